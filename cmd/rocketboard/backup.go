@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/backup"
+	rocketSql "github.com/rocketdynamics/rocketboard/cmd/rocketboard/repository/sql"
+)
+
+const (
+	backupUsage  = "usage: rocketboard backup FILE|-"
+	restoreUsage = "usage: rocketboard restore FILE|-"
+)
+
+// backupTo implements the `rocketboard backup` subcommand, writing the
+// backup to a file, or to stdout for -. An interrupt stops the backup and
+// removes the partial file.
+func backupTo(dbURI string, args []string) (err error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if len(args) != 1 {
+		return fmt.Errorf(backupUsage)
+	}
+	if strings.HasPrefix(dbURI, "inmem:") {
+		return fmt.Errorf("the in-memory repository can't be backed up")
+	}
+
+	db, err := rocketSql.NewRepository(dbURI)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var w io.Writer = os.Stdout
+	if args[0] != "-" {
+		f, err := os.Create(args[0])
+		if err != nil {
+			return err
+		}
+		// Don't leave a partial backup behind
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(args[0])
+			}
+		}()
+		w = f
+	}
+
+	stats, err := backup.Backup(ctx, db, w)
+	if err != nil {
+		return fmt.Errorf("backup failed: %s", err)
+	}
+	log.Println("Backed up", stats)
+	return nil
+}
+
+// restoreFrom implements the `rocketboard restore` subcommand, reading the
+// backup from a file, or from stdin for -.
+func restoreFrom(dbURI string, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if len(args) != 1 {
+		return fmt.Errorf(restoreUsage)
+	}
+	if strings.HasPrefix(dbURI, "inmem:") {
+		return fmt.Errorf("the in-memory repository can't be restored into")
+	}
+
+	db, err := rocketSql.NewRepository(dbURI)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	stats, err := backup.Restore(ctx, db, r)
+	if err != nil {
+		return fmt.Errorf("restore failed after %s: %s", stats, err)
+	}
+	log.Println("Restored", stats)
+	return nil
+}
+
+func runBackup(dbURI string, args []string) {
+	if err := backupTo(dbURI, args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runRestore(dbURI string, args []string) {
+	if err := restoreFrom(dbURI, args); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,287 @@
+// Package backup streams the contents of a repository to a portable file,
+// and restores such a file into an empty repository of any kind.
+//
+// A backup is a gzipped stream of JSON records, one per line. The header
+// comes first, followed by teams, retrospectives along with everything
+// recorded about them in the format they are archived in (model.Snapshot),
+// archives and webhooks. Webhook deliveries and observations are transient
+// and not backed up.
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+const (
+	Format = "rocketboard-backup"
+	// Restoring refuses backups of a later version.
+	Version = 1
+)
+
+// Number of retrospectives and archives loaded at once.
+const pageSize = 100
+
+type Header struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+}
+
+const (
+	headerRecord        = "header"
+	teamRecord          = "team"
+	retrospectiveRecord = "retrospective"
+	archiveRecord       = "archive"
+	webhookRecord       = "webhook"
+)
+
+type record struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Stats counts what was backed up or restored.
+type Stats struct {
+	Teams          int
+	Retrospectives int
+	Cards          int
+	Archives       int
+	Webhooks       int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%d teams, %d retrospectives with %d cards, %d archives, %d webhooks",
+		s.Teams, s.Retrospectives, s.Cards, s.Archives, s.Webhooks)
+}
+
+type source interface {
+	GetTeams(context.Context) ([]*model.Team, error)
+	GetRetrospectives(context.Context, string, int) ([]*model.Retrospective, error)
+	GetArchives(context.Context, string, int) ([]*model.Archive, error)
+	GetAllWebhooks(context.Context) ([]*model.Webhook, error)
+	GetSnapshot(context.Context, string) (*model.Snapshot, error)
+}
+
+type target interface {
+	GetTeams(context.Context) ([]*model.Team, error)
+	GetRetrospectives(context.Context, string, int) ([]*model.Retrospective, error)
+	GetArchives(context.Context, string, int) ([]*model.Archive, error)
+	GetAllWebhooks(context.Context) ([]*model.Webhook, error)
+
+	SaveTeam(context.Context, *model.Team) error
+	RestoreSnapshot(context.Context, *model.Snapshot) error
+	ArchiveRetrospective(context.Context, *model.Archive) error
+	NewWebhook(context.Context, *model.Webhook) error
+}
+
+type encoder struct {
+	*json.Encoder
+}
+
+func (e encoder) write(typ string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return e.Encode(record{typ, data})
+}
+
+// Backup writes everything in the repository to w. Each retrospective is
+// read in one read-only transaction, so each of them is consistent even while
+// the repository is in use.
+func Backup(ctx context.Context, db source, w io.Writer) (Stats, error) {
+	var stats Stats
+	zw := gzip.NewWriter(w)
+	enc := encoder{json.NewEncoder(zw)}
+
+	err := enc.write(headerRecord, Header{Format: Format, Version: Version, Created: time.Now()})
+	if err != nil {
+		return stats, err
+	}
+
+	teams, err := db.GetTeams(ctx)
+	if err != nil {
+		return stats, err
+	}
+	for _, t := range teams {
+		if err := enc.write(teamRecord, t); err != nil {
+			return stats, err
+		}
+		stats.Teams++
+	}
+
+	after := ""
+	for {
+		rs, err := db.GetRetrospectives(ctx, after, pageSize)
+		if err != nil {
+			return stats, err
+		}
+		if len(rs) == 0 {
+			break
+		}
+		for _, r := range rs {
+			s, err := db.GetSnapshot(ctx, r.Id)
+			if model.Code(err) == model.CodeNotFound {
+				// Archived in the meantime, it is backed up as an archive
+				continue
+			}
+			if err != nil {
+				return stats, err
+			}
+			if err := enc.write(retrospectiveRecord, s); err != nil {
+				return stats, err
+			}
+			stats.Retrospectives++
+			stats.Cards += len(s.Cards)
+		}
+		after = rs[len(rs)-1].Id
+	}
+
+	after = ""
+	for {
+		as, err := db.GetArchives(ctx, after, pageSize)
+		if err != nil {
+			return stats, err
+		}
+		if len(as) == 0 {
+			break
+		}
+		for _, a := range as {
+			if err := enc.write(archiveRecord, a); err != nil {
+				return stats, err
+			}
+			stats.Archives++
+		}
+		after = as[len(as)-1].Id
+	}
+
+	hooks, err := db.GetAllWebhooks(ctx)
+	if err != nil {
+		return stats, err
+	}
+	for _, h := range hooks {
+		if err := enc.write(webhookRecord, h); err != nil {
+			return stats, err
+		}
+		stats.Webhooks++
+	}
+
+	return stats, zw.Close()
+}
+
+// isEmpty reports whether nothing that can be restored exists yet.
+func isEmpty(ctx context.Context, db target) (bool, error) {
+	teams, err := db.GetTeams(ctx)
+	if err != nil || len(teams) > 0 {
+		return false, err
+	}
+	rs, err := db.GetRetrospectives(ctx, "", 1)
+	if err != nil || len(rs) > 0 {
+		return false, err
+	}
+	as, err := db.GetArchives(ctx, "", 1)
+	if err != nil || len(as) > 0 {
+		return false, err
+	}
+	hooks, err := db.GetAllWebhooks(ctx)
+	return err == nil && len(hooks) == 0, err
+}
+
+// Restore reads a backup from r into the repository, which must be empty.
+// A failed restore leaves the repository partially restored, it has to be
+// emptied before trying again.
+func Restore(ctx context.Context, db target, r io.Reader) (Stats, error) {
+	var stats Stats
+	empty, err := isEmpty(ctx, db)
+	if err != nil {
+		return stats, err
+	}
+	if !empty {
+		return stats, fmt.Errorf("can only restore into an empty database")
+	}
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return stats, err
+	}
+	defer zr.Close()
+	dec := json.NewDecoder(zr)
+
+	var rec record
+	var header Header
+	if err := dec.Decode(&rec); err != nil {
+		return stats, fmt.Errorf("reading header: %s", err)
+	}
+	if rec.Type == headerRecord {
+		err = json.Unmarshal(rec.Data, &header)
+	}
+	if err != nil || header.Format != Format {
+		return stats, fmt.Errorf("not a rocketboard backup")
+	}
+	if header.Version > Version {
+		return stats, fmt.Errorf("backup version %d is newer than supported version %d", header.Version, Version)
+	}
+
+	for {
+		var rec record
+		err := dec.Decode(&rec)
+		if err == io.EOF {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, err
+		}
+
+		switch rec.Type {
+		case teamRecord:
+			var t model.Team
+			if err := json.Unmarshal(rec.Data, &t); err != nil {
+				return stats, err
+			}
+			if err := db.SaveTeam(ctx, &t); err != nil {
+				return stats, fmt.Errorf("restoring team %s: %s", t.Name, err)
+			}
+			stats.Teams++
+		case retrospectiveRecord:
+			var s model.Snapshot
+			if err := json.Unmarshal(rec.Data, &s); err != nil {
+				return stats, err
+			}
+			if s.Retrospective == nil {
+				return stats, fmt.Errorf("retrospective record without retrospective")
+			}
+			if err := db.RestoreSnapshot(ctx, &s); err != nil {
+				return stats, fmt.Errorf("restoring retrospective %s: %s", s.Retrospective.Id, err)
+			}
+			stats.Retrospectives++
+			stats.Cards += len(s.Cards)
+		case archiveRecord:
+			var a model.Archive
+			if err := json.Unmarshal(rec.Data, &a); err != nil {
+				return stats, err
+			}
+			if err := db.ArchiveRetrospective(ctx, &a); err != nil {
+				return stats, fmt.Errorf("restoring archive %s: %s", a.Id, err)
+			}
+			stats.Archives++
+		case webhookRecord:
+			var h model.Webhook
+			if err := json.Unmarshal(rec.Data, &h); err != nil {
+				return stats, err
+			}
+			if err := db.NewWebhook(ctx, &h); err != nil {
+				return stats, fmt.Errorf("restoring webhook %s: %s", h.Id, err)
+			}
+			stats.Webhooks++
+		default:
+			return stats, fmt.Errorf("unknown record type %q", rec.Type)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/repository/inmem"
+	rocketSql "github.com/rocketdynamics/rocketboard/cmd/rocketboard/repository/sql"
+)
+
+// The precision of timestamps differs between databases.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+type repository interface {
+	source
+	target
+	NewRetrospective(context.Context, *model.Retrospective) error
+	NewCard(context.Context, *model.Card) error
+	MergeCard(context.Context, *model.Card, *model.Merge) error
+	GetCardById(context.Context, string) (*model.Card, error)
+	NewCardGroup(context.Context, *model.CardGroup) error
+	NewVote(context.Context, *model.Vote) error
+	NewStatus(context.Context, *model.Status) error
+}
+
+func populate(t *testing.T, db repository) {
+	t.Helper()
+	ctx := context.Background()
+	days := 30
+	closed := now()
+	db.SaveTeam(ctx, &model.Team{Name: "team", Created: now(), Updated: now(), ChatWebhookUrl: "https://chat", RetentionDays: &days})
+	db.NewRetrospective(ctx, &model.Retrospective{Id: "retro", Created: now(), Updated: now(), Name: "Retro", PetName: "pet", Team: "team", Closed: &closed})
+	db.NewRetrospective(ctx, &model.Retrospective{Id: "empty", Created: now(), Updated: now(), Name: "Empty", PetName: "empty"})
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if err := db.NewCard(ctx, &model.Card{Id: id, Created: now(), Updated: now(), RetrospectiveId: "retro", Column: "Mixed", Message: "Card " + id, Creator: "alice"}); err != nil {
+			t.Fatal("Failed to create card", err)
+		}
+	}
+	for _, m := range [][2]string{{"b", "a"}, {"c", "b"}} {
+		c, _ := db.GetCardById(ctx, m[0])
+		if err := db.MergeCard(ctx, c, &model.Merge{Id: "merge-" + m[0], Created: now(), CardId: m[0], MergedInto: m[1]}); err != nil {
+			t.Fatal("Failed to merge card", err)
+		}
+	}
+	db.NewCardGroup(ctx, &model.CardGroup{Id: "group", Created: now(), Updated: now(), RetrospectiveId: "retro", Column: "Mixed", Name: "Group"})
+	db.NewVote(ctx, &model.Vote{Id: "vote", Created: now(), Updated: now(), CardId: "c", Voter: "bob", Emoji: "clap", Count: 3})
+	db.NewStatus(ctx, &model.Status{Id: "status", Created: now(), CardId: "d", Type: model.Discussed})
+
+	db.ArchiveRetrospective(ctx, &model.Archive{Id: "archived", Created: now(), Name: "Old", PetName: "old", Team: "team", Closed: now(), Snapshot: []byte{1, 2, 3}})
+	rId := "retro"
+	db.NewWebhook(ctx, &model.Webhook{Id: "hook", Created: now(), RetrospectiveId: &rId, Url: "https://hook", Secret: "secret"})
+	db.NewWebhook(ctx, &model.Webhook{Id: "team-hook", Created: now(), Team: "team", Url: "https://hook", Secret: "secret"})
+}
+
+// records returns the records of a backup after the header.
+func records(t *testing.T, b []byte) []string {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal("Failed to read backup", err)
+	}
+	lines := []string{}
+	scanner := bufio.NewScanner(zr)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines[1:]
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := inmem.NewRepository()
+	populate(t, src)
+
+	var buf bytes.Buffer
+	stats, err := Backup(ctx, src, &buf)
+	if err != nil {
+		t.Fatal("Failed to back up", err)
+	}
+	expected := Stats{Teams: 1, Retrospectives: 2, Cards: 4, Archives: 1, Webhooks: 2}
+	if stats != expected {
+		t.Fatal("Expected", expected, "got:", stats)
+	}
+
+	dst, err := rocketSql.NewRepository("sqlite3:" + filepath.Join(t.TempDir(), "rocket.db"))
+	if err != nil {
+		t.Fatal("Failed to open database", err)
+	}
+	defer dst.Close()
+	if stats, err := Restore(ctx, dst, bytes.NewReader(buf.Bytes())); err != nil || stats != expected {
+		t.Fatal("Failed to restore", stats, err)
+	}
+
+	c, err := dst.GetCardById(ctx, "a")
+	if err != nil || len(c.MergedCards) != 1 || len(c.MergedCards[0].MergedCards) != 1 {
+		t.Fatal("Expected merge tree to be restored, got:", c, err)
+	}
+
+	var again bytes.Buffer
+	if _, err := Backup(ctx, dst, &again); err != nil {
+		t.Fatal("Failed to back up restored database", err)
+	}
+	before, after := records(t, buf.Bytes()), records(t, again.Bytes())
+	if len(before) != len(after) {
+		t.Fatal("Expected", len(before), "records, got:", len(after))
+	}
+	for i := range before {
+		if before[i] != after[i] {
+			t.Fatal("Expected restored record\n", before[i], "\ngot:\n", after[i])
+		}
+	}
+
+	// Restoring again would duplicate everything
+	if _, err := Restore(ctx, dst, bytes.NewReader(buf.Bytes())); err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Fatal("Expected restoring into a used database to fail, got:", err)
+	}
+}
+
+func TestRestoreChecksHeader(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(`{"type": "header", "data": {"format": "something else", "version": 1}}` + "\n"))
+	zw.Close()
+	if _, err := Restore(ctx, inmem.NewRepository(), &buf); err == nil {
+		t.Fatal("Expected foreign file to be refused")
+	}
+
+	buf.Reset()
+	zw = gzip.NewWriter(&buf)
+	zw.Write([]byte(`{"type": "header", "data": {"format": "rocketboard-backup", "version": 99}}` + "\n"))
+	zw.Close()
+	if _, err := Restore(ctx, inmem.NewRepository(), &buf); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatal("Expected newer backup to be refused, got:", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Package certs loads the TLS certificate of Rocketboard, reloading it when
+// its files change so that renewed certificates are picked up without a
+// restart.
+package certs
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Reloader holds the certificate loaded from a certificate and a key file.
+type Reloader struct {
+	// Unix time in nanoseconds the files were last checked at, first for
+	// 64-bit alignment of atomic access.
+	lastCheck int64
+
+	certFile string
+	keyFile  string
+	logger   logrus.FieldLogger
+
+	// The files are checked for changes at most once per CheckInterval,
+	// so that handshakes don't wait for the file system.
+	CheckInterval time.Duration
+
+	cert atomic.Value // *tls.Certificate
+
+	mu       sync.Mutex // Serializes reloads
+	modTimes [2]time.Time
+}
+
+// NewReloader loads the certificate from the PEM encoded certFile and
+// keyFile.
+func NewReloader(certFile string, keyFile string, logger logrus.FieldLogger) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, logger: logger, CheckInterval: 10 * time.Second}
+	modTimes, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	r.modTimes = modTimes
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.lastCheck = time.Now().UnixNano()
+	return r, nil
+}
+
+// GetCertificate returns the certificate for tls.Config. Once CheckInterval
+// passed, one handshake checks the files first and reloads the certificate if
+// either of them changed, while the others are served the cached one. A
+// certificate that fails to reload, e.g. because only one of the files was
+// replaced so far, is logged and the previous one is kept until the files
+// change again.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&r.lastCheck)
+	if now-last >= int64(r.CheckInterval) && atomic.CompareAndSwapInt64(&r.lastCheck, last, now) {
+		r.reload()
+	}
+	return r.cert.Load().(*tls.Certificate), nil
+}
+
+func (r *Reloader) reload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTimes, err := r.stat()
+	if err == nil && modTimes != r.modTimes {
+		r.modTimes = modTimes
+		err = r.load()
+	}
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to reload TLS certificate")
+	}
+}
+
+func (r *Reloader) stat() ([2]time.Time, error) {
+	var modTimes [2]time.Time
+	for i, name := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return modTimes, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func (r *Reloader) load() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert.Store(&cert)
+	r.logger.WithField("cert_file", r.certFile).Info("Loaded TLS certificate")
+	return nil
+}
@@ -0,0 +1,97 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// writeCert writes a self-signed certificate for name to certFile and
+// keyFile, modified at modTime.
+func writeCert(t *testing.T, name string, certFile string, keyFile string, modTime time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("Failed to generate key", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal("Failed to create certificate", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal("Failed to marshal key", err)
+	}
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	os.Chtimes(certFile, modTime, modTime)
+	os.Chtimes(keyFile, modTime, modTime)
+}
+
+func commonName(t *testing.T, r *Reloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	if err != nil || cert == nil {
+		t.Fatal("Failed to get certificate", err)
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal("Failed to parse certificate", err)
+	}
+	return parsed.Subject.CommonName
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if _, err := NewReloader(certFile, keyFile, logrus.StandardLogger()); err == nil {
+		t.Fatal("Expected missing files to fail")
+	}
+
+	start := time.Now().Add(-time.Minute)
+	writeCert(t, "first", certFile, keyFile, start)
+	r, err := NewReloader(certFile, keyFile, logrus.StandardLogger())
+	if err != nil {
+		t.Fatal("Failed to load certificate", err)
+	}
+	if name := commonName(t, r); name != "first" {
+		t.Fatal("Expected the first certificate, got:", name)
+	}
+	r.CheckInterval = 0
+
+	writeCert(t, "second", certFile, keyFile, start.Add(time.Second))
+	if name := commonName(t, r); name != "second" {
+		t.Fatal("Expected the renewed certificate, got:", name)
+	}
+
+	os.WriteFile(keyFile, []byte("broken"), 0600)
+	os.Chtimes(keyFile, start.Add(2*time.Second), start.Add(2*time.Second))
+	if name := commonName(t, r); name != "second" {
+		t.Fatal("Expected the previous certificate to be kept, got:", name)
+	}
+
+	// Files aren't checked again before the interval passed
+	r.CheckInterval = time.Hour
+	commonName(t, r)
+	writeCert(t, "third", certFile, keyFile, start.Add(3*time.Second))
+	if name := commonName(t, r); name != "second" {
+		t.Fatal("Expected the files not to be checked yet, got:", name)
+	}
+}
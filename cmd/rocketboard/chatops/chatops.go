@@ -0,0 +1,158 @@
+package chatops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+// Card is a card along with its votes and current status, as far as they are
+// relevant for a summary.
+type Card struct {
+	*model.Card
+
+	// Vote counts by emoji, including the votes on merged cards.
+	Emojis map[string]int
+	Status *model.Status
+}
+
+// Votes returns the total number of votes on the card.
+func (c Card) Votes() int {
+	total := 0
+	for _, n := range c.Emojis {
+		total += n
+	}
+	return total
+}
+
+// IsActionItem reports whether the card was marked as being worked on.
+func (c Card) IsActionItem() bool {
+	return c.Status != nil && c.Status.Type == model.InProgress
+}
+
+// Message is an incoming-webhook payload understood by both Slack and
+// Mattermost.
+type Message struct {
+	Text     string `json:"text"`
+	Username string `json:"username,omitempty"`
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// Summarize formats the top limit cards by votes and all action items of a
+// retrospective. link is included to point back at the board if not empty.
+func Summarize(r *model.Retrospective, link string, cards []Card, limit int) Message {
+	top := append([]Card{}, cards...)
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].Votes() > top[j].Votes()
+	})
+	if len(top) > limit {
+		top = top[:limit]
+	}
+
+	var b strings.Builder
+	title := r.Name
+	if title == "" {
+		title = r.PetName
+	}
+	fmt.Fprintf(&b, "*Retrospective summary: %s*", escape(title))
+	if link != "" {
+		fmt.Fprintf(&b, " (%s)", link)
+	}
+	if len(cards) == 1 {
+		b.WriteString("\n1 card\n")
+	} else {
+		fmt.Fprintf(&b, "\n%d cards\n", len(cards))
+	}
+
+	if len(top) > 0 {
+		b.WriteString("\n*Top cards*\n")
+	}
+	for i, c := range top {
+		fmt.Fprintf(&b, "%d. %s — %s", i+1, escape(c.Message), votes(c))
+		if c.Status != nil {
+			fmt.Fprintf(&b, " · %s", c.Status.Type)
+		}
+		b.WriteString("\n")
+	}
+
+	actionItems := []Card{}
+	for _, c := range cards {
+		if c.IsActionItem() {
+			actionItems = append(actionItems, c)
+		}
+	}
+	if len(actionItems) > 0 {
+		b.WriteString("\n*Action items*\n")
+	}
+	for _, c := range actionItems {
+		fmt.Fprintf(&b, "• %s (%s)\n", escape(c.Message), c.Creator)
+	}
+
+	return Message{Text: b.String(), Username: "Rocketboard"}
+}
+
+func votes(c Card) string {
+	total := c.Votes()
+	if total == 0 {
+		return "no votes"
+	}
+
+	emojis := []string{}
+	for emoji := range c.Emojis {
+		emojis = append(emojis, emoji)
+	}
+	sort.Slice(emojis, func(i, j int) bool {
+		if c.Emojis[emojis[i]] != c.Emojis[emojis[j]] {
+			return c.Emojis[emojis[i]] > c.Emojis[emojis[j]]
+		}
+		return emojis[i] < emojis[j]
+	})
+	parts := []string{}
+	for _, emoji := range emojis {
+		parts = append(parts, fmt.Sprintf(":%s: %d", emoji, c.Emojis[emoji]))
+	}
+
+	noun := "votes"
+	if total == 1 {
+		noun = "vote"
+	}
+	return fmt.Sprintf("%d %s (%s)", total, noun, strings.Join(parts, ", "))
+}
+
+// escape keeps card messages from being interpreted as mentions or links.
+func escape(s string) string {
+	s = strings.ReplaceAll(s, "&", "&amp;")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	s = strings.ReplaceAll(s, ">", "&gt;")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Send posts a message to an incoming webhook.
+func Send(ctx context.Context, url string, m Message) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("chat webhook returned %s", res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package chatops
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+func TestSummarize(t *testing.T) {
+	r := &model.Retrospective{Name: "Sprint 42", PetName: "pet-name"}
+	cards := []Card{
+		{Card: &model.Card{Message: "few votes"}, Emojis: map[string]int{"clap": 1}},
+		{Card: &model.Card{Message: "no votes", Creator: "alice"}, Emojis: map[string]int{},
+			Status: &model.Status{Type: model.InProgress}},
+		{Card: &model.Card{Message: "most <!channel> votes"}, Emojis: map[string]int{"clap": 2, "rocket": 3},
+			Status: &model.Status{Type: model.Discussed}},
+	}
+
+	m := Summarize(r, "https://rocketboard.example/retrospective/pet-name/", cards, 2)
+	expected := `*Retrospective summary: Sprint 42* (https://rocketboard.example/retrospective/pet-name/)
+3 cards
+
+*Top cards*
+1. most &lt;!channel&gt; votes — 5 votes (:rocket: 3, :clap: 2) · Discussed
+2. few votes — 1 vote (:clap: 1)
+
+*Action items*
+• no votes (alice)
+`
+	if m.Text != expected {
+		t.Fatalf("Unexpected summary:\n%s", m.Text)
+	}
+}
+
+func TestSend(t *testing.T) {
+	var received Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		if strings.HasSuffix(r.URL.Path, "/broken") {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	if err := Send(context.Background(), server.URL+"/hook", Message{Text: "hello"}); err != nil {
+		t.Fatal("Failed to send", err)
+	}
+	if received.Text != "hello" {
+		t.Fatal("Unexpected message:", received)
+	}
+	if err := Send(context.Background(), server.URL+"/broken", Message{Text: "hello"}); err == nil {
+		t.Fatal("Expected error for failed webhook")
+	}
+}
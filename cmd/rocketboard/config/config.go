@@ -0,0 +1,298 @@
+// Package config loads the settings of Rocketboard. Every setting has a
+// default, which can be overridden by a YAML file, then by an environment
+// variable and then by a command line flag, see Load.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Config struct {
+	// Address the HTTP server listens on
+	Addr string `yaml:"addr"`
+	// Directory the frontend is served from instead of the one embedded in
+	// the binary, for development
+	PublicDir string `yaml:"public_dir"`
+	// Base URL the frontend is served on, used to link back to boards
+	PublicURL string `yaml:"public_url"`
+	// Lets requests pick their user with ?email=, for development
+	Debug bool `yaml:"debug"`
+	// How long to wait for requests and subscriptions to finish on SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	TLS       TLS       `yaml:"tls"`
+	Database  Database  `yaml:"database"`
+	NATS      NATS      `yaml:"nats"`
+	Log       Log       `yaml:"log"`
+	Limits    Limits    `yaml:"limits"`
+	Retention Retention `yaml:"retention"`
+	Webhooks  Webhooks  `yaml:"webhooks"`
+}
+
+type TLS struct {
+	// Certificate and key files, PEM encoded. Addr is served over TLS when
+	// both are set, and they are reloaded within seconds when they change.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// Address redirecting plain HTTP to HTTPS, none if empty
+	RedirectAddr string `yaml:"redirect_addr"`
+	// Browsers are told to only use HTTPS for this long, 0 doesn't tell them
+	HSTSMaxAge time.Duration `yaml:"hsts_max_age"`
+}
+
+// Enabled reports whether Addr is served over TLS.
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+type Database struct {
+	// sqlite3:<path>, postgres://..., or inmem: for a repository that is
+	// lost on exit
+	URI string `yaml:"uri"`
+	// Statements and transaction attempts taking longer are cancelled, 0
+	// lets them run for as long as the request does
+	QueryTimeout time.Duration `yaml:"query_timeout"`
+}
+
+type NATS struct {
+	// Address of the NATS server, an embedded one is started when empty
+	Addr string `yaml:"addr"`
+}
+
+type Log struct {
+	// debug, info, warn or error, debug by default in debug mode
+	Level string `yaml:"level"`
+	// text or json
+	Format string `yaml:"format"`
+}
+
+type Limits struct {
+	// Longer card messages are cut off
+	MaxMessageLength int `yaml:"max_message_length"`
+	// Cards per retrospective
+	MaxCards int `yaml:"max_cards"`
+	// Users are shown as present for this long after their last heartbeat
+	PresenceWindow time.Duration `yaml:"presence_window"`
+	// Votes per second and burst of votes allowed per user
+	VoteRate  float64 `yaml:"vote_rate"`
+	VoteBurst int     `yaml:"vote_burst"`
+}
+
+type Retention struct {
+	// Days closed retrospectives are kept for, for teams without a
+	// retention of their own. 0 keeps them forever.
+	Days int `yaml:"days"`
+	// Observations older than this are purged
+	ObservationTTL time.Duration `yaml:"observation_ttl"`
+	// How often the janitor sweeps
+	Interval time.Duration `yaml:"interval"`
+}
+
+type Webhooks struct {
+	MaxAttempts  int           `yaml:"max_attempts"`
+	BaseBackoff  time.Duration `yaml:"base_backoff"`
+	MaxBackoff   time.Duration `yaml:"max_backoff"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+}
+
+// Default returns the settings used when nothing is configured.
+func Default() *Config {
+	return &Config{
+		Addr:            ":5000",
+		ShutdownTimeout: 20 * time.Second,
+		Database: Database{
+			URI:          "sqlite3:rocket.db",
+			QueryTimeout: 10 * time.Second,
+		},
+		Log: Log{
+			Format: "text",
+		},
+		Limits: Limits{
+			MaxMessageLength: 500,
+			MaxCards:         100,
+			PresenceWindow:   10 * time.Second,
+			VoteRate:         10,
+			VoteBurst:        100,
+		},
+		Retention: Retention{
+			ObservationTTL: time.Hour,
+			Interval:       time.Hour,
+		},
+		Webhooks: Webhooks{
+			MaxAttempts:  8,
+			BaseBackoff:  10 * time.Second,
+			MaxBackoff:   time.Hour,
+			PollInterval: 5 * time.Second,
+		},
+	}
+}
+
+// bind defines a flag for every setting on fs, along with the environment
+// variables setting them.
+func (c *Config) bind(fs *flag.FlagSet) map[string]string {
+	fs.StringVar(&c.Addr, "addr", c.Addr, "address to listen on")
+	fs.StringVar(&c.PublicDir, "public-dir", c.PublicDir, "directory to serve the frontend from instead of the embedded one")
+	fs.StringVar(&c.PublicURL, "public-url", c.PublicURL, "base URL the frontend is served on")
+	fs.BoolVar(&c.Debug, "debug", c.Debug, "let requests pick their user with ?email=")
+	fs.DurationVar(&c.ShutdownTimeout, "shutdown-timeout", c.ShutdownTimeout, "how long to wait for requests to finish on shutdown")
+	fs.StringVar(&c.TLS.CertFile, "tls-cert", c.TLS.CertFile, "certificate file to serve TLS with")
+	fs.StringVar(&c.TLS.KeyFile, "tls-key", c.TLS.KeyFile, "key file of the TLS certificate")
+	fs.StringVar(&c.TLS.RedirectAddr, "tls-redirect-addr", c.TLS.RedirectAddr, "address to redirect plain HTTP to HTTPS on, e.g. :80")
+	fs.DurationVar(&c.TLS.HSTSMaxAge, "hsts-max-age", c.TLS.HSTSMaxAge, "how long browsers should only use HTTPS, 0 for no HSTS")
+	fs.StringVar(&c.Database.URI, "database-uri", c.Database.URI, "database to connect to, or inmem:")
+	fs.DurationVar(&c.Database.QueryTimeout, "query-timeout", c.Database.QueryTimeout, "timeout of database queries, 0 for none")
+	fs.StringVar(&c.NATS.Addr, "nats-addr", c.NATS.Addr, "NATS server to connect to, an embedded one is started if empty")
+	fs.StringVar(&c.Log.Level, "log-level", c.Log.Level, "debug, info, warn or error")
+	fs.StringVar(&c.Log.Format, "log-format", c.Log.Format, "text or json")
+	fs.IntVar(&c.Limits.MaxMessageLength, "max-message-length", c.Limits.MaxMessageLength, "longer card messages are cut off")
+	fs.IntVar(&c.Limits.MaxCards, "max-cards", c.Limits.MaxCards, "cards per retrospective")
+	fs.DurationVar(&c.Limits.PresenceWindow, "presence-window", c.Limits.PresenceWindow, "how long users are shown as present after a heartbeat")
+	fs.Float64Var(&c.Limits.VoteRate, "vote-rate", c.Limits.VoteRate, "votes per second allowed per user")
+	fs.IntVar(&c.Limits.VoteBurst, "vote-burst", c.Limits.VoteBurst, "burst of votes allowed per user")
+	fs.IntVar(&c.Retention.Days, "retention-days", c.Retention.Days, "days closed retrospectives are kept for, 0 for forever")
+	fs.DurationVar(&c.Retention.ObservationTTL, "observation-ttl", c.Retention.ObservationTTL, "age after which observations are purged")
+	fs.DurationVar(&c.Retention.Interval, "retention-interval", c.Retention.Interval, "how often retention is applied")
+	fs.IntVar(&c.Webhooks.MaxAttempts, "webhook-max-attempts", c.Webhooks.MaxAttempts, "attempts before a webhook delivery is given up")
+	fs.DurationVar(&c.Webhooks.BaseBackoff, "webhook-base-backoff", c.Webhooks.BaseBackoff, "delay before the first retry of a webhook delivery")
+	fs.DurationVar(&c.Webhooks.MaxBackoff, "webhook-max-backoff", c.Webhooks.MaxBackoff, "maximum delay between retries of a webhook delivery")
+	fs.DurationVar(&c.Webhooks.PollInterval, "webhook-poll-interval", c.Webhooks.PollInterval, "how often due webhook deliveries are looked for")
+
+	return map[string]string{
+		"addr":                  "ROCKET_ADDR",
+		"public-dir":            "ROCKET_PUBLIC_DIR",
+		"public-url":            "ROCKET_PUBLIC_URL",
+		"debug":                 "DEBUG",
+		"shutdown-timeout":      "ROCKET_SHUTDOWN_TIMEOUT",
+		"tls-cert":              "ROCKET_TLS_CERT",
+		"tls-key":               "ROCKET_TLS_KEY",
+		"tls-redirect-addr":     "ROCKET_TLS_REDIRECT_ADDR",
+		"hsts-max-age":          "ROCKET_HSTS_MAX_AGE",
+		"database-uri":          "ROCKET_DATABASE_URI",
+		"query-timeout":         "ROCKET_QUERY_TIMEOUT",
+		"nats-addr":             "NATS_ADDR",
+		"log-level":             "ROCKET_LOG_LEVEL",
+		"log-format":            "ROCKET_LOG_FORMAT",
+		"max-message-length":    "ROCKET_MAX_MESSAGE_LENGTH",
+		"max-cards":             "ROCKET_MAX_CARDS",
+		"presence-window":       "ROCKET_PRESENCE_WINDOW",
+		"vote-rate":             "ROCKET_VOTE_RATE",
+		"vote-burst":            "ROCKET_VOTE_BURST",
+		"retention-days":        "ROCKET_RETENTION_DAYS",
+		"observation-ttl":       "ROCKET_OBSERVATION_TTL",
+		"retention-interval":    "ROCKET_RETENTION_INTERVAL",
+		"webhook-max-attempts":  "ROCKET_WEBHOOK_MAX_ATTEMPTS",
+		"webhook-base-backoff":  "ROCKET_WEBHOOK_BASE_BACKOFF",
+		"webhook-max-backoff":   "ROCKET_WEBHOOK_MAX_BACKOFF",
+		"webhook-poll-interval": "ROCKET_WEBHOOK_POLL_INTERVAL",
+	}
+}
+
+// Load returns the settings, read from the YAML file given by the -config
+// flag or ROCKET_CONFIG, then from environment variables and then from the
+// flags in args. The arguments following the flags are returned as well.
+func Load(args []string) (*Config, []string, error) {
+	// The file has to be read before the flags are applied, so parse them
+	// once to find it and to know which were given.
+	fs := flag.NewFlagSet("rocketboard", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("ROCKET_CONFIG"), "YAML file to read settings from ($ROCKET_CONFIG)")
+	env := Default().bind(fs)
+	fs.VisitAll(func(f *flag.Flag) {
+		if key, ok := env[f.Name]; ok {
+			f.Usage += " ($" + key + ")"
+		}
+	})
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	c := Default()
+	if *path != "" {
+		if err := c.loadFile(*path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	settings := flag.NewFlagSet("rocketboard", flag.ContinueOnError)
+	settings.SetOutput(io.Discard)
+	c.bind(settings)
+	for name, key := range env {
+		if v, ok := os.LookupEnv(key); ok && v != "" {
+			if err := settings.Set(name, v); err != nil {
+				return nil, nil, fmt.Errorf("invalid %s: %s", key, err)
+			}
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name != "config" {
+			settings.Set(f.Name, f.Value.String())
+		}
+	})
+
+	if c.Debug && c.Log.Level == "" {
+		c.Log.Level = "debug"
+	}
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return c, fs.Args(), nil
+}
+
+func (c *Config) loadFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return fmt.Errorf("invalid config file %s: %s", path, err)
+	}
+	return nil
+}
+
+// Validate reports all settings that are out of range.
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, problem string) {
+		if !ok {
+			problems = append(problems, problem)
+		}
+	}
+	check(c.Addr != "", "addr is required")
+	check(c.ShutdownTimeout > 0, "shutdown timeout must be positive")
+	check((c.TLS.CertFile == "") == (c.TLS.KeyFile == ""), "tls cert and key must be set together")
+	check(c.TLS.Enabled() || c.TLS.RedirectAddr == "", "tls redirect addr requires a tls cert and key")
+	check(c.TLS.Enabled() || c.TLS.HSTSMaxAge == 0, "hsts max age requires a tls cert and key")
+	check(c.TLS.HSTSMaxAge >= 0, "hsts max age can't be negative")
+	check(c.Database.URI != "", "database uri is required")
+	check(c.Database.QueryTimeout >= 0, "query timeout can't be negative")
+	switch c.Log.Level {
+	case "", "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, "log level must be debug, info, warn or error")
+	}
+	check(c.Log.Format == "text" || c.Log.Format == "json", "log format must be text or json")
+	check(c.Limits.MaxMessageLength > 0, "max message length must be positive")
+	check(c.Limits.MaxCards > 0, "max cards must be positive")
+	check(c.Limits.PresenceWindow > 0, "presence window must be positive")
+	check(c.Limits.VoteRate > 0, "vote rate must be positive")
+	check(c.Limits.VoteBurst > 0, "vote burst must be positive")
+	check(c.Retention.Days >= 0, "retention days can't be negative")
+	check(c.Retention.ObservationTTL > 0, "observation ttl must be positive")
+	check(c.Retention.Interval > 0, "retention interval must be positive")
+	check(c.Webhooks.MaxAttempts > 0, "webhook max attempts must be positive")
+	check(c.Webhooks.BaseBackoff > 0, "webhook base backoff must be positive")
+	check(c.Webhooks.MaxBackoff >= c.Webhooks.BaseBackoff, "webhook max backoff can't be below the base backoff")
+	check(c.Webhooks.PollInterval > 0, "webhook poll interval must be positive")
+	if len(problems) > 0 {
+		return errors.New("invalid configuration: " + strings.Join(problems, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefault(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatal("Expected defaults to be valid, got:", err)
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rocketboard.yml")
+	err := os.WriteFile(path, []byte(`
+addr: ":6000"
+database:
+  uri: postgres://file
+  query_timeout: 3s
+limits:
+  max_cards: 50
+  vote_rate: 2.5
+`), 0600)
+	if err != nil {
+		t.Fatal("Failed to write config file", err)
+	}
+	t.Setenv("ROCKET_CONFIG", path)
+	t.Setenv("ROCKET_DATABASE_URI", "postgres://env")
+	t.Setenv("ROCKET_MAX_CARDS", "60")
+	t.Setenv("DEBUG", "1")
+
+	c, args, err := Load([]string{"-max-cards", "70", "migrate", "up"})
+	if err != nil {
+		t.Fatal("Failed to load config", err)
+	}
+	if c.Addr != ":6000" || c.Database.QueryTimeout != 3*time.Second || c.Limits.VoteRate != 2.5 {
+		t.Fatal("Expected settings from the file, got:", c)
+	}
+	if c.Database.URI != "postgres://env" {
+		t.Fatal("Expected the environment to override the file, got:", c.Database.URI)
+	}
+	if c.Limits.MaxCards != 70 {
+		t.Fatal("Expected flags to override the environment, got:", c.Limits.MaxCards)
+	}
+	if !c.Debug || c.Log.Level != "debug" {
+		t.Fatal("Expected debug mode to log at debug level, got:", c.Debug, c.Log.Level)
+	}
+	if c.Limits.MaxMessageLength != 500 {
+		t.Fatal("Expected defaults for unset settings, got:", c.Limits.MaxMessageLength)
+	}
+	if strings.Join(args, " ") != "migrate up" {
+		t.Fatal("Expected the arguments after the flags, got:", args)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rocketboard.yml")
+	if err := os.WriteFile(path, []byte("unknown: true\n"), 0600); err != nil {
+		t.Fatal("Failed to write config file", err)
+	}
+	if _, _, err := Load([]string{"-config", path}); err == nil {
+		t.Fatal("Expected unknown settings in the file to fail")
+	}
+
+	t.Setenv("ROCKET_QUERY_TIMEOUT", "soon")
+	if _, _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "ROCKET_QUERY_TIMEOUT") {
+		t.Fatal("Expected invalid environment variable to fail, got:", err)
+	}
+	os.Unsetenv("ROCKET_QUERY_TIMEOUT")
+
+	_, _, err := Load([]string{"-max-cards", "0", "-log-format", "xml"})
+	if err == nil || !strings.Contains(err.Error(), "max cards") || !strings.Contains(err.Error(), "log format") {
+		t.Fatal("Expected all invalid settings to be reported, got:", err)
+	}
+
+	_, _, err = Load([]string{"-tls-cert", "cert.pem", "-hsts-max-age", "24h"})
+	if err == nil || !strings.Contains(err.Error(), "cert and key") || !strings.Contains(err.Error(), "hsts") {
+		t.Fatal("Expected incomplete TLS settings to be reported, got:", err)
+	}
+}
@@ -0,0 +1,149 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+// Issue is a tracker-agnostic issue created from a card.
+type Issue struct {
+	Title  string
+	Body   string
+	Labels []string
+}
+
+// Provider creates issues in an issue tracker.
+type Provider interface {
+	// CreateIssue creates the issue and returns its URL in the tracker's
+	// web interface. The request is cancelled once ctx is done.
+	CreateIssue(context.Context, Issue) (string, error)
+}
+
+var client = &http.Client{Timeout: 15 * time.Second}
+
+// NewIssue builds the issue for a card. votes should include the votes of
+// cards merged into it, and link points back at the board if not empty.
+func NewIssue(c *model.Card, votes []*model.Vote, r *model.Retrospective, link string) Issue {
+	title := strings.Join(strings.Fields(c.Message), " ")
+	if len(title) > 80 {
+		title = strings.TrimSpace(title[:77]) + "..."
+	}
+	if title == "" {
+		title = "Retrospective action item"
+	}
+
+	var b strings.Builder
+	b.WriteString(c.Message)
+	for _, merged := range c.Group()[1:] {
+		fmt.Fprintf(&b, "\n\n%s", merged.Message)
+	}
+	b.WriteString("\n\n---\n")
+
+	emojis := map[string]int{}
+	total := 0
+	for _, v := range votes {
+		emojis[v.Emoji] += v.Count
+		total += v.Count
+	}
+	names := []string{}
+	for emoji := range emojis {
+		names = append(names, emoji)
+	}
+	sort.Strings(names)
+	parts := []string{}
+	for _, emoji := range names {
+		parts = append(parts, fmt.Sprintf(":%s: %d", emoji, emojis[emoji]))
+	}
+	fmt.Fprintf(&b, "Votes: %d", total)
+	if len(parts) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(parts, ", "))
+	}
+	fmt.Fprintf(&b, "\nCreated by: %s\n", c.Creator)
+
+	name := r.PetName
+	if r.Name != "" {
+		name = fmt.Sprintf("%s (%s)", r.Name, r.PetName)
+	}
+	if link != "" {
+		fmt.Fprintf(&b, "Retrospective: [%s](%s)\n", name, link)
+	} else {
+		fmt.Fprintf(&b, "Retrospective: %s\n", name)
+	}
+
+	return Issue{
+		Title:  title,
+		Body:   b.String(),
+		Labels: []string{"retrospective"},
+	}
+}
+
+// FromEnv returns the providers configured through environment variables,
+// keyed by name.
+func FromEnv() map[string]Provider {
+	providers := map[string]Provider{}
+	if repo := os.Getenv("ROCKET_GITHUB_REPO"); repo != "" {
+		providers["github"] = &GitHub{
+			BaseUrl: envOr("ROCKET_GITHUB_URL", "https://api.github.com"),
+			Repo:    repo,
+			Token:   os.Getenv("ROCKET_GITHUB_TOKEN"),
+		}
+	}
+	if project := os.Getenv("ROCKET_GITLAB_PROJECT"); project != "" {
+		providers["gitlab"] = &GitLab{
+			BaseUrl: envOr("ROCKET_GITLAB_URL", "https://gitlab.com"),
+			Project: project,
+			Token:   os.Getenv("ROCKET_GITLAB_TOKEN"),
+		}
+	}
+	if project := os.Getenv("ROCKET_JIRA_PROJECT"); project != "" {
+		providers["jira"] = &Jira{
+			BaseUrl:   os.Getenv("ROCKET_JIRA_URL"),
+			Project:   project,
+			IssueType: envOr("ROCKET_JIRA_ISSUE_TYPE", "Task"),
+			User:      os.Getenv("ROCKET_JIRA_USER"),
+			Token:     os.Getenv("ROCKET_JIRA_TOKEN"),
+		}
+	}
+	return providers
+}
+
+func envOr(key string, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// post sends a JSON request and decodes the JSON response into res.
+func post(req *http.Request, body interface{}, res interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	req.ContentLength = int64(len(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s returned %s: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(res)
+}
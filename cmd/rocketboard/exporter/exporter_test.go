@@ -0,0 +1,153 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+type request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+// fakeTracker records the requests it gets and answers them with response.
+func fakeTracker(t *testing.T, response string) (*httptest.Server, *[]request) {
+	requests := []request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{Method: r.Method, Path: r.URL.EscapedPath(), Header: r.Header}
+		if err := json.NewDecoder(r.Body).Decode(&req.Body); err != nil {
+			t.Error("Failed to decode request body", err)
+		}
+		requests = append(requests, req)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(response))
+	}))
+	return server, &requests
+}
+
+var issue = Issue{Title: "Fix the build", Body: "It is broken", Labels: []string{"retrospective"}}
+
+func TestGitHub(t *testing.T) {
+	server, requests := fakeTracker(t, `{"html_url": "https://github.example/owner/repo/issues/1"}`)
+	defer server.Close()
+
+	u, err := (&GitHub{BaseUrl: server.URL, Repo: "owner/repo", Token: "token"}).CreateIssue(context.Background(), issue)
+	if err != nil {
+		t.Fatal("Failed to create issue", err)
+	}
+	if u != "https://github.example/owner/repo/issues/1" {
+		t.Fatal("Unexpected issue URL:", u)
+	}
+	req := (*requests)[0]
+	if req.Method != "POST" || req.Path != "/repos/owner/repo/issues" || req.Header.Get("Authorization") != "Bearer token" {
+		t.Fatal("Unexpected request:", req)
+	}
+	if req.Body["title"] != issue.Title || req.Body["body"] != issue.Body {
+		t.Fatal("Unexpected body:", req.Body)
+	}
+}
+
+func TestGitLab(t *testing.T) {
+	server, requests := fakeTracker(t, `{"web_url": "https://gitlab.example/group/project/-/issues/1"}`)
+	defer server.Close()
+
+	u, err := (&GitLab{BaseUrl: server.URL, Project: "group/project", Token: "token"}).CreateIssue(context.Background(), issue)
+	if err != nil {
+		t.Fatal("Failed to create issue", err)
+	}
+	if u != "https://gitlab.example/group/project/-/issues/1" {
+		t.Fatal("Unexpected issue URL:", u)
+	}
+	req := (*requests)[0]
+	if req.Path != "/api/v4/projects/group%2Fproject/issues" || req.Header.Get("PRIVATE-TOKEN") != "token" {
+		t.Fatal("Unexpected request:", req)
+	}
+	if req.Body["description"] != issue.Body || req.Body["labels"] != "retrospective" {
+		t.Fatal("Unexpected body:", req.Body)
+	}
+}
+
+func TestJira(t *testing.T) {
+	server, requests := fakeTracker(t, `{"id": "10000", "key": "OPS-7"}`)
+	defer server.Close()
+
+	u, err := (&Jira{BaseUrl: server.URL, Project: "OPS", IssueType: "Task", User: "bot", Token: "token"}).CreateIssue(context.Background(), issue)
+	if err != nil {
+		t.Fatal("Failed to create issue", err)
+	}
+	if u != server.URL+"/browse/OPS-7" {
+		t.Fatal("Unexpected issue URL:", u)
+	}
+	req := (*requests)[0]
+	user, token, ok := (&http.Request{Header: req.Header}).BasicAuth()
+	if req.Path != "/rest/api/2/issue" || !ok || user != "bot" || token != "token" {
+		t.Fatal("Unexpected request:", req)
+	}
+	fields := req.Body["fields"].(map[string]interface{})
+	if fields["summary"] != issue.Title || fields["project"].(map[string]interface{})["key"] != "OPS" {
+		t.Fatal("Unexpected body:", req.Body)
+	}
+}
+
+func TestTrackerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Bad credentials", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := (&GitHub{BaseUrl: server.URL, Repo: "owner/repo"}).CreateIssue(context.Background(), issue)
+	if err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Fatal("Expected tracker error, got:", err)
+	}
+}
+
+func TestCancelledExport(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := (&GitHub{BaseUrl: server.URL, Repo: "owner/repo"}).CreateIssue(ctx, issue)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("Expected request to be cancelled with the context, got:", err)
+	}
+}
+
+func TestNewIssue(t *testing.T) {
+	c := &model.Card{
+		Message:     "We should  write\nmore tests",
+		Creator:     "alice",
+		MergedCards: []*model.Card{{Message: "Tests are flaky"}},
+	}
+	votes := []*model.Vote{{Emoji: "clap", Count: 2}, {Emoji: "rocket", Count: 1}, {Emoji: "clap", Count: 1}}
+	r := &model.Retrospective{Name: "Sprint 42", PetName: "pet-name"}
+
+	i := NewIssue(c, votes, r, "https://rocketboard.example/retrospective/pet-name/")
+	if i.Title != "We should write more tests" {
+		t.Fatal("Unexpected title:", i.Title)
+	}
+	for _, expected := range []string{
+		"Tests are flaky",
+		"Votes: 4 (:clap: 3, :rocket: 1)",
+		"Created by: alice",
+		"[Sprint 42 (pet-name)](https://rocketboard.example/retrospective/pet-name/)",
+	} {
+		if !strings.Contains(i.Body, expected) {
+			t.Errorf("Expected body to contain %q, got:\n%s", expected, i.Body)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GitHub creates issues through the GitHub (or GitHub Enterprise) REST API.
+type GitHub struct {
+	// API root, e.g. https://api.github.com
+	BaseUrl string
+	// Repository as "owner/name".
+	Repo  string
+	Token string
+}
+
+func (g *GitHub) CreateIssue(ctx context.Context, i Issue) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(g.BaseUrl, "/")+"/repos/"+g.Repo+"/issues", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+g.Token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	var res struct {
+		HtmlUrl string `json:"html_url"`
+	}
+	err = post(req, map[string]interface{}{
+		"title":  i.Title,
+		"body":   i.Body,
+		"labels": i.Labels,
+	}, &res)
+	if err != nil {
+		return "", err
+	}
+	if res.HtmlUrl == "" {
+		return "", fmt.Errorf("github response is missing the issue URL")
+	}
+	return res.HtmlUrl, nil
+}
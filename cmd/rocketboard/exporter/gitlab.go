@@ -0,0 +1,43 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GitLab creates issues through the GitLab REST API.
+type GitLab struct {
+	// Instance root, e.g. https://gitlab.com
+	BaseUrl string
+	// Project ID or path, e.g. "group/project".
+	Project string
+	Token   string
+}
+
+func (g *GitLab) CreateIssue(ctx context.Context, i Issue) (string, error) {
+	u := strings.TrimSuffix(g.BaseUrl, "/") + "/api/v4/projects/" + url.PathEscape(g.Project) + "/issues"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("PRIVATE-TOKEN", g.Token)
+
+	var res struct {
+		WebUrl string `json:"web_url"`
+	}
+	err = post(req, map[string]interface{}{
+		"title":       i.Title,
+		"description": i.Body,
+		"labels":      strings.Join(i.Labels, ","),
+	}, &res)
+	if err != nil {
+		return "", err
+	}
+	if res.WebUrl == "" {
+		return "", fmt.Errorf("gitlab response is missing the issue URL")
+	}
+	return res.WebUrl, nil
+}
@@ -0,0 +1,55 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Jira creates issues through the Jira REST API (v2, which takes plain text
+// descriptions on both Cloud and Server).
+type Jira struct {
+	// Site root, e.g. https://example.atlassian.net
+	BaseUrl string
+	// Project key, e.g. "OPS".
+	Project   string
+	IssueType string
+	User      string
+	Token     string
+}
+
+func (j *Jira) CreateIssue(ctx context.Context, i Issue) (string, error) {
+	base := strings.TrimSuffix(j.BaseUrl, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/rest/api/2/issue", nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(j.User, j.Token)
+
+	labels := []string{}
+	for _, l := range i.Labels {
+		// Jira labels can't contain spaces.
+		labels = append(labels, strings.Join(strings.Fields(l), "-"))
+	}
+
+	var res struct {
+		Key string `json:"key"`
+	}
+	err = post(req, map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": j.Project},
+			"issuetype":   map[string]string{"name": j.IssueType},
+			"summary":     i.Title,
+			"description": i.Body,
+			"labels":      labels,
+		},
+	}, &res)
+	if err != nil {
+		return "", err
+	}
+	if res.Key == "" {
+		return "", fmt.Errorf("jira response is missing the issue key")
+	}
+	return base + "/browse/" + res.Key, nil
+}
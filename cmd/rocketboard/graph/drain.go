@@ -0,0 +1,144 @@
+package graph
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// errDraining is returned for subscriptions started after Drain was called.
+var errDraining = errors.New("server is shutting down")
+
+// The subscriptions that are live, which end when draining is closed, and
+// the websocket connections they were made over.
+var (
+	drainMu       sync.Mutex
+	draining      = make(chan struct{})
+	isDraining    bool
+	subscriptions sync.WaitGroup
+	websockets    = make(map[*websocketConn]bool)
+)
+
+// startSubscription registers a subscription, which must call
+// subscriptions.Done once it has ended and cleaned up.
+func startSubscription() error {
+	drainMu.Lock()
+	defer drainMu.Unlock()
+	if isDraining {
+		return errDraining
+	}
+	subscriptions.Add(1)
+	return nil
+}
+
+// Draining reports whether Drain was called.
+func Draining() bool {
+	drainMu.Lock()
+	defer drainMu.Unlock()
+	return isDraining
+}
+
+// Drain ends all subscriptions and event streams and refuses new ones. The
+// websocket connections tracked by TrackWebsockets are closed, which has
+// clients reconnect to another instance. It returns once
+// the subscriptions have cleaned up after themselves, which clears the
+// observations of their connections, or with the error of ctx if that is
+// done first.
+func Drain(ctx context.Context) error {
+	drainMu.Lock()
+	if !isDraining {
+		isDraining = true
+		close(draining)
+	}
+	conns := make([]*websocketConn, 0, len(websockets))
+	for c := range websockets {
+		conns = append(conns, c)
+	}
+	drainMu.Unlock()
+
+	// gqlgen can't complete subscriptions on its own, so the connections
+	// are closed from underneath it.
+	for _, c := range conns {
+		c.goAway()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		subscriptions.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CloseMessageQueue sends what is left to publish to NATS and closes the
+// connection, waiting for the server at most until ctx is done.
+func CloseMessageQueue(ctx context.Context) error {
+	if nc == nil {
+		return nil
+	}
+	defer nc.Close()
+	timeout := 5 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if timeout <= 0 {
+		return context.DeadlineExceeded
+	}
+	return nc.FlushTimeout(timeout)
+}
+
+// TrackWebsockets returns a handler recording the connections that base
+// hijacks for websockets, for Drain to close.
+func TrackWebsockets(base http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h, ok := w.(http.Hijacker); ok {
+			w = &hijackTracker{w, h}
+		}
+		base.ServeHTTP(w, r)
+	})
+}
+
+type hijackTracker struct {
+	http.ResponseWriter
+	hijacker http.Hijacker
+}
+
+func (w *hijackTracker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := w.hijacker.Hijack()
+	if err != nil {
+		return conn, rw, err
+	}
+	c := &websocketConn{Conn: conn}
+	drainMu.Lock()
+	websockets[c] = true
+	drainMu.Unlock()
+	return c, rw, nil
+}
+
+// websocketConn is a hijacked connection, which stops being tracked once
+// it is closed.
+type websocketConn struct {
+	net.Conn
+}
+
+func (c *websocketConn) Close() error {
+	drainMu.Lock()
+	delete(websockets, c)
+	drainMu.Unlock()
+	return c.Conn.Close()
+}
+
+// goAway closes the connection. No close frame is sent, as gqlgen may be
+// writing to the connection at the same time; clients reconnect either way.
+func (c *websocketConn) goAway() {
+	c.Close()
+}
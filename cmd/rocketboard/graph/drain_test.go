@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	hijacked := make(chan net.Conn, 1)
+	srv := httptest.NewServer(TrackWebsockets(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error("Failed to hijack", err)
+			return
+		}
+		hijacked <- conn
+	})))
+	defer srv.Close()
+
+	client, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal("Failed to connect", err)
+	}
+	defer client.Close()
+	client.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\n\r\n"))
+	<-hijacked
+
+	if err := startSubscription(); err != nil {
+		t.Fatal("Expected subscriptions before draining, got:", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := Drain(ctx); err != context.DeadlineExceeded {
+		t.Fatal("Expected drain to wait for the live subscription, got:", err)
+	}
+	select {
+	case <-draining:
+	default:
+		t.Fatal("Expected subscriptions to be told to end")
+	}
+	if err := startSubscription(); err != errDraining || !Draining() {
+		t.Fatal("Expected new subscriptions to be refused, got:", err)
+	}
+
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := client.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatal("Expected the websocket connection to be closed, got:", err)
+	}
+
+	subscriptions.Done()
+	if err := Drain(context.Background()); err != nil {
+		t.Fatal("Expected drain to finish once subscriptions ended, got:", err)
+	}
+}
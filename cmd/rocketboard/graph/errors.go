@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/sirupsen/logrus"
+	"github.com/vektah/gqlparser/gqlerror"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/logging"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+// errPanicked is returned for resolvers that panicked, which were logged by
+// Recover already.
+var errPanicked = errors.New("internal error")
+
+// ErrorPresenter returns a presenter adding the code of the kind of an error
+// (see model.Code) to its extensions. Internal errors are logged.
+func ErrorPresenter(logger logrus.FieldLogger) graphql.ErrorPresenterFunc {
+	return func(ctx context.Context, err error) *gqlerror.Error {
+		var gqlErr *gqlerror.Error
+		if errors.As(err, &gqlErr) {
+			return graphql.DefaultErrorPresenter(ctx, gqlErr)
+		}
+
+		path := graphql.GetResolverContext(ctx).Path
+		code := model.Code(err)
+		if code == model.CodeInternal && err != errPanicked {
+			logging.Request(ctx, logger).WithError(err).WithField("path", path).Error("Request failed")
+		}
+		extensions := map[string]interface{}{"code": code}
+		var extended graphql.ExtendedError
+		if errors.As(err, &extended) {
+			extensions = extended.Extensions()
+		}
+		return &gqlerror.Error{
+			Message:    err.Error(),
+			Path:       path,
+			Extensions: extensions,
+		}
+	}
+}
+
+// Recover returns a function turning a panic while resolving into an
+// internal error, logging it along with the stack.
+func Recover(logger logrus.FieldLogger) graphql.RecoverFunc {
+	return func(ctx context.Context, p interface{}) error {
+		logging.Request(ctx, logger).WithFields(logrus.Fields{
+			"panic": p,
+			"stack": string(debug.Stack()),
+		}).Error("Recovered from panic")
+		return errPanicked
+	}
+}
@@ -0,0 +1,345 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/go-nats"
+	"github.com/sirupsen/logrus"
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/logging"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/utils"
+)
+
+const (
+	// How often a comment line is written to keep idle streams (and any
+	// proxies in between) from timing out.
+	sseHeartbeatInterval = 15 * time.Second
+	// How many events are kept per retrospective for Last-Event-ID resumption.
+	sseBacklogSize = 500
+	// How long a retrospective's backlog is kept after its last listener
+	// disconnects, so that reconnecting clients can resume.
+	sseBacklogRetention = 5 * time.Minute
+)
+
+// event is a single Server-Sent Event, already encoded as JSON.
+type event struct {
+	Id   string
+	Name string
+	Data []byte
+}
+
+// cardEvent is the JSON shape of a card, including the fields that are
+// resolved separately by cardResolver over GraphQL.
+type cardEvent struct {
+	*model.Card
+	Statuses []*model.Status `json:"statuses"`
+	Votes    []*model.Vote   `json:"votes"`
+}
+
+// retroEvent is the JSON shape of a retrospective, including the fields that
+// are resolved separately by retrospectiveResolver over GraphQL.
+type retroEvent struct {
+	*model.Retrospective
+	OnlineUsers []model.UserState `json:"onlineUsers"`
+}
+
+// eventLog keeps a bounded backlog of the events of a single retrospective
+// and fans them out to the connected listeners.
+type eventLog struct {
+	mu        sync.Mutex
+	events    []event
+	listeners map[chan event]bool
+	subs      []*nats.Subscription
+	idle      *time.Timer
+}
+
+type eventsHandler struct {
+	*rootResolver
+
+	mu   sync.Mutex
+	logs map[string]*eventLog
+}
+
+// NewEventsHandler returns a handler that streams the updates of the
+// retrospective in the request path (`/events/<retroId>`) as Server-Sent
+// Events, for clients that can't use GraphQL subscriptions over websockets.
+func NewEventsHandler(s rocketboardService, o observationStore, logger logrus.FieldLogger) http.Handler {
+	return &eventsHandler{
+		rootResolver: &rootResolver{s: s, o: o, logger: logger},
+		logs:         make(map[string]*eventLog),
+	}
+}
+
+func (h *eventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rId := strings.TrimPrefix(r.URL.Path, "/events/")
+	if rId == "" || strings.Contains(rId, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	ctx := r.Context()
+	if Draining() {
+		http.Error(w, errDraining.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if _, err := h.s.GetRetrospectiveById(ctx, rId); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	l, err := h.acquire(rId)
+	if err != nil {
+		logging.Retro(ctx, h.logger, rId).WithError(err).Error("Failed to subscribe to retrospective events")
+		http.Error(w, "failed to subscribe", http.StatusInternalServerError)
+		return
+	}
+	defer h.release(rId, l)
+
+	lastEventId := r.Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = r.URL.Query().Get("lastEventId")
+	}
+
+	// Register before computing the backlog so nothing published in between
+	// is lost. Events are full card/retro states, so duplicates are harmless.
+	ch := make(chan event, 100)
+	backlog, resumed := l.listen(ch, lastEventId)
+	defer l.unlisten(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", 3000)
+
+	if !resumed {
+		backlog = h.snapshot(ctx, rId, l)
+	}
+	for _, e := range backlog {
+		writeEvent(w, e)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-draining:
+			// The client reconnects to another instance and resumes.
+			return
+		case e, ok := <-ch:
+			if !ok {
+				// We fell too far behind, let the client reconnect and resume.
+				return
+			}
+			writeEvent(w, e)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e event) {
+	if e.Id != "" {
+		fmt.Fprintf(w, "id: %s\n", e.Id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Name, e.Data)
+}
+
+// snapshot returns the current state of every card and the retrospective
+// itself, for clients that connect fresh or can no longer be resumed. The
+// events carry the ID of the newest backlog entry so a later reconnect
+// resumes from this point.
+func (h *eventsHandler) snapshot(ctx context.Context, rId string, l *eventLog) []event {
+	l.mu.Lock()
+	head := ""
+	if len(l.events) > 0 {
+		head = l.events[len(l.events)-1].Id
+	}
+	l.mu.Unlock()
+
+	events := []event{}
+	cards, err := h.s.GetCardsForRetrospective(ctx, rId)
+	if err != nil {
+		logging.Retro(ctx, h.logger, rId).WithError(err).Error("Failed to load cards for snapshot")
+	}
+	for _, c := range cards {
+		if e, err := h.cardEvent(ctx, c); err == nil {
+			e.Id = head
+			events = append(events, e)
+		}
+	}
+	if retro, err := h.s.GetRetrospectiveById(ctx, rId); err == nil {
+		if e, err := h.retroEvent(ctx, retro); err == nil {
+			e.Id = head
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func (h *eventsHandler) cardEvent(ctx context.Context, c *model.Card) (event, error) {
+	statuses, _ := h.s.GetCardStatuses(ctx, c.Id)
+	votes, _ := h.s.GetVotesByCardId(ctx, c.Id)
+	b, err := json.Marshal(cardEvent{c, statuses, votes})
+	return event{Name: "cardChanged", Data: b}, err
+}
+
+func (h *eventsHandler) retroEvent(ctx context.Context, retro *model.Retrospective) (event, error) {
+	users, _ := h.o.GetActiveUsers(ctx, retro.Id)
+	b, err := json.Marshal(retroEvent{retro, users})
+	return event{Name: "retroChanged", Data: b}, err
+}
+
+// acquire returns the event log of a retrospective, subscribing to its
+// message queue channels if nobody was listening yet.
+func (h *eventsHandler) acquire(rId string) (*eventLog, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if l, ok := h.logs[rId]; ok {
+		if l.idle != nil {
+			l.idle.Stop()
+			l.idle = nil
+		}
+		return l, nil
+	}
+
+	// The subscriptions outlive the request that made them.
+	ctx := context.Background()
+	l := &eventLog{listeners: make(map[chan event]bool)}
+	cardSub, err := nc.Subscribe("cards-"+rId, func(msg *nats.Msg) {
+		var card model.Card
+		if err := msgpack.Unmarshal(msg.Data, &card); err != nil {
+			h.logger.WithField("retro", rId).WithError(err).Error("Failed to unmarshal card message")
+			return
+		}
+		if e, err := h.cardEvent(ctx, &card); err == nil {
+			l.publish(e)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	retroSub, err := nc.Subscribe("retros-"+rId, func(msg *nats.Msg) {
+		var retro model.Retrospective
+		if err := msgpack.Unmarshal(msg.Data, &retro); err != nil {
+			h.logger.WithField("retro", rId).WithError(err).Error("Failed to unmarshal retro message")
+			return
+		}
+		if e, err := h.retroEvent(ctx, &retro); err == nil {
+			l.publish(e)
+		}
+	})
+	if err != nil {
+		cardSub.Unsubscribe()
+		return nil, err
+	}
+	l.subs = []*nats.Subscription{cardSub, retroSub}
+	h.logs[rId] = l
+	return l, nil
+}
+
+// release schedules the event log of a retrospective to be dropped once it
+// has had no listeners for sseBacklogRetention.
+func (h *eventsHandler) release(rId string, l *eventLog) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	l.mu.Lock()
+	listeners := len(l.listeners)
+	l.mu.Unlock()
+	if listeners > 0 || l.idle != nil {
+		return
+	}
+
+	l.idle = time.AfterFunc(sseBacklogRetention, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		// acquire clears l.idle when a listener came back in the meantime.
+		if h.logs[rId] != l || l.idle == nil {
+			return
+		}
+		// A stream that acquired the log before this timer was started may
+		// have started listening since.
+		l.mu.Lock()
+		listeners := len(l.listeners)
+		l.mu.Unlock()
+		if listeners > 0 {
+			l.idle = nil
+			return
+		}
+		for _, sub := range l.subs {
+			sub.Unsubscribe()
+		}
+		delete(h.logs, rId)
+	})
+}
+
+func (l *eventLog) publish(e event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Id = utils.NewUlid()
+	l.events = append(l.events, e)
+	if len(l.events) > sseBacklogSize {
+		l.events = l.events[len(l.events)-sseBacklogSize:]
+	}
+
+	for ch := range l.listeners {
+		select {
+		case ch <- e:
+		default:
+			delete(l.listeners, ch)
+			close(ch)
+		}
+	}
+}
+
+// listen registers ch for new events and returns the backlog after
+// lastEventId. The second return value is false if lastEventId is not (or no
+// longer) in the backlog.
+func (l *eventLog) listen(ch chan event, lastEventId string) ([]event, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.listeners[ch] = true
+	if lastEventId == "" {
+		return nil, false
+	}
+	for i := len(l.events) - 1; i >= 0; i-- {
+		if l.events[i].Id == lastEventId {
+			return append([]event{}, l.events[i+1:]...), true
+		}
+	}
+	return nil, false
+}
+
+func (l *eventLog) unlisten(ch chan event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.listeners[ch] {
+		delete(l.listeners, ch)
+		close(ch)
+	}
+}
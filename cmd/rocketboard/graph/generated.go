@@ -30,41 +30,82 @@ type Config struct {
 
 type ResolverRoot interface {
 	Card() CardResolver
+	CardGroup() CardGroupResolver
 	Retrospective() RetrospectiveResolver
 	RootMutation() RootMutationResolver
 	RootQuery() RootQueryResolver
 	Subscription() SubscriptionResolver
+	Team() TeamResolver
+	Webhook() WebhookResolver
+	WebhookDelivery() WebhookDeliveryResolver
 }
 
 type DirectiveRoot struct {
 }
 type CardResolver interface {
+	MergeHistory(ctx context.Context, obj *model.Card) ([]model.Merge, error)
+
 	Statuses(ctx context.Context, obj *model.Card) ([]*model.Status, error)
 	Votes(ctx context.Context, obj *model.Card) ([]*model.Vote, error)
 }
+type CardGroupResolver interface {
+	Cards(ctx context.Context, obj *model.CardGroup) ([]model.Card, error)
+	Votes(ctx context.Context, obj *model.CardGroup) ([]model.VoteTotal, error)
+	TotalVotes(ctx context.Context, obj *model.CardGroup) (int, error)
+}
 type RetrospectiveResolver interface {
 	Cards(ctx context.Context, obj *model.Retrospective) ([]*model.Card, error)
+	Groups(ctx context.Context, obj *model.Retrospective) ([]model.CardGroup, error)
 	OnlineUsers(ctx context.Context, obj *model.Retrospective) ([]model.UserState, error)
 }
 type RootMutationResolver interface {
-	StartRetrospective(ctx context.Context, name *string) (string, error)
+	StartRetrospective(ctx context.Context, name *string, team *string) (string, error)
+	CloseRetrospective(ctx context.Context, id string) (model.Retrospective, error)
 	AddCardToRetrospective(ctx context.Context, id string, column *string, message *string) (string, error)
-	MoveCard(ctx context.Context, id string, column string, index int) (int, error)
+	MoveCard(ctx context.Context, id string, column string, index int, version int) (int, error)
 	MergeCard(ctx context.Context, id string, mergedInto string) (string, error)
 	UnmergeCard(ctx context.Context, id string) (string, error)
-	UpdateMessage(ctx context.Context, id string, message string) (string, error)
+	UnmergeAll(ctx context.Context, id string) (string, error)
+	MoveMergedCard(ctx context.Context, id string, index int, version int) (int, error)
+	SetMergeTitle(ctx context.Context, id string, title string, version int) (*string, error)
+	CreateCardGroup(ctx context.Context, rId string, column string, name string) (model.CardGroup, error)
+	RenameCardGroup(ctx context.Context, id string, name string) (model.CardGroup, error)
+	MoveCardGroup(ctx context.Context, id string, index int) (int, error)
+	DissolveCardGroup(ctx context.Context, id string) (string, error)
+	MoveCardToGroup(ctx context.Context, id string, groupId string, index int, version int) (int, error)
+	UpdateMessage(ctx context.Context, id string, message string, version int) (string, error)
 	NewVote(ctx context.Context, cardId string, emoji string) (model.Vote, error)
 	UpdateStatus(ctx context.Context, id string, status model.StatusType) (model.Status, error)
 	SendHeartbeat(ctx context.Context, rId string, state string) (string, error)
+	CreateWebhook(ctx context.Context, rId *string, team *string, url string, secret string, events []string) (model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) (string, error)
+	ConfigureTeam(ctx context.Context, name string, chatWebhookUrl *string, retentionDays *int) (model.Team, error)
+	PostSummary(ctx context.Context, rId string, limit *int) (string, error)
+	ExportCard(ctx context.Context, id string, provider string) (model.Card, error)
+	ExportActionItems(ctx context.Context, rId string, provider string) ([]model.Card, error)
 }
 type RootQueryResolver interface {
 	RetrospectiveByID(ctx context.Context, id string) (*model.Retrospective, error)
 	RetrospectiveByPetName(ctx context.Context, petName string) (*model.Retrospective, error)
+	Webhooks(ctx context.Context, rId *string, team *string) ([]model.Webhook, error)
+	Team(ctx context.Context, name string) (*model.Team, error)
+	Archives(ctx context.Context, team string) ([]model.Archive, error)
+	ExportProviders(ctx context.Context) ([]string, error)
 }
 type SubscriptionResolver interface {
 	CardChanged(ctx context.Context, rId string) (<-chan model.Card, error)
 	RetroChanged(ctx context.Context, rId string) (<-chan model.Retrospective, error)
 }
+type TeamResolver interface {
+	ChatWebhookConfigured(ctx context.Context, obj *model.Team) (bool, error)
+}
+type WebhookResolver interface {
+	Events(ctx context.Context, obj *model.Webhook) ([]string, error)
+	Deliveries(ctx context.Context, obj *model.Webhook, limit *int) ([]model.WebhookDelivery, error)
+}
+type WebhookDeliveryResolver interface {
+	AttemptLog(ctx context.Context, obj *model.WebhookDelivery) ([]model.WebhookAttempt, error)
+}
 
 type executableSchema struct {
 	resolvers  ResolverRoot
@@ -140,6 +181,141 @@ type executionContext struct {
 	*executableSchema
 }
 
+var archiveImplementors = []string{"Archive"}
+
+// nolint: gocyclo, errcheck, gas, goconst
+func (ec *executionContext) _Archive(ctx context.Context, sel ast.SelectionSet, obj *model.Archive) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, archiveImplementors)
+
+	out := graphql.NewOrderedMap(len(fields))
+	for i, field := range fields {
+		out.Keys[i] = field.Alias
+
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("Archive")
+		case "id":
+			out.Values[i] = ec._Archive_id(ctx, field, obj)
+		case "created":
+			out.Values[i] = ec._Archive_created(ctx, field, obj)
+		case "name":
+			out.Values[i] = ec._Archive_name(ctx, field, obj)
+		case "petName":
+			out.Values[i] = ec._Archive_petName(ctx, field, obj)
+		case "team":
+			out.Values[i] = ec._Archive_team(ctx, field, obj)
+		case "closed":
+			out.Values[i] = ec._Archive_closed(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+
+	return out
+}
+
+func (ec *executionContext) _Archive_id(ctx context.Context, field graphql.CollectedField, obj *model.Archive) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Archive"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Id, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalID(res)
+}
+
+func (ec *executionContext) _Archive_created(ctx context.Context, field graphql.CollectedField, obj *model.Archive) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Archive"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Created, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	return graphql.MarshalTime(res)
+}
+
+func (ec *executionContext) _Archive_name(ctx context.Context, field graphql.CollectedField, obj *model.Archive) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Archive"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Name, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _Archive_petName(ctx context.Context, field graphql.CollectedField, obj *model.Archive) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Archive"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.PetName, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _Archive_team(ctx context.Context, field graphql.CollectedField, obj *model.Archive) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Archive"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Team, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _Archive_closed(ctx context.Context, field graphql.CollectedField, obj *model.Archive) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Archive"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Closed, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	return graphql.MarshalTime(res)
+}
+
 var cardImplementors = []string{"Card"}
 
 // nolint: gocyclo, errcheck, gas, goconst
@@ -169,12 +345,22 @@ func (ec *executionContext) _Card(ctx context.Context, sel ast.SelectionSet, obj
 			out.Values[i] = ec._Card_mergedInto(ctx, field, obj)
 		case "mergedCards":
 			out.Values[i] = ec._Card_mergedCards(ctx, field, obj)
+		case "mergeTitle":
+			out.Values[i] = ec._Card_mergeTitle(ctx, field, obj)
+		case "mergeHistory":
+			out.Values[i] = ec._Card_mergeHistory(ctx, field, obj)
+		case "groupId":
+			out.Values[i] = ec._Card_groupId(ctx, field, obj)
 		case "statuses":
 			out.Values[i] = ec._Card_statuses(ctx, field, obj)
 		case "votes":
 			out.Values[i] = ec._Card_votes(ctx, field, obj)
 		case "position":
 			out.Values[i] = ec._Card_position(ctx, field, obj)
+		case "issueUrl":
+			out.Values[i] = ec._Card_issueUrl(ctx, field, obj)
+		case "version":
+			out.Values[i] = ec._Card_version(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -334,6 +520,81 @@ func (ec *executionContext) _Card_mergedCards(ctx context.Context, field graphql
 	return arr1
 }
 
+func (ec *executionContext) _Card_mergeTitle(ctx context.Context, field graphql.CollectedField, obj *model.Card) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Card"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.MergeTitle, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	if res == nil {
+		return graphql.Null
+	}
+	return graphql.MarshalString(*res)
+}
+
+func (ec *executionContext) _Card_mergeHistory(ctx context.Context, field graphql.CollectedField, obj *model.Card) graphql.Marshaler {
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "Card",
+		Args:   nil,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.Card().MergeHistory(ctx, obj)
+		})
+		if resTmp == nil {
+			return graphql.Null
+		}
+		res := resTmp.([]model.Merge)
+		arr1 := graphql.Array{}
+		for idx1 := range res {
+			arr1 = append(arr1, func() graphql.Marshaler {
+				rctx := graphql.GetResolverContext(ctx)
+				rctx.PushIndex(idx1)
+				defer rctx.Pop()
+				return ec._Merge(ctx, field.Selections, &res[idx1])
+			}())
+		}
+		return arr1
+	})
+}
+
+func (ec *executionContext) _Card_groupId(ctx context.Context, field graphql.CollectedField, obj *model.Card) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Card"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.GroupId, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	if res == nil {
+		return graphql.Null
+	}
+	return graphql.MarshalID(*res)
+}
+
 func (ec *executionContext) _Card_statuses(ctx context.Context, field graphql.CollectedField, obj *model.Card) graphql.Marshaler {
 	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
 		Object: "Card",
@@ -427,11 +688,48 @@ func (ec *executionContext) _Card_position(ctx context.Context, field graphql.Co
 	return graphql.MarshalInt(res)
 }
 
-var retrospectiveImplementors = []string{"Retrospective"}
+func (ec *executionContext) _Card_issueUrl(ctx context.Context, field graphql.CollectedField, obj *model.Card) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Card"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.IssueUrl, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	if res == nil {
+		return graphql.Null
+	}
+	return graphql.MarshalString(*res)
+}
+
+func (ec *executionContext) _Card_version(ctx context.Context, field graphql.CollectedField, obj *model.Card) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Card"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Version, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	return graphql.MarshalInt(res)
+}
+
+var cardGroupImplementors = []string{"CardGroup"}
 
 // nolint: gocyclo, errcheck, gas, goconst
-func (ec *executionContext) _Retrospective(ctx context.Context, sel ast.SelectionSet, obj *model.Retrospective) graphql.Marshaler {
-	fields := graphql.CollectFields(ctx, sel, retrospectiveImplementors)
+func (ec *executionContext) _CardGroup(ctx context.Context, sel ast.SelectionSet, obj *model.CardGroup) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, cardGroupImplementors)
 
 	out := graphql.NewOrderedMap(len(fields))
 	for i, field := range fields {
@@ -439,21 +737,25 @@ func (ec *executionContext) _Retrospective(ctx context.Context, sel ast.Selectio
 
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("Retrospective")
+			out.Values[i] = graphql.MarshalString("CardGroup")
 		case "id":
-			out.Values[i] = ec._Retrospective_id(ctx, field, obj)
+			out.Values[i] = ec._CardGroup_id(ctx, field, obj)
 		case "created":
-			out.Values[i] = ec._Retrospective_created(ctx, field, obj)
+			out.Values[i] = ec._CardGroup_created(ctx, field, obj)
 		case "updated":
-			out.Values[i] = ec._Retrospective_updated(ctx, field, obj)
+			out.Values[i] = ec._CardGroup_updated(ctx, field, obj)
+		case "column":
+			out.Values[i] = ec._CardGroup_column(ctx, field, obj)
 		case "name":
-			out.Values[i] = ec._Retrospective_name(ctx, field, obj)
-		case "petName":
-			out.Values[i] = ec._Retrospective_petName(ctx, field, obj)
+			out.Values[i] = ec._CardGroup_name(ctx, field, obj)
+		case "position":
+			out.Values[i] = ec._CardGroup_position(ctx, field, obj)
 		case "cards":
-			out.Values[i] = ec._Retrospective_cards(ctx, field, obj)
-		case "onlineUsers":
-			out.Values[i] = ec._Retrospective_onlineUsers(ctx, field, obj)
+			out.Values[i] = ec._CardGroup_cards(ctx, field, obj)
+		case "votes":
+			out.Values[i] = ec._CardGroup_votes(ctx, field, obj)
+		case "totalVotes":
+			out.Values[i] = ec._CardGroup_totalVotes(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -462,9 +764,9 @@ func (ec *executionContext) _Retrospective(ctx context.Context, sel ast.Selectio
 	return out
 }
 
-func (ec *executionContext) _Retrospective_id(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+func (ec *executionContext) _CardGroup_id(ctx context.Context, field graphql.CollectedField, obj *model.CardGroup) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Retrospective"
+	rctx.Object = "CardGroup"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
@@ -479,9 +781,9 @@ func (ec *executionContext) _Retrospective_id(ctx context.Context, field graphql
 	return graphql.MarshalID(res)
 }
 
-func (ec *executionContext) _Retrospective_created(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+func (ec *executionContext) _CardGroup_created(ctx context.Context, field graphql.CollectedField, obj *model.CardGroup) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Retrospective"
+	rctx.Object = "CardGroup"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
@@ -496,9 +798,9 @@ func (ec *executionContext) _Retrospective_created(ctx context.Context, field gr
 	return graphql.MarshalTime(res)
 }
 
-func (ec *executionContext) _Retrospective_updated(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+func (ec *executionContext) _CardGroup_updated(ctx context.Context, field graphql.CollectedField, obj *model.CardGroup) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Retrospective"
+	rctx.Object = "CardGroup"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
@@ -513,15 +815,15 @@ func (ec *executionContext) _Retrospective_updated(ctx context.Context, field gr
 	return graphql.MarshalTime(res)
 }
 
-func (ec *executionContext) _Retrospective_name(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+func (ec *executionContext) _CardGroup_column(ctx context.Context, field graphql.CollectedField, obj *model.CardGroup) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Retrospective"
+	rctx.Object = "CardGroup"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return obj.Name, nil
+		return obj.Column, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
@@ -530,15 +832,15 @@ func (ec *executionContext) _Retrospective_name(ctx context.Context, field graph
 	return graphql.MarshalString(res)
 }
 
-func (ec *executionContext) _Retrospective_petName(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+func (ec *executionContext) _CardGroup_name(ctx context.Context, field graphql.CollectedField, obj *model.CardGroup) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Retrospective"
+	rctx.Object = "CardGroup"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return obj.PetName, nil
+		return obj.Name, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
@@ -547,11 +849,28 @@ func (ec *executionContext) _Retrospective_petName(ctx context.Context, field gr
 	return graphql.MarshalString(res)
 }
 
-func (ec *executionContext) _Retrospective_cards(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
-	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
-		Object: "Retrospective",
-		Args:   nil,
-		Field:  field,
+func (ec *executionContext) _CardGroup_position(ctx context.Context, field graphql.CollectedField, obj *model.CardGroup) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "CardGroup"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Position, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	return graphql.MarshalInt(res)
+}
+
+func (ec *executionContext) _CardGroup_cards(ctx context.Context, field graphql.CollectedField, obj *model.CardGroup) graphql.Marshaler {
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "CardGroup",
+		Args:   nil,
+		Field:  field,
 	})
 	return graphql.Defer(func() (ret graphql.Marshaler) {
 		defer func() {
@@ -563,31 +882,28 @@ func (ec *executionContext) _Retrospective_cards(ctx context.Context, field grap
 		}()
 
 		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-			return ec.resolvers.Retrospective().Cards(ctx, obj)
+			return ec.resolvers.CardGroup().Cards(ctx, obj)
 		})
 		if resTmp == nil {
 			return graphql.Null
 		}
-		res := resTmp.([]*model.Card)
+		res := resTmp.([]model.Card)
 		arr1 := graphql.Array{}
 		for idx1 := range res {
 			arr1 = append(arr1, func() graphql.Marshaler {
 				rctx := graphql.GetResolverContext(ctx)
 				rctx.PushIndex(idx1)
 				defer rctx.Pop()
-				if res[idx1] == nil {
-					return graphql.Null
-				}
-				return ec._Card(ctx, field.Selections, res[idx1])
+				return ec._Card(ctx, field.Selections, &res[idx1])
 			}())
 		}
 		return arr1
 	})
 }
 
-func (ec *executionContext) _Retrospective_onlineUsers(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+func (ec *executionContext) _CardGroup_votes(ctx context.Context, field graphql.CollectedField, obj *model.CardGroup) graphql.Marshaler {
 	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
-		Object: "Retrospective",
+		Object: "CardGroup",
 		Args:   nil,
 		Field:  field,
 	})
@@ -601,34 +917,56 @@ func (ec *executionContext) _Retrospective_onlineUsers(ctx context.Context, fiel
 		}()
 
 		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-			return ec.resolvers.Retrospective().OnlineUsers(ctx, obj)
+			return ec.resolvers.CardGroup().Votes(ctx, obj)
 		})
 		if resTmp == nil {
 			return graphql.Null
 		}
-		res := resTmp.([]model.UserState)
+		res := resTmp.([]model.VoteTotal)
 		arr1 := graphql.Array{}
 		for idx1 := range res {
 			arr1 = append(arr1, func() graphql.Marshaler {
 				rctx := graphql.GetResolverContext(ctx)
 				rctx.PushIndex(idx1)
 				defer rctx.Pop()
-				return ec._UserState(ctx, field.Selections, &res[idx1])
+				return ec._VoteTotal(ctx, field.Selections, &res[idx1])
 			}())
 		}
 		return arr1
 	})
 }
 
-var rootMutationImplementors = []string{"RootMutation"}
-
-// nolint: gocyclo, errcheck, gas, goconst
-func (ec *executionContext) _RootMutation(ctx context.Context, sel ast.SelectionSet) graphql.Marshaler {
-	fields := graphql.CollectFields(ctx, sel, rootMutationImplementors)
-
+func (ec *executionContext) _CardGroup_totalVotes(ctx context.Context, field graphql.CollectedField, obj *model.CardGroup) graphql.Marshaler {
 	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
-		Object: "RootMutation",
+		Object: "CardGroup",
+		Args:   nil,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.CardGroup().TotalVotes(ctx, obj)
+		})
+		if resTmp == nil {
+			return graphql.Null
+		}
+		res := resTmp.(int)
+		return graphql.MarshalInt(res)
 	})
+}
+
+var mergeImplementors = []string{"Merge"}
+
+// nolint: gocyclo, errcheck, gas, goconst
+func (ec *executionContext) _Merge(ctx context.Context, sel ast.SelectionSet, obj *model.Merge) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, mergeImplementors)
 
 	out := graphql.NewOrderedMap(len(fields))
 	for i, field := range fields {
@@ -636,25 +974,19 @@ func (ec *executionContext) _RootMutation(ctx context.Context, sel ast.Selection
 
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("RootMutation")
-		case "startRetrospective":
-			out.Values[i] = ec._RootMutation_startRetrospective(ctx, field)
-		case "addCardToRetrospective":
-			out.Values[i] = ec._RootMutation_addCardToRetrospective(ctx, field)
-		case "moveCard":
-			out.Values[i] = ec._RootMutation_moveCard(ctx, field)
-		case "mergeCard":
-			out.Values[i] = ec._RootMutation_mergeCard(ctx, field)
-		case "unmergeCard":
-			out.Values[i] = ec._RootMutation_unmergeCard(ctx, field)
-		case "updateMessage":
-			out.Values[i] = ec._RootMutation_updateMessage(ctx, field)
-		case "newVote":
-			out.Values[i] = ec._RootMutation_newVote(ctx, field)
-		case "updateStatus":
-			out.Values[i] = ec._RootMutation_updateStatus(ctx, field)
-		case "sendHeartbeat":
-			out.Values[i] = ec._RootMutation_sendHeartbeat(ctx, field)
+			out.Values[i] = graphql.MarshalString("Merge")
+		case "id":
+			out.Values[i] = ec._Merge_id(ctx, field, obj)
+		case "created":
+			out.Values[i] = ec._Merge_created(ctx, field, obj)
+		case "mergedInto":
+			out.Values[i] = ec._Merge_mergedInto(ctx, field, obj)
+		case "column":
+			out.Values[i] = ec._Merge_column(ctx, field, obj)
+		case "position":
+			out.Values[i] = ec._Merge_position(ctx, field, obj)
+		case "unmerged":
+			out.Values[i] = ec._Merge_unmerged(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -663,247 +995,2246 @@ func (ec *executionContext) _RootMutation(ctx context.Context, sel ast.Selection
 	return out
 }
 
-func (ec *executionContext) _RootMutation_startRetrospective(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args := map[string]interface{}{}
-	var arg0 *string
-	if tmp, ok := rawArgs["name"]; ok {
-		var err error
-		var ptr1 string
-		if tmp != nil {
-			ptr1, err = graphql.UnmarshalString(tmp)
-			arg0 = &ptr1
-		}
-
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
-	}
-	args["name"] = arg0
+func (ec *executionContext) _Merge_id(ctx context.Context, field graphql.CollectedField, obj *model.Merge) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "RootMutation"
-	rctx.Args = args
+	rctx.Object = "Merge"
+	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return ec.resolvers.RootMutation().StartRetrospective(ctx, args["name"].(*string))
+		return obj.Id, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
 	res := resTmp.(string)
-	return graphql.MarshalString(res)
+	return graphql.MarshalID(res)
 }
 
-func (ec *executionContext) _RootMutation_addCardToRetrospective(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["id"]; ok {
-		var err error
-		arg0, err = graphql.UnmarshalID(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
-	}
-	args["id"] = arg0
-	var arg1 *string
-	if tmp, ok := rawArgs["column"]; ok {
-		var err error
-		var ptr1 string
-		if tmp != nil {
-			ptr1, err = graphql.UnmarshalString(tmp)
-			arg1 = &ptr1
-		}
-
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
+func (ec *executionContext) _Merge_created(ctx context.Context, field graphql.CollectedField, obj *model.Merge) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Merge"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Created, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
 	}
-	args["column"] = arg1
-	var arg2 *string
-	if tmp, ok := rawArgs["message"]; ok {
-		var err error
-		var ptr1 string
-		if tmp != nil {
-			ptr1, err = graphql.UnmarshalString(tmp)
-			arg2 = &ptr1
-		}
+	res := resTmp.(time.Time)
+	return graphql.MarshalTime(res)
+}
 
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
-	}
-	args["message"] = arg2
+func (ec *executionContext) _Merge_mergedInto(ctx context.Context, field graphql.CollectedField, obj *model.Merge) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "RootMutation"
-	rctx.Args = args
+	rctx.Object = "Merge"
+	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return ec.resolvers.RootMutation().AddCardToRetrospective(ctx, args["id"].(string), args["column"].(*string), args["message"].(*string))
+		return obj.MergedInto, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
 	res := resTmp.(string)
-	return graphql.MarshalString(res)
+	return graphql.MarshalID(res)
 }
 
-func (ec *executionContext) _RootMutation_moveCard(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["id"]; ok {
-		var err error
-		arg0, err = graphql.UnmarshalID(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
-	}
-	args["id"] = arg0
-	var arg1 string
-	if tmp, ok := rawArgs["column"]; ok {
-		var err error
-		arg1, err = graphql.UnmarshalString(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
-	}
-	args["column"] = arg1
-	var arg2 int
-	if tmp, ok := rawArgs["index"]; ok {
-		var err error
-		arg2, err = graphql.UnmarshalInt(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
-	}
-	args["index"] = arg2
+func (ec *executionContext) _Merge_column(ctx context.Context, field graphql.CollectedField, obj *model.Merge) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "RootMutation"
-	rctx.Args = args
+	rctx.Object = "Merge"
+	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return ec.resolvers.RootMutation().MoveCard(ctx, args["id"].(string), args["column"].(string), args["index"].(int))
+		return obj.Column, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(int)
-	return graphql.MarshalInt(res)
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
 }
 
-func (ec *executionContext) _RootMutation_mergeCard(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["id"]; ok {
-		var err error
-		arg0, err = graphql.UnmarshalID(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
-	}
-	args["id"] = arg0
-	var arg1 string
-	if tmp, ok := rawArgs["mergedInto"]; ok {
-		var err error
-		arg1, err = graphql.UnmarshalID(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
-	}
-	args["mergedInto"] = arg1
+func (ec *executionContext) _Merge_position(ctx context.Context, field graphql.CollectedField, obj *model.Merge) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "RootMutation"
-	rctx.Args = args
+	rctx.Object = "Merge"
+	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return ec.resolvers.RootMutation().MergeCard(ctx, args["id"].(string), args["mergedInto"].(string))
+		return obj.Position, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(string)
-	return graphql.MarshalID(res)
+	res := resTmp.(int)
+	return graphql.MarshalInt(res)
 }
 
-func (ec *executionContext) _RootMutation_unmergeCard(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["id"]; ok {
-		var err error
-		arg0, err = graphql.UnmarshalID(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
-	}
-	args["id"] = arg0
+func (ec *executionContext) _Merge_unmerged(ctx context.Context, field graphql.CollectedField, obj *model.Merge) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "RootMutation"
-	rctx.Args = args
+	rctx.Object = "Merge"
+	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return ec.resolvers.RootMutation().UnmergeCard(ctx, args["id"].(string))
+		return obj.Unmerged, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(string)
-	return graphql.MarshalID(res)
+	res := resTmp.(*time.Time)
+	if res == nil {
+		return graphql.Null
+	}
+	return graphql.MarshalTime(*res)
 }
 
-func (ec *executionContext) _RootMutation_updateMessage(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
-	rawArgs := field.ArgumentMap(ec.Variables)
+var retrospectiveImplementors = []string{"Retrospective"}
+
+// nolint: gocyclo, errcheck, gas, goconst
+func (ec *executionContext) _Retrospective(ctx context.Context, sel ast.SelectionSet, obj *model.Retrospective) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, retrospectiveImplementors)
+
+	out := graphql.NewOrderedMap(len(fields))
+	for i, field := range fields {
+		out.Keys[i] = field.Alias
+
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("Retrospective")
+		case "id":
+			out.Values[i] = ec._Retrospective_id(ctx, field, obj)
+		case "created":
+			out.Values[i] = ec._Retrospective_created(ctx, field, obj)
+		case "updated":
+			out.Values[i] = ec._Retrospective_updated(ctx, field, obj)
+		case "name":
+			out.Values[i] = ec._Retrospective_name(ctx, field, obj)
+		case "petName":
+			out.Values[i] = ec._Retrospective_petName(ctx, field, obj)
+		case "team":
+			out.Values[i] = ec._Retrospective_team(ctx, field, obj)
+		case "closed":
+			out.Values[i] = ec._Retrospective_closed(ctx, field, obj)
+		case "cards":
+			out.Values[i] = ec._Retrospective_cards(ctx, field, obj)
+		case "groups":
+			out.Values[i] = ec._Retrospective_groups(ctx, field, obj)
+		case "onlineUsers":
+			out.Values[i] = ec._Retrospective_onlineUsers(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+
+	return out
+}
+
+func (ec *executionContext) _Retrospective_id(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Retrospective"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Id, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalID(res)
+}
+
+func (ec *executionContext) _Retrospective_created(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Retrospective"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Created, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	return graphql.MarshalTime(res)
+}
+
+func (ec *executionContext) _Retrospective_updated(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Retrospective"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Updated, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	return graphql.MarshalTime(res)
+}
+
+func (ec *executionContext) _Retrospective_name(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Retrospective"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Name, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _Retrospective_petName(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Retrospective"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.PetName, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _Retrospective_team(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Retrospective"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Team, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _Retrospective_closed(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Retrospective"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Closed, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*time.Time)
+	if res == nil {
+		return graphql.Null
+	}
+	return graphql.MarshalTime(*res)
+}
+
+func (ec *executionContext) _Retrospective_cards(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "Retrospective",
+		Args:   nil,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.Retrospective().Cards(ctx, obj)
+		})
+		if resTmp == nil {
+			return graphql.Null
+		}
+		res := resTmp.([]*model.Card)
+		arr1 := graphql.Array{}
+		for idx1 := range res {
+			arr1 = append(arr1, func() graphql.Marshaler {
+				rctx := graphql.GetResolverContext(ctx)
+				rctx.PushIndex(idx1)
+				defer rctx.Pop()
+				if res[idx1] == nil {
+					return graphql.Null
+				}
+				return ec._Card(ctx, field.Selections, res[idx1])
+			}())
+		}
+		return arr1
+	})
+}
+
+func (ec *executionContext) _Retrospective_groups(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "Retrospective",
+		Args:   nil,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.Retrospective().Groups(ctx, obj)
+		})
+		if resTmp == nil {
+			return graphql.Null
+		}
+		res := resTmp.([]model.CardGroup)
+		arr1 := graphql.Array{}
+		for idx1 := range res {
+			arr1 = append(arr1, func() graphql.Marshaler {
+				rctx := graphql.GetResolverContext(ctx)
+				rctx.PushIndex(idx1)
+				defer rctx.Pop()
+				return ec._CardGroup(ctx, field.Selections, &res[idx1])
+			}())
+		}
+		return arr1
+	})
+}
+
+func (ec *executionContext) _Retrospective_onlineUsers(ctx context.Context, field graphql.CollectedField, obj *model.Retrospective) graphql.Marshaler {
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "Retrospective",
+		Args:   nil,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.Retrospective().OnlineUsers(ctx, obj)
+		})
+		if resTmp == nil {
+			return graphql.Null
+		}
+		res := resTmp.([]model.UserState)
+		arr1 := graphql.Array{}
+		for idx1 := range res {
+			arr1 = append(arr1, func() graphql.Marshaler {
+				rctx := graphql.GetResolverContext(ctx)
+				rctx.PushIndex(idx1)
+				defer rctx.Pop()
+				return ec._UserState(ctx, field.Selections, &res[idx1])
+			}())
+		}
+		return arr1
+	})
+}
+
+var rootMutationImplementors = []string{"RootMutation"}
+
+// nolint: gocyclo, errcheck, gas, goconst
+func (ec *executionContext) _RootMutation(ctx context.Context, sel ast.SelectionSet) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, rootMutationImplementors)
+
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "RootMutation",
+	})
+
+	out := graphql.NewOrderedMap(len(fields))
+	for i, field := range fields {
+		out.Keys[i] = field.Alias
+
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("RootMutation")
+		case "startRetrospective":
+			out.Values[i] = ec._RootMutation_startRetrospective(ctx, field)
+		case "closeRetrospective":
+			out.Values[i] = ec._RootMutation_closeRetrospective(ctx, field)
+		case "addCardToRetrospective":
+			out.Values[i] = ec._RootMutation_addCardToRetrospective(ctx, field)
+		case "moveCard":
+			out.Values[i] = ec._RootMutation_moveCard(ctx, field)
+		case "mergeCard":
+			out.Values[i] = ec._RootMutation_mergeCard(ctx, field)
+		case "unmergeCard":
+			out.Values[i] = ec._RootMutation_unmergeCard(ctx, field)
+		case "unmergeAll":
+			out.Values[i] = ec._RootMutation_unmergeAll(ctx, field)
+		case "moveMergedCard":
+			out.Values[i] = ec._RootMutation_moveMergedCard(ctx, field)
+		case "setMergeTitle":
+			out.Values[i] = ec._RootMutation_setMergeTitle(ctx, field)
+		case "createCardGroup":
+			out.Values[i] = ec._RootMutation_createCardGroup(ctx, field)
+		case "renameCardGroup":
+			out.Values[i] = ec._RootMutation_renameCardGroup(ctx, field)
+		case "moveCardGroup":
+			out.Values[i] = ec._RootMutation_moveCardGroup(ctx, field)
+		case "dissolveCardGroup":
+			out.Values[i] = ec._RootMutation_dissolveCardGroup(ctx, field)
+		case "moveCardToGroup":
+			out.Values[i] = ec._RootMutation_moveCardToGroup(ctx, field)
+		case "updateMessage":
+			out.Values[i] = ec._RootMutation_updateMessage(ctx, field)
+		case "newVote":
+			out.Values[i] = ec._RootMutation_newVote(ctx, field)
+		case "updateStatus":
+			out.Values[i] = ec._RootMutation_updateStatus(ctx, field)
+		case "sendHeartbeat":
+			out.Values[i] = ec._RootMutation_sendHeartbeat(ctx, field)
+		case "createWebhook":
+			out.Values[i] = ec._RootMutation_createWebhook(ctx, field)
+		case "deleteWebhook":
+			out.Values[i] = ec._RootMutation_deleteWebhook(ctx, field)
+		case "configureTeam":
+			out.Values[i] = ec._RootMutation_configureTeam(ctx, field)
+		case "postSummary":
+			out.Values[i] = ec._RootMutation_postSummary(ctx, field)
+		case "exportCard":
+			out.Values[i] = ec._RootMutation_exportCard(ctx, field)
+		case "exportActionItems":
+			out.Values[i] = ec._RootMutation_exportActionItems(ctx, field)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+
+	return out
+}
+
+func (ec *executionContext) _RootMutation_startRetrospective(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 *string
+	if tmp, ok := rawArgs["name"]; ok {
+		var err error
+		var ptr1 string
+		if tmp != nil {
+			ptr1, err = graphql.UnmarshalString(tmp)
+			arg0 = &ptr1
+		}
+
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["name"] = arg0
+	var arg1 *string
+	if tmp, ok := rawArgs["team"]; ok {
+		var err error
+		var ptr1 string
+		if tmp != nil {
+			ptr1, err = graphql.UnmarshalString(tmp)
+			arg1 = &ptr1
+		}
+
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["team"] = arg1
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().StartRetrospective(ctx, args["name"].(*string), args["team"].(*string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _RootMutation_closeRetrospective(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().CloseRetrospective(ctx, args["id"].(string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(model.Retrospective)
+	return ec._Retrospective(ctx, field.Selections, &res)
+}
+
+func (ec *executionContext) _RootMutation_addCardToRetrospective(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	var arg1 *string
+	if tmp, ok := rawArgs["column"]; ok {
+		var err error
+		var ptr1 string
+		if tmp != nil {
+			ptr1, err = graphql.UnmarshalString(tmp)
+			arg1 = &ptr1
+		}
+
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["column"] = arg1
+	var arg2 *string
+	if tmp, ok := rawArgs["message"]; ok {
+		var err error
+		var ptr1 string
+		if tmp != nil {
+			ptr1, err = graphql.UnmarshalString(tmp)
+			arg2 = &ptr1
+		}
+
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["message"] = arg2
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().AddCardToRetrospective(ctx, args["id"].(string), args["column"].(*string), args["message"].(*string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _RootMutation_moveCard(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["column"]; ok {
+		var err error
+		arg1, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["column"] = arg1
+	var arg2 int
+	if tmp, ok := rawArgs["index"]; ok {
+		var err error
+		arg2, err = graphql.UnmarshalInt(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["index"] = arg2
+	var arg3 int
+	if tmp, ok := rawArgs["version"]; ok {
+		var err error
+		arg3, err = graphql.UnmarshalInt(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["version"] = arg3
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().MoveCard(ctx, args["id"].(string), args["column"].(string), args["index"].(int), args["version"].(int))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	return graphql.MarshalInt(res)
+}
+
+func (ec *executionContext) _RootMutation_mergeCard(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["mergedInto"]; ok {
+		var err error
+		arg1, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["mergedInto"] = arg1
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().MergeCard(ctx, args["id"].(string), args["mergedInto"].(string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalID(res)
+}
+
+func (ec *executionContext) _RootMutation_unmergeCard(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().UnmergeCard(ctx, args["id"].(string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalID(res)
+}
+
+func (ec *executionContext) _RootMutation_unmergeAll(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().UnmergeAll(ctx, args["id"].(string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalID(res)
+}
+
+func (ec *executionContext) _RootMutation_moveMergedCard(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	var arg1 int
+	if tmp, ok := rawArgs["index"]; ok {
+		var err error
+		arg1, err = graphql.UnmarshalInt(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["index"] = arg1
+	var arg2 int
+	if tmp, ok := rawArgs["version"]; ok {
+		var err error
+		arg2, err = graphql.UnmarshalInt(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["version"] = arg2
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().MoveMergedCard(ctx, args["id"].(string), args["index"].(int), args["version"].(int))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	return graphql.MarshalInt(res)
+}
+
+func (ec *executionContext) _RootMutation_setMergeTitle(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["title"]; ok {
+		var err error
+		arg1, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["title"] = arg1
+	var arg2 int
+	if tmp, ok := rawArgs["version"]; ok {
+		var err error
+		arg2, err = graphql.UnmarshalInt(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["version"] = arg2
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().SetMergeTitle(ctx, args["id"].(string), args["title"].(string), args["version"].(int))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	if res == nil {
+		return graphql.Null
+	}
+	return graphql.MarshalString(*res)
+}
+
+func (ec *executionContext) _RootMutation_createCardGroup(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["rId"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["rId"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["column"]; ok {
+		var err error
+		arg1, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["column"] = arg1
+	var arg2 string
+	if tmp, ok := rawArgs["name"]; ok {
+		var err error
+		arg2, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["name"] = arg2
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().CreateCardGroup(ctx, args["rId"].(string), args["column"].(string), args["name"].(string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(model.CardGroup)
+	return ec._CardGroup(ctx, field.Selections, &res)
+}
+
+func (ec *executionContext) _RootMutation_renameCardGroup(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["name"]; ok {
+		var err error
+		arg1, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["name"] = arg1
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().RenameCardGroup(ctx, args["id"].(string), args["name"].(string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(model.CardGroup)
+	return ec._CardGroup(ctx, field.Selections, &res)
+}
+
+func (ec *executionContext) _RootMutation_moveCardGroup(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	var arg1 int
+	if tmp, ok := rawArgs["index"]; ok {
+		var err error
+		arg1, err = graphql.UnmarshalInt(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["index"] = arg1
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().MoveCardGroup(ctx, args["id"].(string), args["index"].(int))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	return graphql.MarshalInt(res)
+}
+
+func (ec *executionContext) _RootMutation_dissolveCardGroup(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().DissolveCardGroup(ctx, args["id"].(string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalID(res)
+}
+
+func (ec *executionContext) _RootMutation_moveCardToGroup(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["groupId"]; ok {
+		var err error
+		arg1, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["groupId"] = arg1
+	var arg2 int
+	if tmp, ok := rawArgs["index"]; ok {
+		var err error
+		arg2, err = graphql.UnmarshalInt(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["index"] = arg2
+	var arg3 int
+	if tmp, ok := rawArgs["version"]; ok {
+		var err error
+		arg3, err = graphql.UnmarshalInt(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["version"] = arg3
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().MoveCardToGroup(ctx, args["id"].(string), args["groupId"].(string), args["index"].(int), args["version"].(int))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	return graphql.MarshalInt(res)
+}
+
+func (ec *executionContext) _RootMutation_updateMessage(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["message"]; ok {
+		var err error
+		arg1, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["message"] = arg1
+	var arg2 int
+	if tmp, ok := rawArgs["version"]; ok {
+		var err error
+		arg2, err = graphql.UnmarshalInt(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["version"] = arg2
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().UpdateMessage(ctx, args["id"].(string), args["message"].(string), args["version"].(int))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _RootMutation_newVote(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["cardId"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["cardId"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["emoji"]; ok {
+		var err error
+		arg1, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["emoji"] = arg1
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().NewVote(ctx, args["cardId"].(string), args["emoji"].(string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(model.Vote)
+	return ec._Vote(ctx, field.Selections, &res)
+}
+
+func (ec *executionContext) _RootMutation_updateStatus(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	var arg1 model.StatusType
+	if tmp, ok := rawArgs["status"]; ok {
+		var err error
+		err = (&arg1).UnmarshalGQL(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["status"] = arg1
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().UpdateStatus(ctx, args["id"].(string), args["status"].(model.StatusType))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(model.Status)
+	return ec._Status(ctx, field.Selections, &res)
+}
+
+func (ec *executionContext) _RootMutation_sendHeartbeat(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["rId"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["rId"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["state"]; ok {
+		var err error
+		arg1, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["state"] = arg1
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().SendHeartbeat(ctx, args["rId"].(string), args["state"].(string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _RootMutation_createWebhook(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 *string
+	if tmp, ok := rawArgs["rId"]; ok {
+		var err error
+		var ptr1 string
+		if tmp != nil {
+			ptr1, err = graphql.UnmarshalID(tmp)
+			arg0 = &ptr1
+		}
+
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["rId"] = arg0
+	var arg1 *string
+	if tmp, ok := rawArgs["team"]; ok {
+		var err error
+		var ptr1 string
+		if tmp != nil {
+			ptr1, err = graphql.UnmarshalString(tmp)
+			arg1 = &ptr1
+		}
+
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["team"] = arg1
+	var arg2 string
+	if tmp, ok := rawArgs["url"]; ok {
+		var err error
+		arg2, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["url"] = arg2
+	var arg3 string
+	if tmp, ok := rawArgs["secret"]; ok {
+		var err error
+		arg3, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["secret"] = arg3
+	var arg4 []string
+	if tmp, ok := rawArgs["events"]; ok {
+		var err error
+		var rawIf1 []interface{}
+		if tmp != nil {
+			if tmp1, ok := tmp.([]interface{}); ok {
+				rawIf1 = tmp1
+			} else {
+				rawIf1 = []interface{}{tmp}
+			}
+		}
+		arg4 = make([]string, len(rawIf1))
+		for idx1 := range rawIf1 {
+			arg4[idx1], err = graphql.UnmarshalString(rawIf1[idx1])
+		}
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["events"] = arg4
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().CreateWebhook(ctx, args["rId"].(*string), args["team"].(*string), args["url"].(string), args["secret"].(string), args["events"].([]string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(model.Webhook)
+	return ec._Webhook(ctx, field.Selections, &res)
+}
+
+func (ec *executionContext) _RootMutation_deleteWebhook(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().DeleteWebhook(ctx, args["id"].(string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalID(res)
+}
+
+func (ec *executionContext) _RootMutation_configureTeam(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["name"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["name"] = arg0
+	var arg1 *string
+	if tmp, ok := rawArgs["chatWebhookUrl"]; ok {
+		var err error
+		var ptr1 string
+		if tmp != nil {
+			ptr1, err = graphql.UnmarshalString(tmp)
+			arg1 = &ptr1
+		}
+
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["chatWebhookUrl"] = arg1
+	var arg2 *int
+	if tmp, ok := rawArgs["retentionDays"]; ok {
+		var err error
+		var ptr1 int
+		if tmp != nil {
+			ptr1, err = graphql.UnmarshalInt(tmp)
+			arg2 = &ptr1
+		}
+
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["retentionDays"] = arg2
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().ConfigureTeam(ctx, args["name"].(string), args["chatWebhookUrl"].(*string), args["retentionDays"].(*int))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(model.Team)
+	return ec._Team(ctx, field.Selections, &res)
+}
+
+func (ec *executionContext) _RootMutation_postSummary(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["rId"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["rId"] = arg0
+	var arg1 *int
+	if tmp, ok := rawArgs["limit"]; ok {
+		var err error
+		var ptr1 int
+		if tmp != nil {
+			ptr1, err = graphql.UnmarshalInt(tmp)
+			arg1 = &ptr1
+		}
+
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["limit"] = arg1
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().PostSummary(ctx, args["rId"].(string), args["limit"].(*int))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _RootMutation_exportCard(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["provider"]; ok {
+		var err error
+		arg1, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["provider"] = arg1
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().ExportCard(ctx, args["id"].(string), args["provider"].(string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(model.Card)
+	return ec._Card(ctx, field.Selections, &res)
+}
+
+func (ec *executionContext) _RootMutation_exportActionItems(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["rId"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["rId"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["provider"]; ok {
+		var err error
+		arg1, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["provider"] = arg1
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootMutation"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.resolvers.RootMutation().ExportActionItems(ctx, args["rId"].(string), args["provider"].(string))
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.([]model.Card)
+	arr1 := graphql.Array{}
+	for idx1 := range res {
+		arr1 = append(arr1, func() graphql.Marshaler {
+			rctx := graphql.GetResolverContext(ctx)
+			rctx.PushIndex(idx1)
+			defer rctx.Pop()
+			return ec._Card(ctx, field.Selections, &res[idx1])
+		}())
+	}
+	return arr1
+}
+
+var rootQueryImplementors = []string{"RootQuery"}
+
+// nolint: gocyclo, errcheck, gas, goconst
+func (ec *executionContext) _RootQuery(ctx context.Context, sel ast.SelectionSet) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, rootQueryImplementors)
+
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "RootQuery",
+	})
+
+	out := graphql.NewOrderedMap(len(fields))
+	for i, field := range fields {
+		out.Keys[i] = field.Alias
+
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("RootQuery")
+		case "retrospectiveById":
+			out.Values[i] = ec._RootQuery_retrospectiveById(ctx, field)
+		case "retrospectiveByPetName":
+			out.Values[i] = ec._RootQuery_retrospectiveByPetName(ctx, field)
+		case "webhooks":
+			out.Values[i] = ec._RootQuery_webhooks(ctx, field)
+		case "team":
+			out.Values[i] = ec._RootQuery_team(ctx, field)
+		case "archives":
+			out.Values[i] = ec._RootQuery_archives(ctx, field)
+		case "exportProviders":
+			out.Values[i] = ec._RootQuery_exportProviders(ctx, field)
+		case "__type":
+			out.Values[i] = ec._RootQuery___type(ctx, field)
+		case "__schema":
+			out.Values[i] = ec._RootQuery___schema(ctx, field)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+
+	return out
+}
+
+func (ec *executionContext) _RootQuery_retrospectiveById(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["id"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalID(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["id"] = arg0
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "RootQuery",
+		Args:   args,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.RootQuery().RetrospectiveByID(ctx, args["id"].(string))
+		})
+		if resTmp == nil {
+			return graphql.Null
+		}
+		res := resTmp.(*model.Retrospective)
+		if res == nil {
+			return graphql.Null
+		}
+		return ec._Retrospective(ctx, field.Selections, res)
+	})
+}
+
+func (ec *executionContext) _RootQuery_retrospectiveByPetName(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["petName"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["petName"] = arg0
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "RootQuery",
+		Args:   args,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.RootQuery().RetrospectiveByPetName(ctx, args["petName"].(string))
+		})
+		if resTmp == nil {
+			return graphql.Null
+		}
+		res := resTmp.(*model.Retrospective)
+		if res == nil {
+			return graphql.Null
+		}
+		return ec._Retrospective(ctx, field.Selections, res)
+	})
+}
+
+func (ec *executionContext) _RootQuery_webhooks(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 *string
+	if tmp, ok := rawArgs["rId"]; ok {
+		var err error
+		var ptr1 string
+		if tmp != nil {
+			ptr1, err = graphql.UnmarshalID(tmp)
+			arg0 = &ptr1
+		}
+
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["rId"] = arg0
+	var arg1 *string
+	if tmp, ok := rawArgs["team"]; ok {
+		var err error
+		var ptr1 string
+		if tmp != nil {
+			ptr1, err = graphql.UnmarshalString(tmp)
+			arg1 = &ptr1
+		}
+
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["team"] = arg1
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "RootQuery",
+		Args:   args,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.RootQuery().Webhooks(ctx, args["rId"].(*string), args["team"].(*string))
+		})
+		if resTmp == nil {
+			return graphql.Null
+		}
+		res := resTmp.([]model.Webhook)
+		arr1 := graphql.Array{}
+		for idx1 := range res {
+			arr1 = append(arr1, func() graphql.Marshaler {
+				rctx := graphql.GetResolverContext(ctx)
+				rctx.PushIndex(idx1)
+				defer rctx.Pop()
+				return ec._Webhook(ctx, field.Selections, &res[idx1])
+			}())
+		}
+		return arr1
+	})
+}
+
+func (ec *executionContext) _RootQuery_team(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["name"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["name"] = arg0
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "RootQuery",
+		Args:   args,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.RootQuery().Team(ctx, args["name"].(string))
+		})
+		if resTmp == nil {
+			return graphql.Null
+		}
+		res := resTmp.(*model.Team)
+		if res == nil {
+			return graphql.Null
+		}
+		return ec._Team(ctx, field.Selections, res)
+	})
+}
+
+func (ec *executionContext) _RootQuery_archives(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["team"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["team"] = arg0
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "RootQuery",
+		Args:   args,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.RootQuery().Archives(ctx, args["team"].(string))
+		})
+		if resTmp == nil {
+			return graphql.Null
+		}
+		res := resTmp.([]model.Archive)
+		arr1 := graphql.Array{}
+		for idx1 := range res {
+			arr1 = append(arr1, func() graphql.Marshaler {
+				rctx := graphql.GetResolverContext(ctx)
+				rctx.PushIndex(idx1)
+				defer rctx.Pop()
+				return ec._Archive(ctx, field.Selections, &res[idx1])
+			}())
+		}
+		return arr1
+	})
+}
+
+func (ec *executionContext) _RootQuery_exportProviders(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "RootQuery",
+		Args:   nil,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.RootQuery().ExportProviders(ctx)
+		})
+		if resTmp == nil {
+			return graphql.Null
+		}
+		res := resTmp.([]string)
+		arr1 := graphql.Array{}
+		for idx1 := range res {
+			arr1 = append(arr1, func() graphql.Marshaler {
+				rctx := graphql.GetResolverContext(ctx)
+				rctx.PushIndex(idx1)
+				defer rctx.Pop()
+				return graphql.MarshalString(res[idx1])
+			}())
+		}
+		return arr1
+	})
+}
+
+func (ec *executionContext) _RootQuery___type(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["name"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return graphql.Null
+		}
+	}
+	args["name"] = arg0
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootQuery"
+	rctx.Args = args
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.introspectType(args["name"].(string)), nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*introspection.Type)
+	if res == nil {
+		return graphql.Null
+	}
+	return ec.___Type(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _RootQuery___schema(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "RootQuery"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return ec.introspectSchema(), nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*introspection.Schema)
+	if res == nil {
+		return graphql.Null
+	}
+	return ec.___Schema(ctx, field.Selections, res)
+}
+
+var statusImplementors = []string{"Status"}
+
+// nolint: gocyclo, errcheck, gas, goconst
+func (ec *executionContext) _Status(ctx context.Context, sel ast.SelectionSet, obj *model.Status) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, statusImplementors)
+
+	out := graphql.NewOrderedMap(len(fields))
+	for i, field := range fields {
+		out.Keys[i] = field.Alias
+
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("Status")
+		case "id":
+			out.Values[i] = ec._Status_id(ctx, field, obj)
+		case "created":
+			out.Values[i] = ec._Status_created(ctx, field, obj)
+		case "cardId":
+			out.Values[i] = ec._Status_cardId(ctx, field, obj)
+		case "type":
+			out.Values[i] = ec._Status_type(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+
+	return out
+}
+
+func (ec *executionContext) _Status_id(ctx context.Context, field graphql.CollectedField, obj *model.Status) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Status"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Id, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalID(res)
+}
+
+func (ec *executionContext) _Status_created(ctx context.Context, field graphql.CollectedField, obj *model.Status) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Status"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Created, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	return graphql.MarshalTime(res)
+}
+
+func (ec *executionContext) _Status_cardId(ctx context.Context, field graphql.CollectedField, obj *model.Status) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Status"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.CardId, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _Status_type(ctx context.Context, field graphql.CollectedField, obj *model.Status) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Status"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Type, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(model.StatusType)
+	return res
+}
+
+var subscriptionImplementors = []string{"Subscription"}
+
+// nolint: gocyclo, errcheck, gas, goconst
+func (ec *executionContext) _Subscription(ctx context.Context, sel ast.SelectionSet) func() graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, subscriptionImplementors)
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "Subscription",
+	})
+	if len(fields) != 1 {
+		ec.Errorf(ctx, "must subscribe to exactly one stream")
+		return nil
+	}
+
+	switch fields[0].Name {
+	case "cardChanged":
+		return ec._Subscription_cardChanged(ctx, fields[0])
+	case "retroChanged":
+		return ec._Subscription_retroChanged(ctx, fields[0])
+	default:
+		panic("unknown field " + strconv.Quote(fields[0].Name))
+	}
+}
+
+func (ec *executionContext) _Subscription_cardChanged(ctx context.Context, field graphql.CollectedField) func() graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
 	args := map[string]interface{}{}
 	var arg0 string
-	if tmp, ok := rawArgs["id"]; ok {
+	if tmp, ok := rawArgs["rId"]; ok {
 		var err error
-		arg0, err = graphql.UnmarshalID(tmp)
+		arg0, err = graphql.UnmarshalString(tmp)
+		if err != nil {
+			ec.Error(ctx, err)
+			return nil
+		}
+	}
+	args["rId"] = arg0
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{Field: field})
+	results, err := ec.resolvers.Subscription().CardChanged(ctx, args["rId"].(string))
+	if err != nil {
+		ec.Error(ctx, err)
+		return nil
+	}
+	return func() graphql.Marshaler {
+		res, ok := <-results
+		if !ok {
+			return nil
+		}
+		var out graphql.OrderedMap
+		out.Add(field.Alias, func() graphql.Marshaler { return ec._Card(ctx, field.Selections, &res) }())
+		return &out
+	}
+}
+
+func (ec *executionContext) _Subscription_retroChanged(ctx context.Context, field graphql.CollectedField) func() graphql.Marshaler {
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["rId"]; ok {
+		var err error
+		arg0, err = graphql.UnmarshalString(tmp)
 		if err != nil {
 			ec.Error(ctx, err)
+			return nil
+		}
+	}
+	args["rId"] = arg0
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{Field: field})
+	results, err := ec.resolvers.Subscription().RetroChanged(ctx, args["rId"].(string))
+	if err != nil {
+		ec.Error(ctx, err)
+		return nil
+	}
+	return func() graphql.Marshaler {
+		res, ok := <-results
+		if !ok {
+			return nil
+		}
+		var out graphql.OrderedMap
+		out.Add(field.Alias, func() graphql.Marshaler { return ec._Retrospective(ctx, field.Selections, &res) }())
+		return &out
+	}
+}
+
+var teamImplementors = []string{"Team"}
+
+// nolint: gocyclo, errcheck, gas, goconst
+func (ec *executionContext) _Team(ctx context.Context, sel ast.SelectionSet, obj *model.Team) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, teamImplementors)
+
+	out := graphql.NewOrderedMap(len(fields))
+	for i, field := range fields {
+		out.Keys[i] = field.Alias
+
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("Team")
+		case "name":
+			out.Values[i] = ec._Team_name(ctx, field, obj)
+		case "created":
+			out.Values[i] = ec._Team_created(ctx, field, obj)
+		case "updated":
+			out.Values[i] = ec._Team_updated(ctx, field, obj)
+		case "chatWebhookConfigured":
+			out.Values[i] = ec._Team_chatWebhookConfigured(ctx, field, obj)
+		case "retentionDays":
+			out.Values[i] = ec._Team_retentionDays(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+
+	return out
+}
+
+func (ec *executionContext) _Team_name(ctx context.Context, field graphql.CollectedField, obj *model.Team) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Team"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Name, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _Team_created(ctx context.Context, field graphql.CollectedField, obj *model.Team) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Team"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Created, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	return graphql.MarshalTime(res)
+}
+
+func (ec *executionContext) _Team_updated(ctx context.Context, field graphql.CollectedField, obj *model.Team) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Team"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Updated, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	return graphql.MarshalTime(res)
+}
+
+func (ec *executionContext) _Team_chatWebhookConfigured(ctx context.Context, field graphql.CollectedField, obj *model.Team) graphql.Marshaler {
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "Team",
+		Args:   nil,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.Team().ChatWebhookConfigured(ctx, obj)
+		})
+		if resTmp == nil {
 			return graphql.Null
 		}
+		res := resTmp.(bool)
+		return graphql.MarshalBoolean(res)
+	})
+}
+
+func (ec *executionContext) _Team_retentionDays(ctx context.Context, field graphql.CollectedField, obj *model.Team) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Team"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.RetentionDays, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
 	}
-	args["id"] = arg0
-	var arg1 string
-	if tmp, ok := rawArgs["message"]; ok {
-		var err error
-		arg1, err = graphql.UnmarshalString(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
+	res := resTmp.(*int)
+	if res == nil {
+		return graphql.Null
+	}
+	return graphql.MarshalInt(*res)
+}
+
+var userStateImplementors = []string{"UserState"}
+
+// nolint: gocyclo, errcheck, gas, goconst
+func (ec *executionContext) _UserState(ctx context.Context, sel ast.SelectionSet, obj *model.UserState) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, userStateImplementors)
+
+	out := graphql.NewOrderedMap(len(fields))
+	for i, field := range fields {
+		out.Keys[i] = field.Alias
+
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("UserState")
+		case "user":
+			out.Values[i] = ec._UserState_user(ctx, field, obj)
+		case "state":
+			out.Values[i] = ec._UserState_state(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
 		}
 	}
-	args["message"] = arg1
+
+	return out
+}
+
+func (ec *executionContext) _UserState_user(ctx context.Context, field graphql.CollectedField, obj *model.UserState) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "RootMutation"
-	rctx.Args = args
+	rctx.Object = "UserState"
+	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return ec.resolvers.RootMutation().UpdateMessage(ctx, args["id"].(string), args["message"].(string))
+		return obj.User, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
@@ -912,115 +3243,152 @@ func (ec *executionContext) _RootMutation_updateMessage(ctx context.Context, fie
 	return graphql.MarshalString(res)
 }
 
-func (ec *executionContext) _RootMutation_newVote(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["cardId"]; ok {
-		var err error
-		arg0, err = graphql.UnmarshalID(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
+func (ec *executionContext) _UserState_state(ctx context.Context, field graphql.CollectedField, obj *model.UserState) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "UserState"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.State, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
 	}
-	args["cardId"] = arg0
-	var arg1 string
-	if tmp, ok := rawArgs["emoji"]; ok {
-		var err error
-		arg1, err = graphql.UnmarshalString(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
+	res := resTmp.(model.UserStateType)
+	return res
+}
+
+var voteImplementors = []string{"Vote"}
+
+// nolint: gocyclo, errcheck, gas, goconst
+func (ec *executionContext) _Vote(ctx context.Context, sel ast.SelectionSet, obj *model.Vote) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, voteImplementors)
+
+	out := graphql.NewOrderedMap(len(fields))
+	for i, field := range fields {
+		out.Keys[i] = field.Alias
+
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("Vote")
+		case "id":
+			out.Values[i] = ec._Vote_id(ctx, field, obj)
+		case "created":
+			out.Values[i] = ec._Vote_created(ctx, field, obj)
+		case "updated":
+			out.Values[i] = ec._Vote_updated(ctx, field, obj)
+		case "cardId":
+			out.Values[i] = ec._Vote_cardId(ctx, field, obj)
+		case "voter":
+			out.Values[i] = ec._Vote_voter(ctx, field, obj)
+		case "emoji":
+			out.Values[i] = ec._Vote_emoji(ctx, field, obj)
+		case "count":
+			out.Values[i] = ec._Vote_count(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
 		}
 	}
-	args["emoji"] = arg1
+
+	return out
+}
+
+func (ec *executionContext) _Vote_id(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "RootMutation"
-	rctx.Args = args
+	rctx.Object = "Vote"
+	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return ec.resolvers.RootMutation().NewVote(ctx, args["cardId"].(string), args["emoji"].(string))
+		return obj.Id, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(model.Vote)
-	return ec._Vote(ctx, field.Selections, &res)
+	res := resTmp.(string)
+	return graphql.MarshalID(res)
 }
 
-func (ec *executionContext) _RootMutation_updateStatus(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["id"]; ok {
-		var err error
-		arg0, err = graphql.UnmarshalID(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
-	}
-	args["id"] = arg0
-	var arg1 model.StatusType
-	if tmp, ok := rawArgs["status"]; ok {
-		var err error
-		err = (&arg1).UnmarshalGQL(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
+func (ec *executionContext) _Vote_created(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Vote"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Created, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
 	}
-	args["status"] = arg1
+	res := resTmp.(time.Time)
+	return graphql.MarshalTime(res)
+}
+
+func (ec *executionContext) _Vote_updated(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "RootMutation"
-	rctx.Args = args
+	rctx.Object = "Vote"
+	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return ec.resolvers.RootMutation().UpdateStatus(ctx, args["id"].(string), args["status"].(model.StatusType))
+		return obj.Updated, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(model.Status)
-	return ec._Status(ctx, field.Selections, &res)
+	res := resTmp.(time.Time)
+	return graphql.MarshalTime(res)
 }
 
-func (ec *executionContext) _RootMutation_sendHeartbeat(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["rId"]; ok {
-		var err error
-		arg0, err = graphql.UnmarshalID(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
+func (ec *executionContext) _Vote_cardId(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Vote"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.CardId, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
 	}
-	args["rId"] = arg0
-	var arg1 string
-	if tmp, ok := rawArgs["state"]; ok {
-		var err error
-		arg1, err = graphql.UnmarshalString(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _Vote_voter(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Vote"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Voter, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
 	}
-	args["state"] = arg1
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
+}
+
+func (ec *executionContext) _Vote_emoji(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "RootMutation"
-	rctx.Args = args
+	rctx.Object = "Vote"
+	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return ec.resolvers.RootMutation().SendHeartbeat(ctx, args["rId"].(string), args["state"].(string))
+		return obj.Emoji, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
@@ -1029,15 +3397,28 @@ func (ec *executionContext) _RootMutation_sendHeartbeat(ctx context.Context, fie
 	return graphql.MarshalString(res)
 }
 
-var rootQueryImplementors = []string{"RootQuery"}
+func (ec *executionContext) _Vote_count(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Vote"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Count, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	return graphql.MarshalInt(res)
+}
 
-// nolint: gocyclo, errcheck, gas, goconst
-func (ec *executionContext) _RootQuery(ctx context.Context, sel ast.SelectionSet) graphql.Marshaler {
-	fields := graphql.CollectFields(ctx, sel, rootQueryImplementors)
+var voteTotalImplementors = []string{"VoteTotal"}
 
-	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
-		Object: "RootQuery",
-	})
+// nolint: gocyclo, errcheck, gas, goconst
+func (ec *executionContext) _VoteTotal(ctx context.Context, sel ast.SelectionSet, obj *model.VoteTotal) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, voteTotalImplementors)
 
 	out := graphql.NewOrderedMap(len(fields))
 	for i, field := range fields {
@@ -1045,15 +3426,11 @@ func (ec *executionContext) _RootQuery(ctx context.Context, sel ast.SelectionSet
 
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("RootQuery")
-		case "retrospectiveById":
-			out.Values[i] = ec._RootQuery_retrospectiveById(ctx, field)
-		case "retrospectiveByPetName":
-			out.Values[i] = ec._RootQuery_retrospectiveByPetName(ctx, field)
-		case "__type":
-			out.Values[i] = ec._RootQuery___type(ctx, field)
-		case "__schema":
-			out.Values[i] = ec._RootQuery___schema(ctx, field)
+			out.Values[i] = graphql.MarshalString("VoteTotal")
+		case "emoji":
+			out.Values[i] = ec._VoteTotal_emoji(ctx, field, obj)
+		case "count":
+			out.Values[i] = ec._VoteTotal_count(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -1062,145 +3439,45 @@ func (ec *executionContext) _RootQuery(ctx context.Context, sel ast.SelectionSet
 	return out
 }
 
-func (ec *executionContext) _RootQuery_retrospectiveById(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["id"]; ok {
-		var err error
-		arg0, err = graphql.UnmarshalID(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
-	}
-	args["id"] = arg0
-	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
-		Object: "RootQuery",
-		Args:   args,
-		Field:  field,
-	})
-	return graphql.Defer(func() (ret graphql.Marshaler) {
-		defer func() {
-			if r := recover(); r != nil {
-				userErr := ec.Recover(ctx, r)
-				ec.Error(ctx, userErr)
-				ret = graphql.Null
-			}
-		}()
-
-		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-			return ec.resolvers.RootQuery().RetrospectiveByID(ctx, args["id"].(string))
-		})
-		if resTmp == nil {
-			return graphql.Null
-		}
-		res := resTmp.(*model.Retrospective)
-		if res == nil {
-			return graphql.Null
-		}
-		return ec._Retrospective(ctx, field.Selections, res)
-	})
-}
-
-func (ec *executionContext) _RootQuery_retrospectiveByPetName(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["petName"]; ok {
-		var err error
-		arg0, err = graphql.UnmarshalString(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
-	}
-	args["petName"] = arg0
-	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
-		Object: "RootQuery",
-		Args:   args,
-		Field:  field,
-	})
-	return graphql.Defer(func() (ret graphql.Marshaler) {
-		defer func() {
-			if r := recover(); r != nil {
-				userErr := ec.Recover(ctx, r)
-				ec.Error(ctx, userErr)
-				ret = graphql.Null
-			}
-		}()
-
-		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-			return ec.resolvers.RootQuery().RetrospectiveByPetName(ctx, args["petName"].(string))
-		})
-		if resTmp == nil {
-			return graphql.Null
-		}
-		res := resTmp.(*model.Retrospective)
-		if res == nil {
-			return graphql.Null
-		}
-		return ec._Retrospective(ctx, field.Selections, res)
-	})
-}
-
-func (ec *executionContext) _RootQuery___type(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["name"]; ok {
-		var err error
-		arg0, err = graphql.UnmarshalString(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return graphql.Null
-		}
-	}
-	args["name"] = arg0
+func (ec *executionContext) _VoteTotal_emoji(ctx context.Context, field graphql.CollectedField, obj *model.VoteTotal) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "RootQuery"
-	rctx.Args = args
+	rctx.Object = "VoteTotal"
+	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return ec.introspectType(args["name"].(string)), nil
+		return obj.Emoji, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(*introspection.Type)
-	if res == nil {
-		return graphql.Null
-	}
-	return ec.___Type(ctx, field.Selections, res)
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
 }
 
-func (ec *executionContext) _RootQuery___schema(ctx context.Context, field graphql.CollectedField) graphql.Marshaler {
+func (ec *executionContext) _VoteTotal_count(ctx context.Context, field graphql.CollectedField, obj *model.VoteTotal) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "RootQuery"
+	rctx.Object = "VoteTotal"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return ec.introspectSchema(), nil
+		return obj.Count, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(*introspection.Schema)
-	if res == nil {
-		return graphql.Null
-	}
-	return ec.___Schema(ctx, field.Selections, res)
+	res := resTmp.(int)
+	return graphql.MarshalInt(res)
 }
 
-var statusImplementors = []string{"Status"}
+var webhookImplementors = []string{"Webhook"}
 
 // nolint: gocyclo, errcheck, gas, goconst
-func (ec *executionContext) _Status(ctx context.Context, sel ast.SelectionSet, obj *model.Status) graphql.Marshaler {
-	fields := graphql.CollectFields(ctx, sel, statusImplementors)
+func (ec *executionContext) _Webhook(ctx context.Context, sel ast.SelectionSet, obj *model.Webhook) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, webhookImplementors)
 
 	out := graphql.NewOrderedMap(len(fields))
 	for i, field := range fields {
@@ -1208,15 +3485,21 @@ func (ec *executionContext) _Status(ctx context.Context, sel ast.SelectionSet, o
 
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("Status")
+			out.Values[i] = graphql.MarshalString("Webhook")
 		case "id":
-			out.Values[i] = ec._Status_id(ctx, field, obj)
+			out.Values[i] = ec._Webhook_id(ctx, field, obj)
 		case "created":
-			out.Values[i] = ec._Status_created(ctx, field, obj)
-		case "cardId":
-			out.Values[i] = ec._Status_cardId(ctx, field, obj)
-		case "type":
-			out.Values[i] = ec._Status_type(ctx, field, obj)
+			out.Values[i] = ec._Webhook_created(ctx, field, obj)
+		case "retrospectiveId":
+			out.Values[i] = ec._Webhook_retrospectiveId(ctx, field, obj)
+		case "team":
+			out.Values[i] = ec._Webhook_team(ctx, field, obj)
+		case "url":
+			out.Values[i] = ec._Webhook_url(ctx, field, obj)
+		case "events":
+			out.Values[i] = ec._Webhook_events(ctx, field, obj)
+		case "deliveries":
+			out.Values[i] = ec._Webhook_deliveries(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -1225,9 +3508,9 @@ func (ec *executionContext) _Status(ctx context.Context, sel ast.SelectionSet, o
 	return out
 }
 
-func (ec *executionContext) _Status_id(ctx context.Context, field graphql.CollectedField, obj *model.Status) graphql.Marshaler {
+func (ec *executionContext) _Webhook_id(ctx context.Context, field graphql.CollectedField, obj *model.Webhook) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Status"
+	rctx.Object = "Webhook"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
@@ -1242,9 +3525,9 @@ func (ec *executionContext) _Status_id(ctx context.Context, field graphql.Collec
 	return graphql.MarshalID(res)
 }
 
-func (ec *executionContext) _Status_created(ctx context.Context, field graphql.CollectedField, obj *model.Status) graphql.Marshaler {
+func (ec *executionContext) _Webhook_created(ctx context.Context, field graphql.CollectedField, obj *model.Webhook) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Status"
+	rctx.Object = "Webhook"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
@@ -1259,128 +3542,152 @@ func (ec *executionContext) _Status_created(ctx context.Context, field graphql.C
 	return graphql.MarshalTime(res)
 }
 
-func (ec *executionContext) _Status_cardId(ctx context.Context, field graphql.CollectedField, obj *model.Status) graphql.Marshaler {
+func (ec *executionContext) _Webhook_retrospectiveId(ctx context.Context, field graphql.CollectedField, obj *model.Webhook) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Status"
+	rctx.Object = "Webhook"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return obj.CardId, nil
+		return obj.RetrospectiveId, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(string)
-	return graphql.MarshalString(res)
+	res := resTmp.(*string)
+	if res == nil {
+		return graphql.Null
+	}
+	return graphql.MarshalID(*res)
 }
 
-func (ec *executionContext) _Status_type(ctx context.Context, field graphql.CollectedField, obj *model.Status) graphql.Marshaler {
+func (ec *executionContext) _Webhook_team(ctx context.Context, field graphql.CollectedField, obj *model.Webhook) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Status"
+	rctx.Object = "Webhook"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return obj.Type, nil
+		return obj.Team, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(model.StatusType)
-	return res
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
 }
 
-var subscriptionImplementors = []string{"Subscription"}
-
-// nolint: gocyclo, errcheck, gas, goconst
-func (ec *executionContext) _Subscription(ctx context.Context, sel ast.SelectionSet) func() graphql.Marshaler {
-	fields := graphql.CollectFields(ctx, sel, subscriptionImplementors)
-	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
-		Object: "Subscription",
+func (ec *executionContext) _Webhook_url(ctx context.Context, field graphql.CollectedField, obj *model.Webhook) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "Webhook"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Url, nil
 	})
-	if len(fields) != 1 {
-		ec.Errorf(ctx, "must subscribe to exactly one stream")
-		return nil
-	}
-
-	switch fields[0].Name {
-	case "cardChanged":
-		return ec._Subscription_cardChanged(ctx, fields[0])
-	case "retroChanged":
-		return ec._Subscription_retroChanged(ctx, fields[0])
-	default:
-		panic("unknown field " + strconv.Quote(fields[0].Name))
+	if resTmp == nil {
+		return graphql.Null
 	}
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
 }
 
-func (ec *executionContext) _Subscription_cardChanged(ctx context.Context, field graphql.CollectedField) func() graphql.Marshaler {
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["rId"]; ok {
-		var err error
-		arg0, err = graphql.UnmarshalString(tmp)
-		if err != nil {
-			ec.Error(ctx, err)
-			return nil
-		}
-	}
-	args["rId"] = arg0
-	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{Field: field})
-	results, err := ec.resolvers.Subscription().CardChanged(ctx, args["rId"].(string))
-	if err != nil {
-		ec.Error(ctx, err)
-		return nil
-	}
-	return func() graphql.Marshaler {
-		res, ok := <-results
-		if !ok {
-			return nil
+func (ec *executionContext) _Webhook_events(ctx context.Context, field graphql.CollectedField, obj *model.Webhook) graphql.Marshaler {
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "Webhook",
+		Args:   nil,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.Webhook().Events(ctx, obj)
+		})
+		if resTmp == nil {
+			return graphql.Null
 		}
-		var out graphql.OrderedMap
-		out.Add(field.Alias, func() graphql.Marshaler { return ec._Card(ctx, field.Selections, &res) }())
-		return &out
-	}
+		res := resTmp.([]string)
+		arr1 := graphql.Array{}
+		for idx1 := range res {
+			arr1 = append(arr1, func() graphql.Marshaler {
+				rctx := graphql.GetResolverContext(ctx)
+				rctx.PushIndex(idx1)
+				defer rctx.Pop()
+				return graphql.MarshalString(res[idx1])
+			}())
+		}
+		return arr1
+	})
 }
 
-func (ec *executionContext) _Subscription_retroChanged(ctx context.Context, field graphql.CollectedField) func() graphql.Marshaler {
+func (ec *executionContext) _Webhook_deliveries(ctx context.Context, field graphql.CollectedField, obj *model.Webhook) graphql.Marshaler {
 	rawArgs := field.ArgumentMap(ec.Variables)
 	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["rId"]; ok {
+	var arg0 *int
+	if tmp, ok := rawArgs["limit"]; ok {
 		var err error
-		arg0, err = graphql.UnmarshalString(tmp)
+		var ptr1 int
+		if tmp != nil {
+			ptr1, err = graphql.UnmarshalInt(tmp)
+			arg0 = &ptr1
+		}
+
 		if err != nil {
 			ec.Error(ctx, err)
-			return nil
+			return graphql.Null
 		}
 	}
-	args["rId"] = arg0
-	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{Field: field})
-	results, err := ec.resolvers.Subscription().RetroChanged(ctx, args["rId"].(string))
-	if err != nil {
-		ec.Error(ctx, err)
-		return nil
-	}
-	return func() graphql.Marshaler {
-		res, ok := <-results
-		if !ok {
-			return nil
+	args["limit"] = arg0
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "Webhook",
+		Args:   args,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.Webhook().Deliveries(ctx, obj, args["limit"].(*int))
+		})
+		if resTmp == nil {
+			return graphql.Null
 		}
-		var out graphql.OrderedMap
-		out.Add(field.Alias, func() graphql.Marshaler { return ec._Retrospective(ctx, field.Selections, &res) }())
-		return &out
-	}
+		res := resTmp.([]model.WebhookDelivery)
+		arr1 := graphql.Array{}
+		for idx1 := range res {
+			arr1 = append(arr1, func() graphql.Marshaler {
+				rctx := graphql.GetResolverContext(ctx)
+				rctx.PushIndex(idx1)
+				defer rctx.Pop()
+				return ec._WebhookDelivery(ctx, field.Selections, &res[idx1])
+			}())
+		}
+		return arr1
+	})
 }
 
-var userStateImplementors = []string{"UserState"}
+var webhookAttemptImplementors = []string{"WebhookAttempt"}
 
 // nolint: gocyclo, errcheck, gas, goconst
-func (ec *executionContext) _UserState(ctx context.Context, sel ast.SelectionSet, obj *model.UserState) graphql.Marshaler {
-	fields := graphql.CollectFields(ctx, sel, userStateImplementors)
+func (ec *executionContext) _WebhookAttempt(ctx context.Context, sel ast.SelectionSet, obj *model.WebhookAttempt) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, webhookAttemptImplementors)
 
 	out := graphql.NewOrderedMap(len(fields))
 	for i, field := range fields {
@@ -1388,11 +3695,17 @@ func (ec *executionContext) _UserState(ctx context.Context, sel ast.SelectionSet
 
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("UserState")
-		case "user":
-			out.Values[i] = ec._UserState_user(ctx, field, obj)
-		case "state":
-			out.Values[i] = ec._UserState_state(ctx, field, obj)
+			out.Values[i] = graphql.MarshalString("WebhookAttempt")
+		case "id":
+			out.Values[i] = ec._WebhookAttempt_id(ctx, field, obj)
+		case "created":
+			out.Values[i] = ec._WebhookAttempt_created(ctx, field, obj)
+		case "statusCode":
+			out.Values[i] = ec._WebhookAttempt_statusCode(ctx, field, obj)
+		case "error":
+			out.Values[i] = ec._WebhookAttempt_error(ctx, field, obj)
+		case "duration":
+			out.Values[i] = ec._WebhookAttempt_duration(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -1401,15 +3714,66 @@ func (ec *executionContext) _UserState(ctx context.Context, sel ast.SelectionSet
 	return out
 }
 
-func (ec *executionContext) _UserState_user(ctx context.Context, field graphql.CollectedField, obj *model.UserState) graphql.Marshaler {
+func (ec *executionContext) _WebhookAttempt_id(ctx context.Context, field graphql.CollectedField, obj *model.WebhookAttempt) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "UserState"
+	rctx.Object = "WebhookAttempt"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return obj.User, nil
+		return obj.Id, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	return graphql.MarshalID(res)
+}
+
+func (ec *executionContext) _WebhookAttempt_created(ctx context.Context, field graphql.CollectedField, obj *model.WebhookAttempt) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "WebhookAttempt"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Created, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	return graphql.MarshalTime(res)
+}
+
+func (ec *executionContext) _WebhookAttempt_statusCode(ctx context.Context, field graphql.CollectedField, obj *model.WebhookAttempt) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "WebhookAttempt"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.StatusCode, nil
+	})
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	return graphql.MarshalInt(res)
+}
+
+func (ec *executionContext) _WebhookAttempt_error(ctx context.Context, field graphql.CollectedField, obj *model.WebhookAttempt) graphql.Marshaler {
+	rctx := graphql.GetResolverContext(ctx)
+	rctx.Object = "WebhookAttempt"
+	rctx.Args = nil
+	rctx.Field = field
+	rctx.PushField(field.Alias)
+	defer rctx.Pop()
+	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+		return obj.Error, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
@@ -1418,28 +3782,28 @@ func (ec *executionContext) _UserState_user(ctx context.Context, field graphql.C
 	return graphql.MarshalString(res)
 }
 
-func (ec *executionContext) _UserState_state(ctx context.Context, field graphql.CollectedField, obj *model.UserState) graphql.Marshaler {
+func (ec *executionContext) _WebhookAttempt_duration(ctx context.Context, field graphql.CollectedField, obj *model.WebhookAttempt) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "UserState"
+	rctx.Object = "WebhookAttempt"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return obj.State, nil
+		return obj.Duration, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(model.UserStateType)
-	return res
+	res := resTmp.(int)
+	return graphql.MarshalInt(res)
 }
 
-var voteImplementors = []string{"Vote"}
+var webhookDeliveryImplementors = []string{"WebhookDelivery"}
 
 // nolint: gocyclo, errcheck, gas, goconst
-func (ec *executionContext) _Vote(ctx context.Context, sel ast.SelectionSet, obj *model.Vote) graphql.Marshaler {
-	fields := graphql.CollectFields(ctx, sel, voteImplementors)
+func (ec *executionContext) _WebhookDelivery(ctx context.Context, sel ast.SelectionSet, obj *model.WebhookDelivery) graphql.Marshaler {
+	fields := graphql.CollectFields(ctx, sel, webhookDeliveryImplementors)
 
 	out := graphql.NewOrderedMap(len(fields))
 	for i, field := range fields {
@@ -1447,21 +3811,23 @@ func (ec *executionContext) _Vote(ctx context.Context, sel ast.SelectionSet, obj
 
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("Vote")
+			out.Values[i] = graphql.MarshalString("WebhookDelivery")
 		case "id":
-			out.Values[i] = ec._Vote_id(ctx, field, obj)
+			out.Values[i] = ec._WebhookDelivery_id(ctx, field, obj)
 		case "created":
-			out.Values[i] = ec._Vote_created(ctx, field, obj)
-		case "updated":
-			out.Values[i] = ec._Vote_updated(ctx, field, obj)
-		case "cardId":
-			out.Values[i] = ec._Vote_cardId(ctx, field, obj)
-		case "voter":
-			out.Values[i] = ec._Vote_voter(ctx, field, obj)
-		case "emoji":
-			out.Values[i] = ec._Vote_emoji(ctx, field, obj)
-		case "count":
-			out.Values[i] = ec._Vote_count(ctx, field, obj)
+			out.Values[i] = ec._WebhookDelivery_created(ctx, field, obj)
+		case "event":
+			out.Values[i] = ec._WebhookDelivery_event(ctx, field, obj)
+		case "payload":
+			out.Values[i] = ec._WebhookDelivery_payload(ctx, field, obj)
+		case "state":
+			out.Values[i] = ec._WebhookDelivery_state(ctx, field, obj)
+		case "attempts":
+			out.Values[i] = ec._WebhookDelivery_attempts(ctx, field, obj)
+		case "nextAttempt":
+			out.Values[i] = ec._WebhookDelivery_nextAttempt(ctx, field, obj)
+		case "attemptLog":
+			out.Values[i] = ec._WebhookDelivery_attemptLog(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -1470,9 +3836,9 @@ func (ec *executionContext) _Vote(ctx context.Context, sel ast.SelectionSet, obj
 	return out
 }
 
-func (ec *executionContext) _Vote_id(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
+func (ec *executionContext) _WebhookDelivery_id(ctx context.Context, field graphql.CollectedField, obj *model.WebhookDelivery) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Vote"
+	rctx.Object = "WebhookDelivery"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
@@ -1487,9 +3853,9 @@ func (ec *executionContext) _Vote_id(ctx context.Context, field graphql.Collecte
 	return graphql.MarshalID(res)
 }
 
-func (ec *executionContext) _Vote_created(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
+func (ec *executionContext) _WebhookDelivery_created(ctx context.Context, field graphql.CollectedField, obj *model.WebhookDelivery) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Vote"
+	rctx.Object = "WebhookDelivery"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
@@ -1504,32 +3870,32 @@ func (ec *executionContext) _Vote_created(ctx context.Context, field graphql.Col
 	return graphql.MarshalTime(res)
 }
 
-func (ec *executionContext) _Vote_updated(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
+func (ec *executionContext) _WebhookDelivery_event(ctx context.Context, field graphql.CollectedField, obj *model.WebhookDelivery) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Vote"
+	rctx.Object = "WebhookDelivery"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return obj.Updated, nil
+		return obj.Event, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(time.Time)
-	return graphql.MarshalTime(res)
+	res := resTmp.(string)
+	return graphql.MarshalString(res)
 }
 
-func (ec *executionContext) _Vote_cardId(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
+func (ec *executionContext) _WebhookDelivery_payload(ctx context.Context, field graphql.CollectedField, obj *model.WebhookDelivery) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Vote"
+	rctx.Object = "WebhookDelivery"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return obj.CardId, nil
+		return obj.Payload, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
@@ -1538,55 +3904,90 @@ func (ec *executionContext) _Vote_cardId(ctx context.Context, field graphql.Coll
 	return graphql.MarshalString(res)
 }
 
-func (ec *executionContext) _Vote_voter(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
+func (ec *executionContext) _WebhookDelivery_state(ctx context.Context, field graphql.CollectedField, obj *model.WebhookDelivery) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Vote"
+	rctx.Object = "WebhookDelivery"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return obj.Voter, nil
+		return obj.State, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(string)
-	return graphql.MarshalString(res)
+	res := resTmp.(model.DeliveryStateType)
+	return res
 }
 
-func (ec *executionContext) _Vote_emoji(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
+func (ec *executionContext) _WebhookDelivery_attempts(ctx context.Context, field graphql.CollectedField, obj *model.WebhookDelivery) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Vote"
+	rctx.Object = "WebhookDelivery"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return obj.Emoji, nil
+		return obj.Attempts, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(string)
-	return graphql.MarshalString(res)
+	res := resTmp.(int)
+	return graphql.MarshalInt(res)
 }
 
-func (ec *executionContext) _Vote_count(ctx context.Context, field graphql.CollectedField, obj *model.Vote) graphql.Marshaler {
+func (ec *executionContext) _WebhookDelivery_nextAttempt(ctx context.Context, field graphql.CollectedField, obj *model.WebhookDelivery) graphql.Marshaler {
 	rctx := graphql.GetResolverContext(ctx)
-	rctx.Object = "Vote"
+	rctx.Object = "WebhookDelivery"
 	rctx.Args = nil
 	rctx.Field = field
 	rctx.PushField(field.Alias)
 	defer rctx.Pop()
 	resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
-		return obj.Count, nil
+		return obj.NextAttempt, nil
 	})
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(int)
-	return graphql.MarshalInt(res)
+	res := resTmp.(time.Time)
+	return graphql.MarshalTime(res)
+}
+
+func (ec *executionContext) _WebhookDelivery_attemptLog(ctx context.Context, field graphql.CollectedField, obj *model.WebhookDelivery) graphql.Marshaler {
+	ctx = graphql.WithResolverContext(ctx, &graphql.ResolverContext{
+		Object: "WebhookDelivery",
+		Args:   nil,
+		Field:  field,
+	})
+	return graphql.Defer(func() (ret graphql.Marshaler) {
+		defer func() {
+			if r := recover(); r != nil {
+				userErr := ec.Recover(ctx, r)
+				ec.Error(ctx, userErr)
+				ret = graphql.Null
+			}
+		}()
+
+		resTmp := ec.FieldMiddleware(ctx, func(ctx context.Context) (interface{}, error) {
+			return ec.resolvers.WebhookDelivery().AttemptLog(ctx, obj)
+		})
+		if resTmp == nil {
+			return graphql.Null
+		}
+		res := resTmp.([]model.WebhookAttempt)
+		arr1 := graphql.Array{}
+		for idx1 := range res {
+			arr1 = append(arr1, func() graphql.Marshaler {
+				rctx := graphql.GetResolverContext(ctx)
+				rctx.PushIndex(idx1)
+				defer rctx.Pop()
+				return ec._WebhookAttempt(ctx, field.Selections, &res[idx1])
+			}())
+		}
+		return arr1
+	})
 }
 
 var __DirectiveImplementors = []string{"__Directive"}
@@ -2487,18 +4888,37 @@ var parsedSchema = gqlparser.MustLoadSchema(
 type RootQuery {
     retrospectiveById(id: ID!): Retrospective
     retrospectiveByPetName(petName: String!): Retrospective
+    webhooks(rId: ID, team: String): [Webhook!]!
+    team(name: String!): Team
+    archives(team: String!): [Archive!]!
+    exportProviders: [String!]!
 }
 
 type RootMutation {
-    startRetrospective(name: String): String!
+    startRetrospective(name: String, team: String): String!
+    closeRetrospective(id: ID!): Retrospective!
     addCardToRetrospective(id: ID!, column: String, message: String): String!
-    moveCard(id: ID!, column: String!, index: Int!): Int!
+    moveCard(id: ID!, column: String!, index: Int!, version: Int!): Int!
     mergeCard(id: ID!, mergedInto: ID!): ID!
     unmergeCard(id: ID!): ID!
-    updateMessage(id: ID!, message: String!): String!
+    unmergeAll(id: ID!): ID!
+    moveMergedCard(id: ID!, index: Int!, version: Int!): Int!
+    setMergeTitle(id: ID!, title: String!, version: Int!): String
+    createCardGroup(rId: ID!, column: String!, name: String!): CardGroup!
+    renameCardGroup(id: ID!, name: String!): CardGroup!
+    moveCardGroup(id: ID!, index: Int!): Int!
+    dissolveCardGroup(id: ID!): ID!
+    moveCardToGroup(id: ID!, groupId: ID!, index: Int!, version: Int!): Int!
+    updateMessage(id: ID!, message: String!, version: Int!): String!
     newVote(cardId: ID!, emoji: String!): Vote!
     updateStatus(id: ID!, status: StatusType!): Status!
     sendHeartbeat(rId: ID!, state: String!): String!
+    createWebhook(rId: ID, team: String, url: String!, secret: String!, events: [String!]): Webhook!
+    deleteWebhook(id: ID!): ID!
+    configureTeam(name: String!, chatWebhookUrl: String, retentionDays: Int): Team!
+    postSummary(rId: ID!, limit: Int): String!
+    exportCard(id: ID!, provider: String!): Card!
+    exportActionItems(rId: ID!, provider: String!): [Card!]!
 }
 
 type Subscription {
@@ -2512,6 +4932,12 @@ enum StatusType {
     Archived
 }
 
+enum DeliveryStateType {
+    Pending
+    Delivered
+    Failed
+}
+
 enum UserStateType {
     Unknown
     Hidden
@@ -2529,8 +4955,11 @@ type Retrospective {
     updated: Time
     name: String
     petName: String
+    team: String
+    closed: Time
 
     cards: [Card]
+    groups: [CardGroup!]!
 
     onlineUsers: [UserState!]
 }
@@ -2544,11 +4973,43 @@ type Card {
     column: String
     mergedInto: ID
     mergedCards: [Card]
+    mergeTitle: String
+    mergeHistory: [Merge!]!
+    groupId: ID
 
     statuses: [Status]
     votes: [Vote]
 
     position: Int
+    issueUrl: String
+    version: Int!
+}
+
+type CardGroup {
+    id: ID!
+    created: Time
+    updated: Time
+    column: String!
+    name: String!
+    position: Int!
+
+    cards: [Card!]!
+    votes: [VoteTotal!]!
+    totalVotes: Int!
+}
+
+type VoteTotal {
+    emoji: String!
+    count: Int!
+}
+
+type Merge {
+    id: ID!
+    created: Time
+    mergedInto: ID!
+    column: String
+    position: Int
+    unmerged: Time
 }
 
 type Vote {
@@ -2568,6 +5029,54 @@ type Status {
     type: StatusType
 }
 
+type Team {
+    name: String!
+    created: Time
+    updated: Time
+    chatWebhookConfigured: Boolean!
+    retentionDays: Int
+}
+
+type Archive {
+    id: ID!
+    created: Time
+    name: String!
+    petName: String!
+    team: String!
+    closed: Time
+}
+
+type Webhook {
+    id: ID!
+    created: Time
+    retrospectiveId: ID
+    team: String
+    url: String!
+    events: [String!]!
+
+    deliveries(limit: Int): [WebhookDelivery!]!
+}
+
+type WebhookDelivery {
+    id: ID!
+    created: Time
+    event: String!
+    payload: String!
+    state: DeliveryStateType!
+    attempts: Int!
+    nextAttempt: Time
+
+    attemptLog: [WebhookAttempt!]!
+}
+
+type WebhookAttempt {
+    id: ID!
+    created: Time
+    statusCode: Int
+    error: String
+    duration: Int
+}
+
 scalar Time
 `},
 )
@@ -2,41 +2,70 @@ package graph
 
 import (
 	"context"
-	"fmt"
-	"sync"
+	"strings"
 
+	"github.com/sirupsen/logrus"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/logging"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/metrics"
 	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/tracing"
 )
 
 type rocketboardService interface {
-	StartRetrospective(string) (string, error)
-	GetRetrospectiveById(string) (*model.Retrospective, error)
-	GetRetrospectiveByPetName(string) (*model.Retrospective, error)
-	AddCardToRetrospective(string, string, string, string) (string, error)
-	MoveCard(string, string, int) error
-	MergeCard(string, string) error
-	UnmergeCard(string) error
-	UpdateMessage(string, string) error
-	GetCardsForRetrospective(string) ([]*model.Card, error)
-	GetCardById(string) (*model.Card, error)
-	GetVotesByCardId(string) ([]*model.Vote, error)
-	GetVoteByCardIdAndVoterAndEmoji(string, string, string) (*model.Vote, error)
-	NewVote(string, string, string) (*model.Vote, error)
-	GetCardStatuses(string) ([]*model.Status, error)
-	SetStatus(string, model.StatusType) (string, error)
-	GetStatusById(string) (*model.Status, error)
+	StartRetrospective(context.Context, string, string) (string, error)
+	CloseRetrospective(context.Context, string) (*model.Retrospective, error)
+	GetRetrospectiveById(context.Context, string) (*model.Retrospective, error)
+	GetRetrospectiveByPetName(context.Context, string) (*model.Retrospective, error)
+	AddCardToRetrospective(context.Context, string, string, string, string) (string, error)
+	MoveCard(context.Context, string, string, int, int) error
+	MergeCard(context.Context, string, string) error
+	UnmergeCard(context.Context, string) error
+	UnmergeAll(context.Context, string) error
+	MoveMergedCard(context.Context, string, int, int) error
+	SetMergeTitle(context.Context, string, string, int) error
+	GetMergeHistory(context.Context, string) ([]*model.Merge, error)
+	CreateCardGroup(context.Context, string, string, string) (*model.CardGroup, error)
+	RenameCardGroup(context.Context, string, string) (*model.CardGroup, error)
+	MoveCardGroup(context.Context, string, int) (*model.CardGroup, error)
+	DissolveCardGroup(context.Context, string) (*model.CardGroup, error)
+	MoveCardToGroup(context.Context, string, string, int, int) error
+	GetCardGroups(context.Context, string) ([]*model.CardGroup, error)
+	GetCardGroupCards(context.Context, *model.CardGroup) ([]*model.Card, error)
+	GetCardGroupVotes(context.Context, *model.CardGroup) ([]*model.VoteTotal, error)
+	UpdateMessage(context.Context, string, string, int) error
+	GetCardsForRetrospective(context.Context, string) ([]*model.Card, error)
+	GetCardById(context.Context, string) (*model.Card, error)
+	GetVotesByCardId(context.Context, string) ([]*model.Vote, error)
+	GetVoteByCardIdAndVoterAndEmoji(context.Context, string, string, string) (*model.Vote, error)
+	NewVote(context.Context, string, string, string) (*model.Vote, error)
+	GetCardStatuses(context.Context, string) ([]*model.Status, error)
+	SetStatus(context.Context, string, model.StatusType) (string, error)
+	GetStatusById(context.Context, string) (*model.Status, error)
+	CreateWebhook(context.Context, *string, string, string, string, []string) (*model.Webhook, error)
+	DeleteWebhook(context.Context, string) error
+	GetWebhooks(context.Context, *string, string) ([]*model.Webhook, error)
+	GetWebhookDeliveries(context.Context, string, int) ([]*model.WebhookDelivery, error)
+	GetWebhookAttempts(context.Context, string) ([]*model.WebhookAttempt, error)
+	GetTeam(context.Context, string) (*model.Team, error)
+	ConfigureTeam(context.Context, string, string, *int) (*model.Team, error)
+	GetArchives(context.Context, string) ([]*model.Archive, error)
+	PostSummary(context.Context, string, int) (string, error)
+	GetExportProviders(context.Context) []string
+	ExportCard(context.Context, string, string) (*model.Card, error)
+	ExportActionItems(context.Context, string, string) ([]*model.Card, error)
 }
 
 type observationStore interface {
-	Observe(string, string, string, string) (bool, error)
-	GetActiveUsers(string) ([]model.UserState, error)
-	ClearObservations(string)
+	Observe(context.Context, string, string, string, string) (bool, error)
+	GetActiveUsers(context.Context, string) ([]model.UserState, error)
+	ClearObservations(context.Context, string)
 }
 
 type rootResolver struct {
-	s  rocketboardService
-	o  observationStore
-	mu sync.Mutex
+	s      rocketboardService
+	o      observationStore
+	logger logrus.FieldLogger
 }
 
 type cardResolver struct {
@@ -47,6 +76,22 @@ type retrospectiveResolver struct {
 	*rootResolver
 }
 
+type cardGroupResolver struct {
+	*rootResolver
+}
+
+type teamResolver struct {
+	*rootResolver
+}
+
+type webhookResolver struct {
+	*rootResolver
+}
+
+type webhookDeliveryResolver struct {
+	*rootResolver
+}
+
 type subscriptionResolver struct {
 	*rootResolver
 }
@@ -59,8 +104,8 @@ type queryResolver struct {
 	*rootResolver
 }
 
-func NewResolver(s rocketboardService, o observationStore) ResolverRoot {
-	return &rootResolver{s, o, sync.Mutex{}}
+func NewResolver(s rocketboardService, o observationStore, logger logrus.FieldLogger) ResolverRoot {
+	return &rootResolver{s: s, o: o, logger: logger}
 }
 
 func (r *rootResolver) Card() CardResolver {
@@ -71,6 +116,22 @@ func (r *rootResolver) Retrospective() RetrospectiveResolver {
 	return &retrospectiveResolver{r}
 }
 
+func (r *rootResolver) CardGroup() CardGroupResolver {
+	return &cardGroupResolver{r}
+}
+
+func (r *rootResolver) Team() TeamResolver {
+	return &teamResolver{r}
+}
+
+func (r *rootResolver) Webhook() WebhookResolver {
+	return &webhookResolver{r}
+}
+
+func (r *rootResolver) WebhookDelivery() WebhookDeliveryResolver {
+	return &webhookDeliveryResolver{r}
+}
+
 func (r *rootResolver) Subscription() SubscriptionResolver {
 	return &subscriptionResolver{r}
 }
@@ -84,137 +145,400 @@ func (r *rootResolver) RootQuery() RootQueryResolver {
 }
 
 func (r *retrospectiveResolver) Cards(ctx context.Context, obj *model.Retrospective) ([]*model.Card, error) {
-	cards, _ := r.s.GetCardsForRetrospective(obj.Id)
-	return cards, nil
+	return r.s.GetCardsForRetrospective(ctx, obj.Id)
 }
+func (r *retrospectiveResolver) Groups(ctx context.Context, obj *model.Retrospective) ([]model.CardGroup, error) {
+	gs, err := r.s.GetCardGroups(ctx, obj.Id)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.CardGroup, len(gs))
+	for i, g := range gs {
+		res[i] = *g
+	}
+	return res, nil
+}
+
 func (r *retrospectiveResolver) OnlineUsers(ctx context.Context, obj *model.Retrospective) ([]model.UserState, error) {
-	return r.o.GetActiveUsers(obj.Id)
+	return r.o.GetActiveUsers(ctx, obj.Id)
 }
 
 func (r *cardResolver) Statuses(ctx context.Context, obj *model.Card) ([]*model.Status, error) {
-	return r.s.GetCardStatuses(obj.Id)
+	return r.s.GetCardStatuses(ctx, obj.Id)
 }
 
 func (r *cardResolver) Votes(ctx context.Context, obj *model.Card) ([]*model.Vote, error) {
-	return r.s.GetVotesByCardId(obj.Id)
+	return r.s.GetVotesByCardId(ctx, obj.Id)
+}
+
+func (r *cardResolver) MergeHistory(ctx context.Context, obj *model.Card) ([]model.Merge, error) {
+	ms, err := r.s.GetMergeHistory(ctx, obj.Id)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.Merge, len(ms))
+	for i, m := range ms {
+		res[i] = *m
+	}
+	return res, nil
+}
+
+func (r *cardGroupResolver) Cards(ctx context.Context, obj *model.CardGroup) ([]model.Card, error) {
+	cs, err := r.s.GetCardGroupCards(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.Card, len(cs))
+	for i, c := range cs {
+		res[i] = *c
+	}
+	return res, nil
+}
+
+func (r *cardGroupResolver) Votes(ctx context.Context, obj *model.CardGroup) ([]model.VoteTotal, error) {
+	totals, err := r.s.GetCardGroupVotes(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.VoteTotal, len(totals))
+	for i, t := range totals {
+		res[i] = *t
+	}
+	return res, nil
+}
+
+func (r *cardGroupResolver) TotalVotes(ctx context.Context, obj *model.CardGroup) (int, error) {
+	totals, err := r.s.GetCardGroupVotes(ctx, obj)
+	if err != nil {
+		return 0, err
+	}
+	sum := 0
+	for _, t := range totals {
+		sum += t.Count
+	}
+	return sum, nil
+}
+
+func (r *teamResolver) ChatWebhookConfigured(ctx context.Context, obj *model.Team) (bool, error) {
+	return obj.ChatWebhookUrl != "", nil
+}
+
+func (r *webhookResolver) Events(ctx context.Context, obj *model.Webhook) ([]string, error) {
+	if obj.Events == "" {
+		return []string{}, nil
+	}
+	return strings.Split(obj.Events, ","), nil
+}
+
+func (r *webhookResolver) Deliveries(ctx context.Context, obj *model.Webhook, limit *int) ([]model.WebhookDelivery, error) {
+	n := 20
+	if limit != nil && *limit > 0 {
+		n = *limit
+	}
+	if n > 100 {
+		n = 100
+	}
+	ds, err := r.s.GetWebhookDeliveries(ctx, obj.Id, n)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.WebhookDelivery, len(ds))
+	for i, d := range ds {
+		res[i] = *d
+	}
+	return res, nil
+}
+
+func (r *webhookDeliveryResolver) AttemptLog(ctx context.Context, obj *model.WebhookDelivery) ([]model.WebhookAttempt, error) {
+	as, err := r.s.GetWebhookAttempts(ctx, obj.Id)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.WebhookAttempt, len(as))
+	for i, a := range as {
+		res[i] = *a
+	}
+	return res, nil
 }
 
 func (r *queryResolver) RetrospectiveByID(ctx context.Context, id string) (*model.Retrospective, error) {
-	return r.s.GetRetrospectiveById(id)
+	return r.s.GetRetrospectiveById(ctx, id)
 }
 
 func (r *queryResolver) RetrospectiveByPetName(ctx context.Context, petName string) (*model.Retrospective, error) {
-	return r.s.GetRetrospectiveByPetName(petName)
+	return r.s.GetRetrospectiveByPetName(ctx, petName)
 }
 
-func (r *mutationResolver) StartRetrospective(ctx context.Context, name *string) (string, error) {
-	return r.s.StartRetrospective(*name)
+func (r *queryResolver) Webhooks(ctx context.Context, rId *string, team *string) ([]model.Webhook, error) {
+	if (rId == nil) == (team == nil) {
+		return nil, model.InputError("Either rId or team is required")
+	}
+	t := ""
+	if team != nil {
+		t = *team
+	}
+	ws, err := r.s.GetWebhooks(ctx, rId, t)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.Webhook, len(ws))
+	for i, w := range ws {
+		res[i] = *w
+	}
+	return res, nil
 }
 
-func (r *mutationResolver) MoveCard(ctx context.Context, id string, column string, index int) (int, error) {
-	if err := r.s.MoveCard(id, column, index); err != nil {
+func (r *queryResolver) Team(ctx context.Context, name string) (*model.Team, error) {
+	return r.s.GetTeam(ctx, name)
+}
+
+func (r *queryResolver) Archives(ctx context.Context, team string) ([]model.Archive, error) {
+	as, err := r.s.GetArchives(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+	archives := []model.Archive{}
+	for _, a := range as {
+		archives = append(archives, *a)
+	}
+	return archives, nil
+}
+
+func (r *queryResolver) ExportProviders(ctx context.Context) ([]string, error) {
+	return r.s.GetExportProviders(ctx), nil
+}
+
+func (r *mutationResolver) StartRetrospective(ctx context.Context, name *string, team *string) (string, error) {
+	if name == nil {
+		return "", model.InputError("Retrospectives need a name")
+	}
+	t := ""
+	if team != nil {
+		t = *team
+	}
+	return r.s.StartRetrospective(ctx, *name, t)
+}
+
+func (r *mutationResolver) CloseRetrospective(ctx context.Context, id string) (model.Retrospective, error) {
+	retro, err := r.s.CloseRetrospective(ctx, id)
+	if err != nil {
+		return model.Retrospective{}, err
+	}
+	r.sendRetroToSubs(ctx, retro)
+	return *retro, nil
+}
+
+func (r *mutationResolver) MoveCard(ctx context.Context, id string, column string, index int, version int) (int, error) {
+	if err := r.s.MoveCard(ctx, id, column, index, version); err != nil {
 		return -1, err
 	}
-	c, _ := r.s.GetCardById(id)
-	r.sendCardToSubs(c)
+	c, err := r.s.GetCardById(ctx, id)
+	if err != nil {
+		return -1, err
+	}
+	r.sendCardToSubs(ctx, c)
 	return c.Position, nil
 }
 
 func (r *mutationResolver) MergeCard(ctx context.Context, id string, mergedInto string) (string, error) {
-	if err := r.s.MergeCard(id, mergedInto); err != nil {
+	if err := r.s.MergeCard(ctx, id, mergedInto); err != nil {
 		return "", err
 	}
-	c, _ := r.s.GetCardById(mergedInto)
-	r.sendCardToSubs(c)
-	c2, _ := r.s.GetCardById(id)
-	r.sendCardToSubs(c2)
+	c, err := r.s.GetCardById(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	r.sendCardToSubs(ctx, c)
+	r.sendGroupToSubs(ctx, mergedInto)
 	return mergedInto, nil
 }
 
 func (r *mutationResolver) UnmergeCard(ctx context.Context, id string) (string, error) {
-	c, err := r.s.GetCardById(id)
+	c, err := r.s.GetCardById(ctx, id)
 	if err != nil {
 		return "", err
 	}
 	oldMergedInto := c.MergedInto
 	if oldMergedInto == nil {
-		return "", fmt.Errorf("Tried to unmerge an unmerged card")
+		return "", model.InputError("Card is not merged")
 	}
 
-	if err := r.s.UnmergeCard(id); err != nil {
-		panic(err)
+	if err := r.s.UnmergeCard(ctx, id); err != nil {
 		return "", err
 	}
 	c.MergedInto = nil
-	r.sendCardToSubs(c)
+	r.sendCardToSubs(ctx, c)
 
 	// Have to update the card that no longer has this merged in to it
-	parentCard, _ := r.s.GetCardById(*oldMergedInto)
-	r.sendCardToSubs(parentCard)
+	r.sendGroupToSubs(ctx, *oldMergedInto)
 
 	return *oldMergedInto, nil
 }
 
-func (r *mutationResolver) UpdateMessage(ctx context.Context, id string, message string) (string, error) {
-	if err := r.s.UpdateMessage(id, message); err != nil {
+func (r *mutationResolver) UnmergeAll(ctx context.Context, id string) (string, error) {
+	c, err := r.s.GetCardById(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if err := r.s.UnmergeAll(ctx, id); err != nil {
 		return "", err
 	}
-	c, _ := r.s.GetCardById(id)
-	r.sendCardToSubs(c)
+	for _, card := range c.Group()[1:] {
+		if unmerged, err := r.s.GetCardById(ctx, card.Id); err == nil {
+			r.sendCardToSubs(ctx, unmerged)
+		}
+	}
+	r.sendGroupToSubs(ctx, id)
+	return id, nil
+}
+
+func (r *mutationResolver) MoveMergedCard(ctx context.Context, id string, index int, version int) (int, error) {
+	if err := r.s.MoveMergedCard(ctx, id, index, version); err != nil {
+		return -1, err
+	}
+	c, err := r.s.GetCardById(ctx, id)
+	if err != nil {
+		return -1, err
+	}
+	r.sendGroupToSubs(ctx, id)
+	return c.Position, nil
+}
+
+func (r *mutationResolver) SetMergeTitle(ctx context.Context, id string, title string, version int) (*string, error) {
+	if err := r.s.SetMergeTitle(ctx, id, title, version); err != nil {
+		return nil, err
+	}
+	c, err := r.s.GetCardById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.sendGroupToSubs(ctx, id)
+	return c.MergeTitle, nil
+}
+
+func (r *mutationResolver) CreateCardGroup(ctx context.Context, rId string, column string, name string) (model.CardGroup, error) {
+	g, err := r.s.CreateCardGroup(ctx, rId, column, name)
+	if err != nil {
+		return model.CardGroup{}, err
+	}
+	r.sendRetroToSubsById(ctx, g.RetrospectiveId)
+	return *g, nil
+}
+
+func (r *mutationResolver) RenameCardGroup(ctx context.Context, id string, name string) (model.CardGroup, error) {
+	g, err := r.s.RenameCardGroup(ctx, id, name)
+	if err != nil {
+		return model.CardGroup{}, err
+	}
+	r.sendRetroToSubsById(ctx, g.RetrospectiveId)
+	return *g, nil
+}
+
+func (r *mutationResolver) MoveCardGroup(ctx context.Context, id string, index int) (int, error) {
+	g, err := r.s.MoveCardGroup(ctx, id, index)
+	if err != nil {
+		return -1, err
+	}
+	r.sendRetroToSubsById(ctx, g.RetrospectiveId)
+	return g.Position, nil
+}
+
+func (r *mutationResolver) DissolveCardGroup(ctx context.Context, id string) (string, error) {
+	g, err := r.s.DissolveCardGroup(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	cs, err := r.s.GetCardsForRetrospective(ctx, g.RetrospectiveId)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range cs {
+		if c.Column == g.Column {
+			r.sendCardToSubs(ctx, c)
+		}
+	}
+	r.sendRetroToSubsById(ctx, g.RetrospectiveId)
+	return id, nil
+}
+
+func (r *mutationResolver) MoveCardToGroup(ctx context.Context, id string, groupId string, index int, version int) (int, error) {
+	if err := r.s.MoveCardToGroup(ctx, id, groupId, index, version); err != nil {
+		return -1, err
+	}
+	c, err := r.s.GetCardById(ctx, id)
+	if err != nil {
+		return -1, err
+	}
+	r.sendCardToSubs(ctx, c)
+	r.sendRetroToSubsById(ctx, c.RetrospectiveId)
+	return c.Position, nil
+}
+
+func (r *mutationResolver) UpdateMessage(ctx context.Context, id string, message string, version int) (string, error) {
+	if err := r.s.UpdateMessage(ctx, id, message, version); err != nil {
+		return "", err
+	}
+	c, err := r.s.GetCardById(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	r.sendCardToSubs(ctx, c)
 	return message, nil
 }
 
 func (r *mutationResolver) NewVote(ctx context.Context, cardId string, emoji string) (model.Vote, error) {
 	voter := ctx.Value("email").(string)
-	r.mu.Lock()
-	limiter := userLimiters[voter]
-	r.mu.Unlock()
-
-	if limiter != nil && !limiter.Allow() {
+	v, err := r.s.NewVote(ctx, cardId, voter, emoji)
+	if model.Code(err) == model.CodeRateLimited {
 		// If rate limited, just return existing vote (without incrementing)
-		vote, err := r.s.GetVoteByCardIdAndVoterAndEmoji(cardId, voter, emoji)
-		return *vote, err
+		if vote, err := r.s.GetVoteByCardIdAndVoterAndEmoji(ctx, cardId, voter, emoji); err == nil {
+			return *vote, nil
+		}
+		return model.Vote{}, err
 	}
-
-	v, err := r.s.NewVote(cardId, voter, emoji)
 	if err == nil {
-		c, _ := r.s.GetCardById(cardId)
-		r.sendCardToSubs(c)
-		return *v, err
+		if c, err := r.s.GetCardById(ctx, cardId); err == nil {
+			r.sendCardToSubs(ctx, c)
+		}
+		return *v, nil
 	} else {
 		return model.Vote{}, err
 	}
 }
 
 func (r *mutationResolver) AddCardToRetrospective(ctx context.Context, rId string, column *string, message *string) (string, error) {
-	id, err := r.s.AddCardToRetrospective(rId, *column, *message, ctx.Value("email").(string))
+	if column == nil || message == nil {
+		return "", model.InputError("Cards need a column and a message")
+	}
+	id, err := r.s.AddCardToRetrospective(ctx, rId, *column, *message, ctx.Value("email").(string))
 	if err != nil {
 		return "", err
 	}
-	c, err := r.s.GetCardById(id)
+	c, err := r.s.GetCardById(ctx, id)
 	if err != nil {
-		panic(err)
 		return "", err
 	}
 
-	r.sendCardToSubs(c)
+	r.sendCardToSubs(ctx, c)
 	return id, nil
 }
 
 func (r *mutationResolver) UpdateStatus(ctx context.Context, id string, status model.StatusType) (model.Status, error) {
-	sid, err := r.s.SetStatus(id, status)
+	sid, err := r.s.SetStatus(ctx, id, status)
 	if err != nil {
 		return model.Status{}, err
 	}
 
-	s, err := r.s.GetStatusById(sid)
+	s, err := r.s.GetStatusById(ctx, sid)
 	if err != nil {
 		return model.Status{}, err
 	}
 
+	ctx = tracing.Detach(ctx)
 	go func() {
-		c, _ := r.s.GetCardById(id)
-		r.sendCardToSubs(c)
+		if c, err := r.s.GetCardById(ctx, id); err == nil {
+			r.sendCardToSubs(ctx, c)
+		}
 	}()
 
 	return *s, nil
@@ -224,8 +548,74 @@ func (r *mutationResolver) SendHeartbeat(ctx context.Context, rId string, state
 	user := ctx.Value("email").(string)
 	connectionId := ctx.Value("connectionId").(string)
 
-	if changed, _ := r.o.Observe(connectionId, user, rId, state); changed {
-		r.sendRetroToSubsById(rId)
+	metrics.Heartbeats.Inc()
+	changed, err := r.o.Observe(ctx, connectionId, user, rId, state)
+	if err != nil {
+		logging.Retro(ctx, r.logger, rId).WithError(err).Error("Failed to record heartbeat")
+	}
+	if changed {
+		r.sendRetroToSubsById(ctx, rId)
 	}
 	return "", nil
 }
+
+func (r *mutationResolver) CreateWebhook(ctx context.Context, rId *string, team *string, url string, secret string, events []string) (model.Webhook, error) {
+	t := ""
+	if team != nil {
+		t = *team
+	}
+	w, err := r.s.CreateWebhook(ctx, rId, t, url, secret, events)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	return *w, nil
+}
+
+func (r *mutationResolver) DeleteWebhook(ctx context.Context, id string) (string, error) {
+	if err := r.s.DeleteWebhook(ctx, id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *mutationResolver) ConfigureTeam(ctx context.Context, name string, chatWebhookUrl *string, retentionDays *int) (model.Team, error) {
+	u := ""
+	if chatWebhookUrl != nil {
+		u = *chatWebhookUrl
+	}
+	t, err := r.s.ConfigureTeam(ctx, name, u, retentionDays)
+	if err != nil {
+		return model.Team{}, err
+	}
+	return *t, nil
+}
+
+func (r *mutationResolver) PostSummary(ctx context.Context, rId string, limit *int) (string, error) {
+	n := 5
+	if limit != nil && *limit > 0 {
+		n = *limit
+	}
+	if n > 20 {
+		n = 20
+	}
+	return r.s.PostSummary(ctx, rId, n)
+}
+
+func (r *mutationResolver) ExportCard(ctx context.Context, id string, provider string) (model.Card, error) {
+	c, err := r.s.ExportCard(ctx, id, provider)
+	if err != nil {
+		return model.Card{}, err
+	}
+	r.sendCardToSubs(ctx, c)
+	return *c, nil
+}
+
+func (r *mutationResolver) ExportActionItems(ctx context.Context, rId string, provider string) ([]model.Card, error) {
+	cs, err := r.s.ExportActionItems(ctx, rId, provider)
+	res := make([]model.Card, len(cs))
+	for i, c := range cs {
+		r.sendCardToSubs(ctx, c)
+		res[i] = *c
+	}
+	return res, err
+}
@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+func TestMissingArguments(t *testing.T) {
+	r := NewResolver(nil, nil, logrus.StandardLogger()).RootMutation()
+	ctx := context.WithValue(context.Background(), "email", "alice@example.com")
+	message := "hello"
+
+	if _, err := r.StartRetrospective(ctx, nil, nil); model.Code(err) != model.CodeValidation {
+		t.Fatal("Expected a retrospective without a name to be invalid, got:", err)
+	}
+	if _, err := r.AddCardToRetrospective(ctx, "retro", nil, &message); model.Code(err) != model.CodeValidation {
+		t.Fatal("Expected a card without a column to be invalid, got:", err)
+	}
+	if _, err := r.AddCardToRetrospective(ctx, "retro", &message, nil); model.Code(err) != model.CodeValidation {
+		t.Fatal("Expected a card without a message to be invalid, got:", err)
+	}
+}
+
+// summaryService records the limit that summaries are posted with.
+type summaryService struct {
+	rocketboardService
+	limit int
+}
+
+func (s *summaryService) PostSummary(ctx context.Context, rId string, limit int) (string, error) {
+	s.limit = limit
+	return "", nil
+}
+
+func TestPostSummaryLimit(t *testing.T) {
+	s := &summaryService{}
+	r := NewResolver(s, nil, logrus.StandardLogger()).RootMutation()
+	ctx := context.Background()
+
+	for _, c := range []struct {
+		limit    *int
+		expected int
+	}{
+		{nil, 5},
+		{intPtr(0), 5},
+		{intPtr(10), 10},
+		{intPtr(20), 20},
+		{intPtr(50), 20},
+	} {
+		if _, err := r.PostSummary(ctx, "retro", c.limit); err != nil {
+			t.Fatal("Failed to post summary", err)
+		}
+		if s.limit != c.expected {
+			t.Error("Expected a limit of", c.expected, "got:", s.limit)
+		}
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
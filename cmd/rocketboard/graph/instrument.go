@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/ast"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/metrics"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/tracing"
+)
+
+// operationOf names the operation of a request for the metrics and traces.
+// The clients send one operation per request, documents with several are not
+// told apart.
+func operationOf(doc *ast.QueryDocument) (name string, typ ast.Operation) {
+	if doc == nil || len(doc.Operations) != 1 {
+		return "multiple", ""
+	}
+	op := doc.Operations[0]
+	if op.Name == "" {
+		return "anonymous", op.Operation
+	}
+	return op.Name, op.Operation
+}
+
+// RequestMiddleware traces and times queries and mutations by their
+// operation name. Subscriptions last as long as the board is open and are
+// counted by the subscribers gauge instead.
+func RequestMiddleware(ctx context.Context, next func(ctx context.Context) []byte) []byte {
+	name, typ := operationOf(graphql.GetRequestContext(ctx).Doc)
+	if typ == ast.Subscription {
+		return next(ctx)
+	}
+
+	ctx, span := tracing.Start(ctx, string(typ)+" "+name,
+		attribute.String("graphql.operation.name", name),
+		attribute.String("graphql.operation.type", string(typ)),
+	)
+	defer span.End()
+	defer func(start time.Time) {
+		metrics.GraphQLDuration.WithLabelValues(name, string(typ)).Observe(time.Since(start).Seconds())
+	}(time.Now())
+	return next(ctx)
+}
+
+// ResolverMiddleware traces and times the resolvers of root fields, and counts
+// the errors they return. The fields of the returned objects are cheap, or
+// resolve to a root field's service call anyway, whose span is a child of the
+// request's.
+func ResolverMiddleware(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	rctx := graphql.GetResolverContext(ctx)
+	if rctx.Object != "RootQuery" && rctx.Object != "RootMutation" {
+		return next(ctx)
+	}
+
+	ctx, span := tracing.Start(ctx, rctx.Object+"."+rctx.Field.Name)
+	start := time.Now()
+	res, err := next(ctx)
+	metrics.ResolverDuration.WithLabelValues(rctx.Object, rctx.Field.Name).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.ResolverErrors.WithLabelValues(rctx.Object, rctx.Field.Name).Inc()
+	}
+	tracing.End(span, err)
+	return res, err
+}
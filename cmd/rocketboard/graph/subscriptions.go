@@ -2,15 +2,18 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"github.com/nats-io/gnatsd/server"
 	"github.com/nats-io/go-nats"
+	"github.com/sirupsen/logrus"
 	"github.com/vmihailenco/msgpack"
-	"golang.org/x/time/rate"
-	"log"
-	"os"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
 	"time"
 
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/logging"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/metrics"
 	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/tracing"
 )
 
 type Update struct {
@@ -18,15 +21,12 @@ type Update struct {
 	Retrospective *model.Retrospective
 }
 
-type CountedLimiter struct {
-	*rate.Limiter
-	Count int
-}
-
 var nc *nats.Conn
 
+// The logger of the message queue, set by InitMessageQueue.
+var mqLogger logrus.FieldLogger = logrus.StandardLogger()
+
 var subChannels = make(map[string]map[string]chan model.Card)
-var userLimiters = make(map[string]*CountedLimiter)
 
 func startLocalNats() {
 	opts := server.Options{}
@@ -43,11 +43,15 @@ func startLocalNats() {
 	}
 }
 
-func InitMessageQueue() {
+// InitMessageQueue connects to the NATS server at natsAddr, starting an
+// embedded one if it is empty.
+func InitMessageQueue(natsAddr string, logger logrus.FieldLogger) {
+	mqLogger = logger
+
 	var err error
-	nats_addr := os.Getenv("NATS_ADDR")
+	nats_addr := natsAddr
 	if nats_addr == "" {
-		log.Println("No NATS_ADDR specified, starting local nats")
+		logger.Info("No NATS address specified, starting local nats")
 		go startLocalNats()
 		nats_addr = "nats://localhost:4222"
 	}
@@ -60,43 +64,99 @@ func InitMessageQueue() {
 		time.Sleep(100 * time.Millisecond)
 	}
 	if err != nil {
-		log.Fatal("could not connect to nats")
+		logger.WithError(err).Fatal("Could not connect to nats")
+	}
+}
+
+// CheckMessageQueue checks that the NATS server can be reached, returning
+// its URL.
+func CheckMessageQueue(ctx context.Context) (string, error) {
+	if nc == nil {
+		return "", errors.New("not connected")
+	}
+	timeout := time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
 	}
+	if err := nc.FlushTimeout(timeout); err != nil {
+		return "", err
+	}
+	return nc.ConnectedUrl(), nil
+}
+
+// publish sends an update of the given kind to the subscribers of a
+// retrospective. Subscribers miss updates that fail to publish until the next
+// one, so failures are only logged and counted.
+func publish(ctx context.Context, kind string, rId string, v interface{}) {
+	subject := kind + "-" + rId
+	_, span := tracing.Start(ctx, "nats publish "+kind,
+		semconv.MessagingSystemKey.String("nats"),
+		semconv.MessagingDestinationKey.String(subject),
+	)
+	b, err := msgpack.Marshal(v)
+	if err == nil {
+		err = nc.Publish(subject, b)
+	}
+	if err != nil {
+		logging.Retro(ctx, mqLogger, rId).WithError(err).WithField("kind", kind).Error("Failed to publish update")
+		metrics.NatsPublishFailures.WithLabelValues(kind).Inc()
+	}
+	tracing.End(span, err)
+}
+
+// PublishCard notifies the subscribers of a card's retrospective that the card
+// changed, for changes made outside of the GraphQL mutations.
+func PublishCard(ctx context.Context, c *model.Card) {
+	publish(ctx, "cards", c.RetrospectiveId, c)
+}
+
+func (r *rootResolver) sendCardToSubs(ctx context.Context, c *model.Card) {
+	PublishCard(ctx, c)
 }
 
-func (r *rootResolver) sendCardToSubs(c *model.Card) {
-	b, _ := msgpack.Marshal(c)
-	nc.Publish("cards-"+c.RetrospectiveId, b)
+// sendGroupToSubs publishes the card heading the merge group that the card
+// with the given id is in, which carries the whole group. The change was
+// made already, so the subscribers are notified even when the client that
+// made it went away.
+func (r *rootResolver) sendGroupToSubs(ctx context.Context, id string) {
+	ctx = tracing.Detach(ctx)
+	c, err := r.s.GetCardById(ctx, id)
+	for err == nil && c.MergedInto != nil {
+		c, err = r.s.GetCardById(ctx, *c.MergedInto)
+	}
+	if err == nil {
+		r.sendCardToSubs(ctx, c)
+	}
 }
 
-func (r *rootResolver) sendRetroToSubs(retro *model.Retrospective) {
-	b, _ := msgpack.Marshal(retro)
-	nc.Publish("retros-"+retro.Id, b)
+func (r *rootResolver) sendRetroToSubs(ctx context.Context, retro *model.Retrospective) {
+	publish(ctx, "retros", retro.Id, retro)
 }
 
-func (r *rootResolver) sendRetroToSubsById(rId string) {
-	retro, _ := r.s.GetRetrospectiveById(rId)
-	b, _ := msgpack.Marshal(retro)
-	nc.Publish("retros-"+retro.Id, b)
+func (r *rootResolver) sendRetroToSubsById(ctx context.Context, rId string) {
+	ctx = tracing.Detach(ctx)
+	retro, err := r.s.GetRetrospectiveById(ctx, rId)
+	if err != nil {
+		logging.Retro(ctx, r.logger, rId).WithError(err).Error("Failed to load retrospective")
+		return
+	}
+	publish(ctx, "retros", retro.Id, retro)
 }
 
 func (r *subscriptionResolver) CardChanged(ctx context.Context, rId string) (<-chan model.Card, error) {
+	if err := startSubscription(); err != nil {
+		return nil, err
+	}
 	cardChan := make(chan model.Card, 100)
 
-	user := ctx.Value("email").(string)
 	connectionId := ctx.Value("connectionId").(string)
-	r.mu.Lock()
-	if userLimiters[user] != nil {
-		userLimiters[user].Count += 1
-	} else {
-		userLimiters[user] = &CountedLimiter{rate.NewLimiter(10, 100), 1}
-	}
-	r.mu.Unlock()
 
 	natsChan := make(chan *nats.Msg, 100)
+	log := logging.Retro(ctx, r.logger, rId)
 	sub, err := nc.ChanSubscribe("cards-"+rId, natsChan)
 	if err != nil {
-		log.Println("ERROR: Failed to subscribe to card channel")
+		log.WithError(err).Error("Failed to subscribe to card channel")
+		subscriptions.Done()
 		return nil, err
 	}
 	go func(natsChan chan *nats.Msg, cardChan chan model.Card) {
@@ -104,23 +164,27 @@ func (r *subscriptionResolver) CardChanged(ctx context.Context, rId string) (<-c
 			var card model.Card
 			err := msgpack.Unmarshal(msg.Data, &card)
 			if err != nil {
-				log.Println("ERROR: Failed to unmarshal card message")
+				log.WithError(err).Error("Failed to unmarshal card message")
+				continue
 			}
 			cardChan <- card
 		}
 	}(natsChan, cardChan)
+	metrics.Subscribed(rId)
 
 	go func() {
-		<-ctx.Done()
-		r.o.ClearObservations(connectionId)
-		// Re-send retro to subs to update online users.
-		r.sendRetroToSubsById(rId)
-		r.mu.Lock()
-		userLimiters[user].Count -= 1
-		if userLimiters[user].Count == 0 {
-			delete(userLimiters, user)
+		defer subscriptions.Done()
+		select {
+		case <-ctx.Done():
+		case <-draining:
 		}
-		r.mu.Unlock()
+		// The subscription ended with its context or the server, clean up
+		// in a new one.
+		ctx := context.Background()
+		metrics.Unsubscribed(rId)
+		r.o.ClearObservations(ctx, connectionId)
+		// Re-send retro to subs to update online users.
+		r.sendRetroToSubsById(ctx, rId)
 		sub.Unsubscribe()
 		close(natsChan)
 	}()
@@ -129,30 +193,40 @@ func (r *subscriptionResolver) CardChanged(ctx context.Context, rId string) (<-c
 }
 
 func (r *subscriptionResolver) RetroChanged(ctx context.Context, rId string) (<-chan model.Retrospective, error) {
+	if err := startSubscription(); err != nil {
+		return nil, err
+	}
 	retroChan := make(chan model.Retrospective, 100)
 
 	natsChan := make(chan *nats.Msg, 100)
+	log := logging.Retro(ctx, r.logger, rId)
 	sub, err := nc.ChanSubscribe("retros-"+rId, natsChan)
 	if err != nil {
-		log.Println("ERROR: Failed to subscribe to card channel")
+		log.WithError(err).Error("Failed to subscribe to retro channel")
+		subscriptions.Done()
 		return nil, err
 	}
 	go func(natsChan chan *nats.Msg, retroChan chan model.Retrospective) {
 		for msg := range natsChan {
 			var retro model.Retrospective
-			err = msgpack.Unmarshal(msg.Data, &retro)
+			err := msgpack.Unmarshal(msg.Data, &retro)
 			if err != nil {
-				log.Println("ERROR: Failed to unmarshal retro message")
+				log.WithError(err).Error("Failed to unmarshal retro message")
+				continue
 			}
 			retroChan <- retro
 		}
 	}(natsChan, retroChan)
 
 	// Send initial retro update incase we missed something
-	r.sendRetroToSubsById(rId)
+	r.sendRetroToSubsById(ctx, rId)
 
 	go func() {
-		<-ctx.Done()
+		defer subscriptions.Done()
+		select {
+		case <-ctx.Done():
+		case <-draining:
+		}
 		sub.Unsubscribe()
 		close(natsChan)
 	}()
@@ -0,0 +1,107 @@
+// Package health serves the liveness and readiness of Rocketboard, which
+// report the status of every dependency as JSON.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is how long checks may take before they are reported as
+// failing.
+const DefaultTimeout = 2 * time.Second
+
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+var errTimedOut = errors.New("timed out")
+
+// A Check reports whether a dependency is usable, along with details such
+// as its version, which may be nil.
+type Check func(ctx context.Context) (map[string]interface{}, error)
+
+// Result is the outcome of a Check.
+type Result struct {
+	Status  string                 `json:"status"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Report is the response of Handler.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Run runs the checks concurrently, giving each of them up to timeout. The
+// report is failing if any of the checks fail.
+func Run(ctx context.Context, checks map[string]Check, timeout time.Duration) *Report {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	report := &Report{Status: StatusOK, Checks: make(map[string]Result, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			r := run(ctx, check)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = r
+			if r.Status != StatusOK {
+				report.Status = StatusFailing
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return report
+}
+
+// run runs check, giving up once ctx is done for checks that don't honour
+// it.
+func run(ctx context.Context, check Check) Result {
+	type outcome struct {
+		details map[string]interface{}
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		details, err := check(ctx)
+		done <- outcome{details, err}
+	}()
+
+	var o outcome
+	select {
+	case o = <-done:
+	case <-ctx.Done():
+		o.err = errTimedOut
+	}
+	r := Result{Status: StatusOK, Details: o.details}
+	if o.err != nil {
+		r.Status = StatusFailing
+		r.Error = o.err.Error()
+	}
+	return r
+}
+
+// Handler returns a handler running the checks, which answers with the
+// report and 200 if all of them pass, and 503 otherwise.
+func Handler(checks map[string]Check) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := Run(r.Context(), checks, DefaultTimeout)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if report.Status != StatusOK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	ok := func(ctx context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"version": 18}, nil
+	}
+	failing := func(ctx context.Context) (map[string]interface{}, error) {
+		return nil, errors.New("unreachable")
+	}
+
+	cases := []struct {
+		checks map[string]Check
+		code   int
+		status string
+	}{
+		{map[string]Check{}, http.StatusOK, StatusOK},
+		{map[string]Check{"database": ok}, http.StatusOK, StatusOK},
+		{map[string]Check{"database": ok, "nats": failing}, http.StatusServiceUnavailable, StatusFailing},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		Handler(c.checks).ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+		var report Report
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatal("Failed to decode report", err)
+		}
+		if w.Code != c.code || report.Status != c.status || len(report.Checks) != len(c.checks) {
+			t.Fatal("Expected", c.code, c.status, "got:", w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	Handler(map[string]Check{"database": ok, "nats": failing}).ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	var report Report
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if report.Checks["database"].Details["version"] != 18.0 || report.Checks["nats"].Error != "unreachable" {
+		t.Fatal("Expected the outcome of every check, got:", w.Body.String())
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	hanging := func(ctx context.Context) (map[string]interface{}, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	}
+	report := Run(context.Background(), map[string]Check{"database": hanging}, 10*time.Millisecond)
+	if report.Status != StatusFailing || report.Checks["database"].Error != errTimedOut.Error() {
+		t.Fatal("Expected hanging check to time out, got:", report)
+	}
+}
@@ -0,0 +1,82 @@
+// Package logging sets up the structured logger of Rocketboard. The logger
+// is passed to the parts that log, which add the request ID, user and
+// retrospective of what they are logging about as fields.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Configure sets logger to write at the given level ("debug", "info",
+// "warn", "error"), as JSON if format is "json" and as text otherwise.
+func Configure(logger *logrus.Logger, level string, format string) error {
+	if level != "" {
+		l, err := logrus.ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		logger.SetLevel(l)
+	}
+	switch strings.ToLower(format) {
+	case "json":
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	case "", "text":
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	default:
+		return fmt.Errorf("unknown log format %q, expected json or text", format)
+	}
+	return nil
+}
+
+// Init configures and returns the standard logger of logrus, which the
+// packages default to, see Configure. The logger of the standard library,
+// used by dependencies and the subcommands, writes through it.
+func Init(level string, format string) (*logrus.Logger, error) {
+	logger := logrus.StandardLogger()
+	if err := Configure(logger, level, format); err != nil {
+		return nil, err
+	}
+	log.SetFlags(0)
+	log.SetOutput(stdWriter{logger})
+	return logger, nil
+}
+
+// stdWriter logs the lines of the standard logger at info level. Unlike
+// logrus.Logger.Writer it does so before returning, so that log.Fatal
+// doesn't exit before its message is written.
+type stdWriter struct {
+	logger logrus.FieldLogger
+}
+
+func (w stdWriter) Write(p []byte) (int, error) {
+	w.logger.Info(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+// Request returns an entry of logger with the request ID and user of the
+// request ctx belongs to, and the trace ID of its span, if any.
+func Request(ctx context.Context, logger logrus.FieldLogger) *logrus.Entry {
+	fields := logrus.Fields{}
+	if id, ok := ctx.Value("connectionId").(string); ok {
+		fields["request_id"] = id
+	}
+	if user, ok := ctx.Value("email").(string); ok {
+		fields["user"] = user
+	}
+	if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+		fields["trace_id"] = span.TraceID().String()
+	}
+	return logger.WithFields(fields)
+}
+
+// Retro returns an entry of logger for the request ctx belongs to, about the
+// retrospective with the given id.
+func Retro(ctx context.Context, logger logrus.FieldLogger, rId string) *logrus.Entry {
+	return Request(ctx, logger).WithField("retro", rId)
+}
@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRetro(t *testing.T) {
+	var b bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&b)
+	if err := Configure(logger, "info", "json"); err != nil {
+		t.Fatal("Failed to configure logger", err)
+	}
+
+	ctx := context.WithValue(context.Background(), "email", "alice@example.com")
+	ctx = context.WithValue(ctx, "connectionId", "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	Retro(ctx, logger, "r1").Error("Failed")
+	Request(ctx, logger).Debug("Dropped")
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &line); err != nil {
+		t.Fatal("Expected a single JSON line, got:", b.String())
+	}
+	expected := map[string]interface{}{
+		"level":      "error",
+		"msg":        "Failed",
+		"request_id": "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+		"user":       "alice@example.com",
+		"retro":      "r1",
+	}
+	for k, v := range expected {
+		if line[k] != v {
+			t.Fatal("Expected", k, v, "got:", line[k])
+		}
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	if err := Configure(logrus.New(), "loud", ""); err == nil {
+		t.Fatal("Expected unknown level to fail")
+	}
+	if err := Configure(logrus.New(), "", "xml"); err == nil {
+		t.Fatal("Expected unknown format to fail")
+	}
+}
@@ -2,19 +2,45 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/base64"
+	"flag"
+	"fmt"
 	"github.com/99designs/gqlgen/handler"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/certs"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/config"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/exporter"
 	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/graph"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/health"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/logging"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/metrics"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/ratelimit"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/repository/inmem"
 	rocketSql "github.com/rocketdynamics/rocketboard/cmd/rocketboard/repository/sql"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/rest"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/retention"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/tracing"
 	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/utils"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/web"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/webhook"
+	"github.com/sirupsen/logrus"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"regexp"
+	"runtime/debug"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
-func WithEmail(base http.Handler) http.Handler {
+// WithEmail adds the user signed in through oauth2_proxy to the request
+// context. In debug mode, the ?email= parameter picks the user instead.
+func WithEmail(base http.Handler, debug bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		email := "unknown"
@@ -33,7 +59,7 @@ func WithEmail(base http.Handler) http.Handler {
 				}
 			}
 		}
-		if os.Getenv("DEBUG") == "1" && r.FormValue("email") != "" {
+		if debug && r.FormValue("email") != "" {
 			email = r.FormValue("email")
 		}
 		ctx = context.WithValue(ctx, "email", email)
@@ -43,38 +69,304 @@ func WithEmail(base http.Handler) http.Handler {
 	})
 }
 
-func main() {
-	dbURI := os.Getenv("ROCKET_DATABASE_URI")
-	if dbURI == "" {
-		log.Println("No ROCKET_DATABASE_URI specified, using sqlite3:rocket.db")
-		dbURI = "sqlite3:rocket.db"
+// WithRecover answers requests whose handler panicked with an internal error
+// and logs the panic, where net/http would just drop the connection.
+func WithRecover(base http.Handler, logger logrus.FieldLogger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil || p == http.ErrAbortHandler {
+				return
+			}
+			logging.Request(r.Context(), logger).WithFields(logrus.Fields{
+				"panic": p,
+				"path":  r.URL.Path,
+				"stack": string(debug.Stack()),
+			}).Error("Recovered from panic")
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}()
+		base.ServeHTTP(w, r)
+	})
+}
+
+// WithHSTS tells browsers to only use HTTPS for maxAge.
+func WithHSTS(base http.Handler, maxAge time.Duration) http.Handler {
+	header := fmt.Sprintf("max-age=%d", int(maxAge.Seconds()))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Strict-Transport-Security", header)
+		base.ServeHTTP(w, r)
+	})
+}
+
+// RedirectToHTTPS returns a handler redirecting requests to the same URL on
+// HTTPS, served on the port of addr.
+func RedirectToHTTPS(addr string) http.Handler {
+	_, port, _ := net.SplitHostPort(addr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		code := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), code)
+	})
+}
+
+// readinessChecks returns the checks of the dependencies that requests need:
+// the repository, its schema and NATS. Instances are also not ready while
+// they shut down.
+func readinessChecks(svc *rocketboardService, repository repository) map[string]health.Check {
+	checks := map[string]health.Check{
+		"database": func(ctx context.Context) (map[string]interface{}, error) {
+			return nil, svc.Healthcheck(ctx)
+		},
+		"nats": func(ctx context.Context) (map[string]interface{}, error) {
+			url, err := graph.CheckMessageQueue(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"url": url}, nil
+		},
+		"shutdown": func(ctx context.Context) (map[string]interface{}, error) {
+			if graph.Draining() {
+				return nil, fmt.Errorf("draining")
+			}
+			return nil, nil
+		},
+	}
+	if db, ok := repository.(interface {
+		SchemaVersion(context.Context) (int, error)
+	}); ok {
+		checks["migrations"] = func(ctx context.Context) (map[string]interface{}, error) {
+			version, err := db.SchemaVersion(ctx)
+			if err != nil {
+				return nil, err
+			}
+			details := map[string]interface{}{
+				"version": version,
+				"latest":  rocketSql.LatestVersion(),
+			}
+			if version < rocketSql.LatestVersion() {
+				return details, fmt.Errorf("migrations are pending")
+			}
+			return details, nil
+		}
+	}
+	return checks
+}
+
+// newRepository opens the repository configured by c, where the URI inmem:
+// selects an in-memory repository that is lost on exit.
+func newRepository(c *config.Config, logger logrus.FieldLogger) (repository, error) {
+	if strings.HasPrefix(c.Database.URI, "inmem:") {
+		logger.Warn("Using in-memory repository, data will be lost on exit")
+		db := inmem.NewRepository()
+		db.MaxCards = c.Limits.MaxCards
+		db.PresenceWindow = c.Limits.PresenceWindow
+		return db, nil
 	}
-	repository, err := rocketSql.NewRepository(dbURI)
+	db, err := rocketSql.NewRepository(c.Database.URI)
 	if err != nil {
+		return nil, err
+	}
+	db.QueryTimeout = c.Database.QueryTimeout
+	db.Logger = logger
+	db.MaxCards = c.Limits.MaxCards
+	db.PresenceWindow = c.Limits.PresenceWindow
+	return db, nil
+}
+
+func main() {
+	cfg, args, err := config.Load(os.Args[1:])
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	} else if err != nil {
 		log.Fatal(err)
 	}
-	svc := NewRocketboardService(repository)
+	logger, err := logging.Init(cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		log.Fatal("Failed to set up logging: ", err)
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			runMigrate(cfg.Database.URI, args[1:])
+		case "backup":
+			runBackup(cfg.Database.URI, args[1:])
+		case "restore":
+			runRestore(cfg.Database.URI, args[1:])
+		default:
+			log.Fatalf("unknown command %q, expected migrate, backup or restore", args[0])
+		}
+		return
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to set up tracing")
+	}
+
+	repository, err := newRepository(cfg, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to open repository")
+	}
+	hooks := webhook.NewDispatcher(repository)
+	hooks.Logger = logger
+	hooks.MaxAttempts = cfg.Webhooks.MaxAttempts
+	hooks.BaseBackoff = cfg.Webhooks.BaseBackoff
+	hooks.MaxBackoff = cfg.Webhooks.MaxBackoff
+	hooks.PollInterval = cfg.Webhooks.PollInterval
+	// The workers are stopped on shutdown, before the repository is closed.
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		hooks.Run(workersCtx)
+	}()
+
+	janitor := retention.NewJanitor(repository)
+	janitor.Logger = logger
+	janitor.DefaultRetentionDays = cfg.Retention.Days
+	janitor.ObservationTTL = cfg.Retention.ObservationTTL
+	janitor.Interval = cfg.Retention.Interval
+	go func() {
+		defer workers.Done()
+		janitor.Run(workersCtx)
+	}()
+
+	svc := NewRocketboardService(repository, hooks, logger)
+	svc.publicUrl = cfg.PublicURL
+	svc.maxMessageLength = cfg.Limits.MaxMessageLength
+	svc.exporters = exporter.FromEnv()
+	svc.voteLimiter = ratelimit.New(cfg.Limits.VoteRate, cfg.Limits.VoteBurst)
 	obs := NewObservationStore(repository)
-	graph.InitMessageQueue()
+	graph.InitMessageQueue(cfg.NATS.Addr, logger)
 
+	http.Handle("/metrics", metrics.Handler())
 	http.Handle("/query-playground", handler.Playground("Rocketboard", "/query"))
-	http.HandleFunc("/healthcheck", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		svc.Healthcheck()
-		w.Write([]byte("OK"))
-	})
+	// Liveness doesn't depend on anything outside of the process, so that an
+	// outage of the database doesn't restart every instance.
+	http.Handle("/livez", health.Handler(map[string]health.Check{}))
+	ready := health.Handler(readinessChecks(svc, repository))
+	http.Handle("/readyz", ready)
+	// Kept for probes predating /livez and /readyz
+	http.Handle("/healthcheck", ready)
 
-	http.Handle("/query", WithEmail(handler.GraphQL(
+	http.Handle("/query", tracing.Handler(graph.TrackWebsockets(WithEmail(handler.GraphQL(
 		graph.NewExecutableSchema(graph.Config{
-			Resolvers: graph.NewResolver(svc, obs),
+			Resolvers: graph.NewResolver(svc, obs, logger),
 		}),
-	)))
+		handler.RequestMiddleware(graph.RequestMiddleware),
+		handler.ResolverMiddleware(graph.ResolverMiddleware),
+		handler.ErrorPresenter(graph.ErrorPresenter(logger)),
+		handler.RecoverFunc(graph.Recover(logger)),
+	), cfg.Debug)), "/query"))
+	http.Handle("/events/", tracing.Handler(WithEmail(graph.NewEventsHandler(svc, obs, logger), cfg.Debug), "/events"))
+	http.Handle("/api/v1/", tracing.Handler(WithEmail(rest.NewHandler(svc, graph.PublishCard, logger), cfg.Debug), "/api/v1"))
 
-	http.HandleFunc("/retrospective/", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "./public/index.html")
-	})
-	http.Handle("/", http.FileServer(http.Dir("./public/")))
+	frontend, err := web.Handler(cfg.PublicDir)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to serve the frontend")
+	}
+	http.Handle("/", frontend)
 
-	log.Println("Listening on port 5000 ...")
-	log.Fatal(http.ListenAndServe(":5000", nil))
+	srv := &http.Server{
+		Addr:    cfg.Addr,
+		Handler: WithRecover(http.DefaultServeMux, logger),
+	}
+	var redirect *http.Server
+	if cfg.TLS.Enabled() {
+		cert, err := certs.NewReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to load TLS certificate")
+		}
+		srv.TLSConfig = &tls.Config{
+			GetCertificate: cert.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
+			NextProtos:     []string{"h2", "http/1.1"},
+		}
+		if cfg.TLS.HSTSMaxAge > 0 {
+			srv.Handler = WithHSTS(srv.Handler, cfg.TLS.HSTSMaxAge)
+		}
+		if cfg.TLS.RedirectAddr != "" {
+			redirect = &http.Server{
+				Addr:    cfg.TLS.RedirectAddr,
+				Handler: RedirectToHTTPS(cfg.Addr),
+			}
+		}
+	}
+
+	stop, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	served := make(chan error, 2)
+	go func() {
+		if srv.TLSConfig != nil {
+			logger.Infof("Listening on %s with TLS ...", cfg.Addr)
+			served <- srv.ListenAndServeTLS("", "")
+		} else {
+			logger.Infof("Listening on %s ...", cfg.Addr)
+			served <- srv.ListenAndServe()
+		}
+	}()
+	if redirect != nil {
+		go func() {
+			logger.Infof("Redirecting to HTTPS on %s ...", redirect.Addr)
+			served <- redirect.ListenAndServe()
+		}()
+	}
+	select {
+	case err := <-served:
+		logger.WithError(err).Fatal("Failed to serve")
+	case <-stop.Done():
+	}
+	// A second signal exits right away
+	stopSignals()
+
+	logger.Infof("Shutting down, waiting up to %s ...", cfg.ShutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if redirect != nil {
+		redirect.Shutdown(ctx)
+	}
+	if err := shutdown(ctx, srv); err != nil {
+		logger.WithError(err).Error("Failed to shut down cleanly")
+	}
+	stopWorkers()
+	workers.Wait()
+	if db, ok := repository.(io.Closer); ok {
+		if err := db.Close(); err != nil {
+			logger.WithError(err).Error("Failed to close repository")
+		}
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		logger.WithError(err).Error("Failed to flush traces")
+	}
+	logger.Info("Shut down")
+}
+
+// shutdown stops accepting connections and waits for the requests in flight.
+// Subscriptions, which live on hijacked websocket connections that srv
+// doesn't track, and event streams are ended alongside, clearing the
+// observations of their connections. Finally, the updates they published
+// are flushed to NATS.
+func shutdown(ctx context.Context, srv *http.Server) error {
+	drained := make(chan error, 1)
+	go func() {
+		drained <- graph.Drain(ctx)
+	}()
+	err := srv.Shutdown(ctx)
+	if drainErr := <-drained; err == nil {
+		err = drainErr
+	}
+	if mqErr := graph.CloseMessageQueue(ctx); err == nil {
+		err = mqErr
+	}
+	return err
 }
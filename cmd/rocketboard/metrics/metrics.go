@@ -0,0 +1,101 @@
+// Package metrics defines the Prometheus metrics of Rocketboard, which are
+// served on /metrics.
+package metrics
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	subscribers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rocketboard_subscribers",
+		Help: "Card subscriptions, by retrospective.",
+	}, []string{"retrospective"})
+
+	Heartbeats = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rocketboard_heartbeats_total",
+		Help: "Heartbeats received from boards.",
+	})
+
+	RateLimitedVotes = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rocketboard_rate_limited_votes_total",
+		Help: "Votes that were dropped because the voter was rate limited.",
+	})
+
+	GraphQLDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rocketboard_graphql_duration_seconds",
+		Help:    "Time taken to execute GraphQL operations, by operation name and type.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation", "type"})
+
+	ResolverDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rocketboard_graphql_resolver_duration_seconds",
+		Help:    "Time taken by the resolvers of root query and mutation fields.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"object", "field"})
+
+	ResolverErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rocketboard_graphql_resolver_errors_total",
+		Help: "Errors returned by the resolvers of root query and mutation fields.",
+	}, []string{"object", "field"})
+
+	NatsPublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rocketboard_nats_publish_failures_total",
+		Help: "Updates that could not be published to NATS, by kind of update.",
+	}, []string{"kind"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rocketboard_db_query_duration_seconds",
+		Help:    "Time taken by database statements outside of transactions, by statement and table.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"statement"})
+
+	DBTransactionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rocketboard_db_transaction_duration_seconds",
+		Help:    "Time taken by database transactions including retries, by repository method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"name"})
+
+	DBTransactionRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rocketboard_db_transaction_retries_total",
+		Help: "Transactions that were retried after conflicting with another one.",
+	}, []string{"name"})
+)
+
+var (
+	subscribersMu sync.Mutex
+	// Subscriptions by retrospective, to drop the series of retrospectives
+	// nobody is looking at anymore.
+	subscriptions = map[string]int{}
+)
+
+// Subscribed counts a new card subscription to a retrospective.
+func Subscribed(rId string) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	subscriptions[rId]++
+	subscribers.WithLabelValues(rId).Set(float64(subscriptions[rId]))
+}
+
+// Unsubscribed counts a card subscription to a retrospective that ended.
+func Unsubscribed(rId string) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	subscriptions[rId]--
+	if subscriptions[rId] <= 0 {
+		delete(subscriptions, rId)
+		subscribers.DeleteLabelValues(rId)
+		return
+	}
+	subscribers.WithLabelValues(rId).Set(float64(subscriptions[rId]))
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSubscribers(t *testing.T) {
+	Subscribed("retro")
+	Subscribed("retro")
+	Subscribed("other")
+	if n := testutil.ToFloat64(subscribers.WithLabelValues("retro")); n != 2 {
+		t.Fatal("Expected 2 subscribers, got:", n)
+	}
+
+	Unsubscribed("retro")
+	Unsubscribed("retro")
+	if n := testutil.CollectAndCount(subscribers); n != 1 {
+		t.Fatal("Expected retrospective without subscribers to be dropped, got:", n, "series")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	rocketSql "github.com/rocketdynamics/rocketboard/cmd/rocketboard/repository/sql"
+)
+
+const migrateUsage = "usage: rocketboard migrate up|down [steps]|status"
+
+// migrate implements the `rocketboard migrate` subcommand.
+func migrate(dbURI string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(migrateUsage)
+	}
+
+	if strings.HasPrefix(dbURI, "inmem:") {
+		return fmt.Errorf("the in-memory repository has no migrations")
+	}
+
+	db, err := rocketSql.Open(dbURI)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		return db.MigrateUp(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+		}
+		return db.MigrateDown(ctx, steps)
+	case "status":
+		status, err := db.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			applied := "pending"
+			if s.Applied != nil {
+				applied = s.Applied.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(os.Stdout, "%4d  %-19s  %s\n", s.Version, applied, s.Name)
+		}
+		return nil
+	}
+	return fmt.Errorf(migrateUsage)
+}
+
+func runMigrate(dbURI string, args []string) {
+	if err := migrate(dbURI, args); err != nil {
+		log.Fatal(err)
+	}
+}
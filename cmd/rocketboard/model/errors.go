@@ -0,0 +1,115 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// The errors returned by the service and the repositories are of one of the
+// kinds below, which clients tell apart by the code extension of GraphQL
+// errors and by the status of REST responses. Any other error is an internal
+// failure, with the code INTERNAL.
+const (
+	CodeNotFound    = "NOT_FOUND"
+	CodeForbidden   = "FORBIDDEN"
+	CodeConflict    = "CONFLICT"
+	CodeValidation  = "VALIDATION"
+	CodeRateLimited = "RATE_LIMITED"
+	CodeInternal    = "INTERNAL"
+)
+
+// Code returns the code of the kind of err.
+func Code(err error) string {
+	var notFound *NotFoundError
+	var forbidden ForbiddenError
+	var conflict *ConflictError
+	var input InputError
+	var rateLimited *RateLimitedError
+	switch {
+	case errors.As(err, &notFound), errors.Is(err, sql.ErrNoRows):
+		return CodeNotFound
+	case errors.As(err, &forbidden):
+		return CodeForbidden
+	case errors.As(err, &conflict):
+		return CodeConflict
+	case errors.As(err, &input):
+		return CodeValidation
+	case errors.As(err, &rateLimited):
+		return CodeRateLimited
+	}
+	return CodeInternal
+}
+
+// NotFoundError is returned when the entity of the given kind and id does
+// not exist. It is a sql.ErrNoRows for callers checking for that.
+type NotFoundError struct {
+	Kind string
+	Id   string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not found", e.Kind, e.Id)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == sql.ErrNoRows
+}
+
+func (e *NotFoundError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": CodeNotFound}
+}
+
+// ForbiddenError is returned when the caller may not do what they asked for.
+type ForbiddenError string
+
+func (e ForbiddenError) Error() string {
+	return string(e)
+}
+
+func (e ForbiddenError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": CodeForbidden}
+}
+
+// InputError is returned when a request can never succeed as given, e.g.
+// because of an unknown emoji or a card limit, as opposed to failures that
+// are outside of the caller's control.
+type InputError string
+
+func (e InputError) Error() string {
+	return string(e)
+}
+
+func (e InputError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": CodeValidation}
+}
+
+// ConflictError is returned when a card was changed by someone else since it
+// was read. Card is its current state.
+type ConflictError struct {
+	Card *Card
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("card %s was changed concurrently, current version is %d", e.Card.Id, e.Card.Version)
+}
+
+// Extensions are added to GraphQL errors, to hand clients the current card.
+func (e *ConflictError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": CodeConflict,
+		"card": e.Card,
+	}
+}
+
+// RateLimitedError is returned when the caller made too many requests and
+// should slow down.
+type RateLimitedError struct{}
+
+func (e *RateLimitedError) Error() string {
+	return "Too many requests, slow down"
+}
+
+func (e *RateLimitedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": CodeRateLimited}
+}
@@ -0,0 +1,46 @@
+package model
+
+import "fmt"
+
+// MaxMergeDepth limits how many levels a merge group can have, counting the
+// card at its head.
+const MaxMergeDepth = 3
+
+// CheckMerge returns an InputError if the card with the given id can not be
+// merged into mergedInto, because it would create a cycle or nest too deeply.
+// cards holds every card of the retrospective by id; a mergedInto that is
+// missing from it belongs to another retrospective.
+func CheckMerge(cards map[string]*Card, id string, mergedInto string) error {
+	if _, ok := cards[mergedInto]; !ok {
+		return InputError("Cards can only be merged within a retrospective")
+	}
+
+	depth := 1
+	for parent := cards[mergedInto]; parent != nil; depth++ {
+		if parent.Id == id {
+			return InputError("Cannot merge a card into itself or a card merged into it")
+		}
+		if parent.MergedInto == nil {
+			break
+		}
+		parent = cards[*parent.MergedInto]
+	}
+
+	if depth+height(cards, id) > MaxMergeDepth {
+		return InputError(fmt.Sprintf("Merge groups cannot be nested more than %d levels deep", MaxMergeDepth))
+	}
+	return nil
+}
+
+// height returns the number of levels of the merge group headed by id.
+func height(cards map[string]*Card, id string) int {
+	h := 0
+	for _, c := range cards {
+		if c.MergedInto != nil && *c.MergedInto == id {
+			if ch := height(cards, c.Id); ch > h {
+				h = ch
+			}
+		}
+	}
+	return h + 1
+}
@@ -3,43 +3,110 @@
 package model
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Retrospective struct {
-	Id string
+	Id string `json:"id"`
 
-	Created time.Time
-	Updated time.Time
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
 
-	Name    string
-	PetName string
+	Name    string `json:"name"`
+	PetName string `json:"petName"`
+	Team    string `json:"team"`
+
+	Closed *time.Time `json:"closed"`
 }
 
 type Card struct {
-	Id string
+	Id string `json:"id"`
 
-	Created time.Time
-	Updated time.Time
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
 
-	RetrospectiveId string
+	RetrospectiveId string `json:"retrospectiveId"`
+
+	Message     string  `json:"message"`
+	Creator     string  `json:"creator"`
+	Column      string  `json:"column"`
+	MergedCards []*Card `json:"mergedCards"`
+	MergedInto  *string `db:"mergedinto" json:"mergedInto"`
+
+	Position int `json:"position"`
+
+	// Title of the merge group the card heads, if any.
+	MergeTitle *string `db:"mergetitle" json:"mergeTitle"`
 
-	Message     string
-	Creator     string
-	Column      string
-	MergedCards []*Card
-	MergedInto  *string `db:"mergedInto"`
+	// The named group within its column the card is in, if any.
+	GroupId *string `db:"groupid" json:"groupId"`
 
-	Position int
+	// Link to the issue the card was exported to, if any.
+	IssueUrl *string `json:"issueUrl"`
+	// When an export of the card started, while it is being exported.
+	ExportClaimed *time.Time `db:"exportclaimed" json:"-"`
+
+	// Counts the changes to the card, so that edits based on an outdated
+	// card can be refused.
+	Version int `json:"version"`
 }
 
 func (c *Card) String() string {
 	return strconv.Itoa(c.Position)
 }
 
+// Group returns the card followed by all cards merged into it, at any depth.
+func (c *Card) Group() []*Card {
+	cs := []*Card{c}
+	for _, merged := range c.MergedCards {
+		cs = append(cs, merged.Group()...)
+	}
+	return cs
+}
+
+// CardGroup clusters cards within a column under a name, without merging
+// them, so that their votes stay visible. Groups are ordered by position
+// among the other groups of their column.
+type CardGroup struct {
+	Id string `json:"id"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+
+	RetrospectiveId string `json:"retrospectiveId"`
+
+	Column   string `json:"column"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+// VoteTotal sums up the votes with an emoji across several cards.
+type VoteTotal struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
+
+// Merge records a card being merged into another one, along with where the
+// card was on the board before, so that it can be put back there.
+type Merge struct {
+	Id string `json:"id"`
+
+	Created time.Time `json:"created"`
+	CardId  string    `json:"cardId"`
+
+	MergedInto string `db:"mergedinto" json:"mergedInto"`
+
+	Column   string `json:"column"`
+	Position int    `json:"position"`
+
+	Unmerged *time.Time `json:"unmerged"`
+}
+
 func (t *StatusType) UnmarshalGQL(v interface{}) error {
 	str, ok := v.(string)
 	if !ok {
@@ -55,6 +122,33 @@ func (t StatusType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(t.String()))
 }
 
+func (t *StatusType) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	return t.UnmarshalGQL(str)
+}
+
+func (t StatusType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+func (t *DeliveryStateType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	var err error
+	*t, err = DeliveryStateTypeString(str)
+	return err
+}
+
+func (t DeliveryStateType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(t.String()))
+}
+
 func (t *UserStateType) UnmarshalGQL(v interface{}) error {
 	str, ok := v.(string)
 	if !ok {
@@ -70,6 +164,18 @@ func (t UserStateType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(t.String()))
 }
 
+func (t *UserStateType) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	return t.UnmarshalGQL(str)
+}
+
+func (t UserStateType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
 type StatusType int
 
 const (
@@ -81,6 +187,14 @@ const (
 
 type UserStateType int
 
+type DeliveryStateType int
+
+const (
+	Pending DeliveryStateType = iota
+	Delivered
+	Failed
+)
+
 const (
 	Unknown UserStateType = iota
 	Hidden
@@ -88,30 +202,30 @@ const (
 )
 
 type Status struct {
-	Id string
+	Id string `json:"id"`
 
-	Created time.Time
-	CardId  string
+	Created time.Time `json:"created"`
+	CardId  string    `json:"cardId"`
 
-	Type StatusType
+	Type StatusType `json:"type"`
 }
 
 type UserState struct {
-	User  string
-	State UserStateType
+	User  string        `json:"user"`
+	State UserStateType `json:"state"`
 }
 
 type Vote struct {
-	Id string
+	Id string `json:"id"`
 
-	Created time.Time
-	Updated time.Time
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
 
-	CardId string
+	CardId string `json:"cardId"`
 
-	Voter string
-	Emoji string
-	Count int
+	Voter string `json:"voter"`
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
 }
 
 type Observation struct {
@@ -122,3 +236,90 @@ type Observation struct {
 	FirstSeen       time.Time
 	LastSeen        time.Time
 }
+
+type Team struct {
+	Name string `json:"name"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+
+	// Incoming webhook (Slack or Mattermost) that summaries are posted to.
+	ChatWebhookUrl string `json:"chatWebhookUrl"`
+
+	// Days after which closed retrospectives of the team are archived, 0 to
+	// keep them forever, or nil for the default retention.
+	RetentionDays *int `json:"retentionDays"`
+}
+
+// Archive is a retrospective that was purged after the retention period of
+// its team, kept as a compressed Snapshot.
+type Archive struct {
+	// Same as the id of the retrospective
+	Id string `json:"id"`
+
+	Created time.Time `json:"created"`
+
+	Name    string    `json:"name"`
+	PetName string    `json:"petName"`
+	Team    string    `json:"team"`
+	Closed  time.Time `json:"closed"`
+
+	// Gzipped JSON of the Snapshot, only loaded for a single archive.
+	Snapshot []byte `json:"snapshot"`
+}
+
+type Webhook struct {
+	Id string `json:"id"`
+
+	Created time.Time `json:"created"`
+
+	// Webhooks are either registered for a single retrospective, or for all
+	// retrospectives of a team.
+	RetrospectiveId *string `json:"retrospectiveId"`
+	Team            string  `json:"team"`
+
+	Url    string `json:"url"`
+	Secret string `json:"secret"`
+	// Comma separated list of events to deliver, or empty for all events.
+	Events string `json:"events"`
+}
+
+// Wants reports whether the webhook is subscribed to the given event.
+func (w *Webhook) Wants(event string) bool {
+	if w.Events == "" {
+		return true
+	}
+	for _, e := range strings.Split(w.Events, ",") {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDelivery struct {
+	Id string `json:"id"`
+
+	Created time.Time `json:"created"`
+
+	WebhookId string `json:"webhookId"`
+	Event     string `json:"event"`
+	Payload   string `json:"payload"`
+
+	State       DeliveryStateType `json:"state"`
+	Attempts    int               `json:"attempts"`
+	NextAttempt time.Time         `json:"nextAttempt"`
+	// Set by the instance currently sending the delivery.
+	Claim string `json:"claim"`
+}
+
+type WebhookAttempt struct {
+	Id string `json:"id"`
+
+	Created    time.Time `json:"created"`
+	DeliveryId string    `json:"deliveryId"`
+
+	StatusCode int    `json:"statusCode"`
+	Error      string `json:"error"`
+	Duration   int    `json:"duration"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+)
+
+// Snapshot is everything recorded about a retrospective. Cards are listed
+// flat, merged cards refer to the card they were merged into by MergedInto.
+type Snapshot struct {
+	Retrospective *Retrospective `json:"retrospective"`
+	Cards         []*Card        `json:"cards"`
+	Groups        []*CardGroup   `json:"groups"`
+	Merges        []*Merge       `json:"merges"`
+	Votes         []*Vote        `json:"votes"`
+	Statuses      []*Status      `json:"statuses"`
+}
+
+// Compress returns the snapshot as gzipped JSON.
+func (s *Snapshot) Compress() ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(s); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecompressSnapshot reads a snapshot from gzipped JSON.
+func DecompressSnapshot(r io.Reader) (*Snapshot, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var s Snapshot
+	if err := json.NewDecoder(zr).Decode(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,65 @@
+// Package ratelimit limits how often each user may do something, such as
+// voting, regardless of the API they do it through.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limiter allows each key a number of events per second, and a burst of
+// events at once.
+type Limiter struct {
+	mu       sync.Mutex
+	rate     rate.Limit
+	burst    int
+	limiters map[string]*limiter
+	// Limiters unused for this long are full again and can be dropped.
+	idle      time.Duration
+	lastPrune time.Time
+}
+
+type limiter struct {
+	*rate.Limiter
+	used time.Time
+}
+
+// New returns a Limiter allowing perSecond events per second, and burst
+// events at once, for each key.
+func New(perSecond float64, burst int) *Limiter {
+	return &Limiter{
+		rate:     rate.Limit(perSecond),
+		burst:    burst,
+		limiters: make(map[string]*limiter),
+		idle:     time.Duration(float64(burst) / perSecond * float64(time.Second)),
+	}
+}
+
+// Allow reports whether key may have another event now.
+func (l *Limiter) Allow(key string) bool {
+	return l.allow(key, time.Now())
+}
+
+func (l *Limiter) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastPrune) >= l.idle {
+		for k, lim := range l.limiters {
+			if now.Sub(lim.used) >= l.idle {
+				delete(l.limiters, k)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	lim := l.limiters[key]
+	if lim == nil {
+		lim = &limiter{Limiter: rate.NewLimiter(l.rate, l.burst)}
+		l.limiters[key] = lim
+	}
+	lim.used = now
+	return lim.AllowN(now, 1)
+}
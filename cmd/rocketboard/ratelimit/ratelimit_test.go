@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	l := New(1, 2)
+	now := time.Now()
+
+	if !l.allow("alice", now) || !l.allow("alice", now) {
+		t.Fatal("Expected a burst of 2 to be allowed")
+	}
+	if l.allow("alice", now) {
+		t.Fatal("Expected a third event to be limited")
+	}
+	if !l.allow("bob", now) {
+		t.Fatal("Expected other keys not to be limited")
+	}
+	if !l.allow("alice", now.Add(time.Second)) {
+		t.Fatal("Expected an event to be allowed a second later")
+	}
+}
+
+func TestLimiterPrunesIdleKeys(t *testing.T) {
+	l := New(1, 2)
+	now := time.Now()
+
+	l.allow("alice", now)
+	l.allow("bob", now.Add(time.Second))
+	l.allow("bob", now.Add(3*time.Second))
+	if _, ok := l.limiters["alice"]; ok || len(l.limiters) != 1 {
+		t.Fatal("Expected only the idle limiter to be dropped, got:", l.limiters)
+	}
+}
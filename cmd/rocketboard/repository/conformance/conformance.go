@@ -0,0 +1,988 @@
+// Package conformance verifies that repository implementations behave the
+// same, so that the service works no matter which one it runs on.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+// Repository is the part of the service's repository interface covered by
+// the suite.
+type Repository interface {
+	NewRetrospective(context.Context, *model.Retrospective) error
+	UpdateRetrospective(context.Context, *model.Retrospective) error
+	GetRetrospectiveById(context.Context, string) (*model.Retrospective, error)
+	GetRetrospectiveByPetName(context.Context, string) (*model.Retrospective, error)
+
+	NewCard(context.Context, *model.Card) error
+	UpdateCard(context.Context, *model.Card) error
+	MoveCard(context.Context, *model.Card, string, int) error
+	MergeCard(context.Context, *model.Card, *model.Merge) error
+	UnmergeCard(context.Context, *model.Card) error
+	UnmergeAll(context.Context, *model.Card) error
+	GetMergesByCardId(context.Context, string) ([]*model.Merge, error)
+
+	NewCardGroup(context.Context, *model.CardGroup) error
+	UpdateCardGroup(context.Context, *model.CardGroup) error
+	MoveCardGroup(context.Context, *model.CardGroup, int) error
+	DeleteCardGroup(context.Context, *model.CardGroup) error
+	GetCardGroupById(context.Context, string) (*model.CardGroup, error)
+	GetCardGroupsByRetrospectiveId(context.Context, string) ([]*model.CardGroup, error)
+	GetCardById(context.Context, string) (*model.Card, error)
+	GetCardsByRetrospectiveId(context.Context, string) ([]*model.Card, error)
+
+	NewVote(context.Context, *model.Vote) error
+	GetVotesByCardId(context.Context, string) ([]*model.Vote, error)
+	GetVoteByCardIdAndVoterAndEmoji(context.Context, string, string, string) (*model.Vote, error)
+	GetTotalUniqueEmojis(context.Context, string) (int, error)
+
+	NewStatus(context.Context, *model.Status) error
+	GetStatusById(context.Context, string) (*model.Status, error)
+	GetStatusesByCardId(context.Context, string) ([]*model.Status, error)
+
+	GetClosedRetrospectives(context.Context, time.Time) ([]*model.Retrospective, error)
+	ArchiveRetrospective(context.Context, *model.Archive) error
+	GetSnapshot(context.Context, string) (*model.Snapshot, error)
+	GetArchivesByTeam(context.Context, string) ([]*model.Archive, error)
+	GetArchiveById(context.Context, string) (*model.Archive, error)
+
+	Observe(context.Context, string, string, string, string) (bool, error)
+	GetActiveUsers(context.Context, string) ([]model.UserState, error)
+	ClearObservations(context.Context, string)
+	PurgeObservations(context.Context, time.Time) (int, error)
+
+	Healthcheck(context.Context) error
+}
+
+// Run runs the suite, calling open for an empty repository in every test.
+func Run(t *testing.T, open func(t *testing.T) Repository) {
+	tests := []struct {
+		name string
+		test func(*testing.T, Repository)
+	}{
+		{"Retrospectives", testRetrospectives},
+		{"PetNames", testPetNames},
+		{"CardPositions", testCardPositions},
+		{"ConcurrentMoves", testConcurrentMoves},
+		{"Versions", testVersions},
+		{"MergeTrees", testMergeTrees},
+		{"UnmergeAll", testUnmergeAll},
+		{"MergeChecks", testMergeChecks},
+		{"MergeHistory", testMergeHistory},
+		{"CardGroups", testCardGroups},
+		{"Votes", testVotes},
+		{"StatusHistory", testStatusHistory},
+		{"Archives", testArchives},
+		{"Observations", testObservations},
+		{"StaleObservations", testStaleObservations},
+		{"Cancellation", testCancellation},
+		{"NotFound", testNotFound},
+		{"Healthcheck", testHealthcheck},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, open(t))
+		})
+	}
+}
+
+// The precision of timestamps differs between databases.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+func newRetro(t *testing.T, db Repository, id string) *model.Retrospective {
+	t.Helper()
+	ctx := context.Background()
+	r := &model.Retrospective{Id: id, Created: now(), Updated: now(), Name: "Retro " + id, PetName: "pet-" + id}
+	if err := db.NewRetrospective(ctx, r); err != nil {
+		t.Fatal("Failed to create retrospective", err)
+	}
+	return r
+}
+
+func newCards(t *testing.T, db Repository, rId string, column string, n int) []string {
+	t.Helper()
+	ctx := context.Background()
+	ids := []string{}
+	for i := 0; i < n; i++ {
+		c := &model.Card{
+			Id:              fmt.Sprint(rId, "-", column, "-", i),
+			Created:         now(),
+			Updated:         now(),
+			RetrospectiveId: rId,
+			Message:         fmt.Sprint("Card ", i),
+			Column:          column,
+		}
+		if err := db.NewCard(ctx, c); err != nil {
+			t.Fatal("Failed to create card", err)
+		}
+		ids = append(ids, c.Id)
+	}
+	return ids
+}
+
+// column returns the ids of the top level cards in a column, in order.
+func column(t *testing.T, db Repository, rId string, column string) []string {
+	t.Helper()
+	ctx := context.Background()
+	cards, err := db.GetCardsByRetrospectiveId(ctx, rId)
+	if err != nil {
+		t.Fatal("Failed to get cards", err)
+	}
+	ids := []string{}
+	for _, c := range cards {
+		if c.Column == column {
+			ids = append(ids, c.Id)
+		}
+	}
+	return ids
+}
+
+func ids(cards []*model.Card) []string {
+	ids := []string{}
+	for _, c := range cards {
+		ids = append(ids, c.Id)
+	}
+	return ids
+}
+
+func expectIds(t *testing.T, what string, expected []string, got []string) {
+	t.Helper()
+	if fmt.Sprint(expected) != fmt.Sprint(got) {
+		t.Fatal("Expected", what, expected, "got:", got)
+	}
+}
+
+func move(t *testing.T, db Repository, id string, column string, index int) {
+	t.Helper()
+	ctx := context.Background()
+	c, err := db.GetCardById(ctx, id)
+	if err != nil {
+		t.Fatal("Failed to get card", err)
+	}
+	if err := db.MoveCard(ctx, c, column, index); err != nil {
+		t.Fatal("Failed to move card", err)
+	}
+}
+
+func merge(t *testing.T, db Repository, id string, mergedInto string) error {
+	t.Helper()
+	ctx := context.Background()
+	c, err := db.GetCardById(ctx, id)
+	if err != nil {
+		t.Fatal("Failed to get card", err)
+	}
+	return db.MergeCard(ctx, c, &model.Merge{
+		Id:         fmt.Sprint(id, "-", mergedInto, "-", time.Now().UnixNano()),
+		Created:    now(),
+		CardId:     id,
+		MergedInto: mergedInto,
+	})
+}
+
+func unmerge(t *testing.T, db Repository, id string) {
+	t.Helper()
+	ctx := context.Background()
+	c, err := db.GetCardById(ctx, id)
+	if err != nil {
+		t.Fatal("Failed to get card", err)
+	}
+	if err := db.UnmergeCard(ctx, c); err != nil {
+		t.Fatal("Failed to unmerge card", err)
+	}
+}
+
+func testRetrospectives(t *testing.T, db Repository) {
+	ctx := context.Background()
+	r := newRetro(t, db, "retro")
+
+	got, err := db.GetRetrospectiveById(ctx, "retro")
+	if err != nil {
+		t.Fatal("Failed to get retrospective", err)
+	}
+	if got.Name != r.Name || got.PetName != r.PetName || !got.Created.Equal(r.Created) || got.Closed != nil {
+		t.Fatal("Unexpected retrospective:", got)
+	}
+
+	closed := now()
+	r.Name = "Renamed"
+	r.Team = "team"
+	r.Closed = &closed
+	if err := db.UpdateRetrospective(ctx, r); err != nil {
+		t.Fatal("Failed to update retrospective", err)
+	}
+	got, _ = db.GetRetrospectiveById(ctx, "retro")
+	if got.Name != "Renamed" || got.Team != "team" || got.Closed == nil || !got.Closed.Equal(closed) {
+		t.Fatal("Retrospective not updated:", got)
+	}
+
+	if _, err := db.GetRetrospectiveById(ctx, "missing"); err == nil {
+		t.Fatal("Expected error for missing retrospective")
+	}
+}
+
+func testPetNames(t *testing.T, db Repository) {
+	ctx := context.Background()
+	r := newRetro(t, db, "retro")
+
+	got, err := db.GetRetrospectiveByPetName(ctx, r.PetName)
+	if err != nil || got.Id != r.Id {
+		t.Fatal("Failed to get retrospective by pet name", got, err)
+	}
+	if _, err := db.GetRetrospectiveByPetName(ctx, "missing"); err == nil {
+		t.Fatal("Expected error for missing pet name")
+	}
+
+	err = db.NewRetrospective(ctx, &model.Retrospective{Id: "other", Created: now(), Updated: now(), PetName: r.PetName})
+	if err == nil {
+		t.Fatal("Expected pet names to be unique")
+	}
+}
+
+func testCardPositions(t *testing.T, db Repository) {
+	ctx := context.Background()
+	newRetro(t, db, "retro")
+	cs := newCards(t, db, "retro", "Mixed", 4)
+
+	// New cards go on top
+	expectIds(t, "new cards first", []string{cs[3], cs[2], cs[1], cs[0]}, column(t, db, "retro", "Mixed"))
+
+	move(t, db, cs[3], "Mixed", 2)
+	expectIds(t, "card moved down", []string{cs[2], cs[1], cs[3], cs[0]}, column(t, db, "retro", "Mixed"))
+
+	move(t, db, cs[0], "Mixed", 0)
+	expectIds(t, "card moved to top", []string{cs[0], cs[2], cs[1], cs[3]}, column(t, db, "retro", "Mixed"))
+
+	move(t, db, cs[2], "Mixed", 100)
+	expectIds(t, "card moved past the end", []string{cs[0], cs[1], cs[3], cs[2]}, column(t, db, "retro", "Mixed"))
+
+	move(t, db, cs[1], "Good", 0)
+	expectIds(t, "card left in column", []string{cs[0], cs[3], cs[2]}, column(t, db, "retro", "Mixed"))
+	expectIds(t, "card in new column", []string{cs[1]}, column(t, db, "retro", "Good"))
+
+	// Swap back and forth a couple of times, which halves the gap each time
+	for i := 0; i < 10; i++ {
+		cards := column(t, db, "retro", "Mixed")
+		move(t, db, cards[0], "Mixed", 1)
+		expectIds(t, fmt.Sprint("swapped cards after ", i, " swaps"), []string{cards[1], cards[0], cards[2]}, column(t, db, "retro", "Mixed"))
+	}
+
+	c, _ := db.GetCardById(ctx, cs[0])
+	if err := db.MoveCard(ctx, c, "Mixed", -1); err == nil {
+		t.Fatal("Expected moving to a negative index to fail")
+	}
+}
+
+// testConcurrentMoves has many users move cards around the same columns at
+// once, which must neither lose moves nor leave cards on the same position.
+func testConcurrentMoves(t *testing.T, db Repository) {
+	ctx := context.Background()
+	newRetro(t, db, "retro")
+	cs := newCards(t, db, "retro", "A", 10)
+	columns := []string{"A", "B"}
+
+	var wg sync.WaitGroup
+	lastColumn := make([]string, len(cs))
+	for i := range cs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(i)))
+			for n := 0; n < 30; n++ {
+				c, err := db.GetCardById(ctx, cs[i])
+				if err != nil {
+					t.Error("Failed to get card", err)
+					return
+				}
+				column := columns[r.Intn(len(columns))]
+				if err := db.MoveCard(ctx, c, column, r.Intn(len(cs))); err != nil {
+					t.Error("Failed to move card", err)
+					return
+				}
+				lastColumn[i] = column
+			}
+		}(i)
+	}
+	wg.Wait()
+	if t.Failed() {
+		return
+	}
+
+	cards, _ := db.GetCardsByRetrospectiveId(ctx, "retro")
+	if len(cards) != len(cs) {
+		t.Fatal("Expected", len(cs), "cards, got:", len(cards))
+	}
+	positions := map[string]bool{}
+	for _, c := range cards {
+		i := sort.SearchStrings(cs, c.Id)
+		if c.Column != lastColumn[i] {
+			t.Error("Lost move of", c.Id, "expected column", lastColumn[i], "got:", c.Column)
+		}
+		key := fmt.Sprint(c.Column, "/", c.Position)
+		if positions[key] {
+			t.Error("Duplicate position", key)
+		}
+		positions[key] = true
+	}
+
+	// Squeezing cards between the same neighbours runs out of room quickly
+	for i := 0; i < 40; i++ {
+		a := column(t, db, "retro", "A")
+		if len(a) < 3 {
+			break
+		}
+		move(t, db, a[len(a)-1], "A", 1)
+		expectIds(t, fmt.Sprint("card order after ", i, " moves"), append([]string{a[0], a[len(a)-1]}, a[1:len(a)-1]...), column(t, db, "retro", "A"))
+	}
+}
+
+func testVersions(t *testing.T, db Repository) {
+	ctx := context.Background()
+	newRetro(t, db, "retro")
+	cs := newCards(t, db, "retro", "Mixed", 2)
+
+	first, _ := db.GetCardById(ctx, cs[0])
+	second, _ := db.GetCardById(ctx, cs[0])
+	if first.Version != 0 {
+		t.Fatal("Expected new card at version 0, got:", first.Version)
+	}
+
+	first.Message = "first"
+	claimed := now()
+	first.ExportClaimed = &claimed
+	if err := db.UpdateCard(ctx, first); err != nil {
+		t.Fatal("Failed to update card", err)
+	}
+	if first.Version != 1 {
+		t.Fatal("Expected update to bump the version, got:", first.Version)
+	}
+	if stored, _ := db.GetCardById(ctx, cs[0]); stored.ExportClaimed == nil || !stored.ExportClaimed.Equal(claimed) {
+		t.Fatal("Expected the export claim to be stored, got:", stored.ExportClaimed)
+	}
+
+	second.Message = "second"
+	err := db.UpdateCard(ctx, second)
+	conflict, ok := err.(*model.ConflictError)
+	if !ok {
+		t.Fatal("Expected conflict for outdated card, got:", err)
+	}
+	if conflict.Card.Message != "first" || conflict.Card.Version != 1 {
+		t.Fatal("Expected conflict to carry the current card, got:", conflict.Card)
+	}
+
+	if err := db.MoveCard(ctx, second, "Mixed", 1); err == nil {
+		t.Fatal("Expected moving an outdated card to fail")
+	}
+	if err := db.MoveCard(ctx, first, "Mixed", 1); err != nil {
+		t.Fatal("Failed to move card", err)
+	}
+	c, _ := db.GetCardById(ctx, cs[0])
+	if c.Version != 2 || first.Version != 2 || c.Message != "first" {
+		t.Fatal("Expected move to bump the version, got:", c.Version, first.Version)
+	}
+}
+
+func testMergeTrees(t *testing.T, db Repository) {
+	ctx := context.Background()
+	newRetro(t, db, "retro")
+	cs := newCards(t, db, "retro", "Mixed", 3)
+
+	if err := merge(t, db, cs[1], cs[0]); err != nil {
+		t.Fatal("Failed to merge card", err)
+	}
+	a, _ := db.GetCardById(ctx, cs[0])
+	expectIds(t, "merged cards", []string{cs[1]}, ids(a.MergedCards))
+	expectIds(t, "top level cards", []string{cs[2], cs[0]}, column(t, db, "retro", "Mixed"))
+
+	// Merging a card keeps the cards merged into it, nested below it
+	if err := merge(t, db, cs[0], cs[2]); err != nil {
+		t.Fatal("Failed to merge card", err)
+	}
+	c, _ := db.GetCardById(ctx, cs[2])
+	expectIds(t, "group", []string{cs[2], cs[0], cs[1]}, ids(c.Group()))
+	cards, _ := db.GetCardsByRetrospectiveId(ctx, "retro")
+	if len(cards) != 1 || len(cards[0].MergedCards) != 1 || len(cards[0].MergedCards[0].MergedCards) != 1 {
+		t.Fatal("Expected a single card with 2 levels of merged cards, got:", cards)
+	}
+
+	b, _ := db.GetCardById(ctx, cs[1])
+	if b.MergedInto == nil || *b.MergedInto != cs[0] {
+		t.Fatal("Expected card to be merged into", cs[0], "got:", b.MergedInto)
+	}
+	if err := db.UnmergeCard(ctx, b); err != nil {
+		t.Fatal("Failed to unmerge card", err)
+	}
+	b, _ = db.GetCardById(ctx, cs[1])
+	if b.MergedInto != nil {
+		t.Fatal("Expected card to be unmerged, got:", *b.MergedInto)
+	}
+	if err := db.UnmergeCard(ctx, b); err == nil {
+		t.Fatal("Expected unmerging an unmerged card to fail")
+	}
+	c, _ = db.GetCardById(ctx, cs[2])
+	expectIds(t, "group", []string{cs[2], cs[0]}, ids(c.Group()))
+	expectIds(t, "top level cards", []string{cs[2], cs[1]}, column(t, db, "retro", "Mixed"))
+}
+
+func testUnmergeAll(t *testing.T, db Repository) {
+	ctx := context.Background()
+	newRetro(t, db, "retro")
+	cs := newCards(t, db, "retro", "Mixed", 4)
+	for _, m := range [][2]string{{cs[2], cs[1]}, {cs[1], cs[0]}} {
+		if err := merge(t, db, m[0], m[1]); err != nil {
+			t.Fatal("Failed to merge card", err)
+		}
+	}
+	head, _ := db.GetCardById(ctx, cs[0])
+	title := "Title"
+	head.MergeTitle = &title
+	if err := db.UpdateCard(ctx, head); err != nil {
+		t.Fatal("Failed to set merge title", err)
+	}
+	expectIds(t, "group", []string{cs[0], cs[1], cs[2]}, ids(head.Group()))
+
+	if err := db.UnmergeAll(ctx, head); err != nil {
+		t.Fatal("Failed to unmerge all cards", err)
+	}
+	expectIds(t, "top level cards", []string{cs[3], cs[2], cs[1], cs[0]}, column(t, db, "retro", "Mixed"))
+	stored, _ := db.GetCardById(ctx, cs[0])
+	if len(stored.MergedCards) != 0 || stored.MergeTitle != nil {
+		t.Fatal("Expected merge group to be gone along with its title, got:", stored)
+	}
+	if head.Version != stored.Version || head.MergeTitle != nil {
+		t.Fatal("Expected card to be updated, got:", head)
+	}
+	for _, id := range cs[1:3] {
+		ms, _ := db.GetMergesByCardId(ctx, id)
+		if len(ms) != 1 || ms[0].Unmerged == nil {
+			t.Fatal("Expected merge to be closed, got:", ms)
+		}
+	}
+	if err := db.UnmergeAll(ctx, stored); model.Code(err) != model.CodeValidation {
+		t.Fatal("Expected unmerging a card without merged cards to fail, got:", err)
+	}
+}
+
+func testMergeChecks(t *testing.T, db Repository) {
+	ctx := context.Background()
+	newRetro(t, db, "retro")
+	newRetro(t, db, "other")
+	cs := newCards(t, db, "retro", "Mixed", 4)
+	others := newCards(t, db, "other", "Mixed", 1)
+
+	expectInputError := func(what string, err error) {
+		t.Helper()
+		if _, ok := err.(model.InputError); !ok {
+			t.Fatal("Expected", what, "to be refused, got:", err)
+		}
+	}
+
+	expectInputError("merging a card into itself", merge(t, db, cs[0], cs[0]))
+	expectInputError("merging across retrospectives", merge(t, db, others[0], cs[0]))
+
+	if err := merge(t, db, cs[1], cs[0]); err != nil {
+		t.Fatal("Failed to merge card", err)
+	}
+	expectInputError("merging a card into its merged card", merge(t, db, cs[0], cs[1]))
+
+	// Merging into the same card again changes nothing
+	if err := merge(t, db, cs[1], cs[0]); err != nil {
+		t.Fatal("Failed to merge card again", err)
+	}
+	if ms, _ := db.GetMergesByCardId(ctx, cs[1]); len(ms) != 1 {
+		t.Fatal("Expected a single merge, got:", len(ms))
+	}
+
+	if err := merge(t, db, cs[2], cs[1]); err != nil {
+		t.Fatal("Failed to merge card", err)
+	}
+	expectInputError("nesting too deeply", merge(t, db, cs[3], cs[2]))
+	expectInputError("nesting a group too deeply", merge(t, db, cs[0], cs[3]))
+
+	cards, _ := db.GetCardsByRetrospectiveId(ctx, "retro")
+	if len(cards) != 2 {
+		t.Fatal("Expected refused merges to change nothing, got:", cards)
+	}
+}
+
+func testMergeHistory(t *testing.T, db Repository) {
+	ctx := context.Background()
+	newRetro(t, db, "retro")
+	cs := newCards(t, db, "retro", "Mixed", 3)
+	good := newCards(t, db, "retro", "Good", 1)[0]
+	before, _ := db.GetCardById(ctx, cs[2])
+
+	for _, id := range []string{cs[2], cs[1]} {
+		if err := merge(t, db, id, good); err != nil {
+			t.Fatal("Failed to merge card", err)
+		}
+	}
+	g, _ := db.GetCardById(ctx, good)
+	expectIds(t, "merged cards", []string{cs[2], cs[1]}, ids(g.MergedCards))
+
+	ms, err := db.GetMergesByCardId(ctx, cs[2])
+	if err != nil {
+		t.Fatal("Failed to get merges", err)
+	}
+	if len(ms) != 1 || ms[0].MergedInto != good || ms[0].Column != "Mixed" || ms[0].Position != before.Position || ms[0].Unmerged != nil {
+		t.Fatal("Expected merge to record where the card was, got:", ms)
+	}
+
+	// Merged cards are ordered within their group
+	move(t, db, cs[1], "Good", 0)
+	g, _ = db.GetCardById(ctx, good)
+	expectIds(t, "merged cards", []string{cs[1], cs[2]}, ids(g.MergedCards))
+	expectIds(t, "top level cards", []string{good}, column(t, db, "retro", "Good"))
+
+	title := "Flaky tests"
+	g.MergeTitle = &title
+	if err := db.UpdateCard(ctx, g); err != nil {
+		t.Fatal("Failed to set title", err)
+	}
+
+	// Moving a card to another group keeps where it came from
+	if err := merge(t, db, cs[2], cs[0]); err != nil {
+		t.Fatal("Failed to merge card", err)
+	}
+	ms, _ = db.GetMergesByCardId(ctx, cs[2])
+	if len(ms) != 2 || ms[0].Unmerged == nil || ms[1].MergedInto != cs[0] || ms[1].Column != "Mixed" || ms[1].Position != before.Position {
+		t.Fatal("Expected merge history to carry over, got:", ms)
+	}
+
+	unmerge(t, db, cs[2])
+	expectIds(t, "top level cards", []string{cs[2], cs[0]}, column(t, db, "retro", "Mixed"))
+	unmerge(t, db, cs[1])
+	expectIds(t, "top level cards", []string{cs[2], cs[1], cs[0]}, column(t, db, "retro", "Mixed"))
+
+	g, _ = db.GetCardById(ctx, good)
+	if g.MergeTitle != nil {
+		t.Fatal("Expected title to go with the last merged card, got:", *g.MergeTitle)
+	}
+	ms, _ = db.GetMergesByCardId(ctx, cs[2])
+	if ms[1].Unmerged == nil {
+		t.Fatal("Expected unmerge to be recorded")
+	}
+}
+
+// members returns the ids of the cards in a group, in order.
+func members(t *testing.T, db Repository, rId string, groupId string) []string {
+	t.Helper()
+	ctx := context.Background()
+	cards, err := db.GetCardsByRetrospectiveId(ctx, rId)
+	if err != nil {
+		t.Fatal("Failed to get cards", err)
+	}
+	ids := []string{}
+	for _, c := range cards {
+		if c.GroupId != nil && *c.GroupId == groupId {
+			ids = append(ids, c.Id)
+		}
+	}
+	return ids
+}
+
+func moveToGroup(t *testing.T, db Repository, id string, groupId *string, column string, index int) {
+	t.Helper()
+	ctx := context.Background()
+	c, err := db.GetCardById(ctx, id)
+	if err != nil {
+		t.Fatal("Failed to get card", err)
+	}
+	c.GroupId = groupId
+	if err := db.MoveCard(ctx, c, column, index); err != nil {
+		t.Fatal("Failed to move card", err)
+	}
+}
+
+func testCardGroups(t *testing.T, db Repository) {
+	ctx := context.Background()
+	newRetro(t, db, "retro")
+	cs := newCards(t, db, "retro", "Mixed", 4)
+
+	groups := map[string]*model.CardGroup{}
+	for _, g := range []struct{ column, name string }{{"Mixed", "first"}, {"Mixed", "second"}, {"Mixed", "third"}, {"Good", "elsewhere"}} {
+		group := &model.CardGroup{Id: g.name, Created: now(), Updated: now(), RetrospectiveId: "retro", Column: g.column, Name: g.name}
+		if err := db.NewCardGroup(ctx, group); err != nil {
+			t.Fatal("Failed to create group", err)
+		}
+		groups[g.name] = group
+	}
+	groupIds := func() []string {
+		gs, err := db.GetCardGroupsByRetrospectiveId(ctx, "retro")
+		if err != nil {
+			t.Fatal("Failed to get groups", err)
+		}
+		ids := []string{}
+		for _, g := range gs {
+			ids = append(ids, g.Id)
+		}
+		return ids
+	}
+	expectIds(t, "groups", []string{"elsewhere", "first", "second", "third"}, groupIds())
+
+	if err := db.MoveCardGroup(ctx, groups["third"], 0); err != nil {
+		t.Fatal("Failed to move group", err)
+	}
+	expectIds(t, "groups", []string{"elsewhere", "third", "first", "second"}, groupIds())
+
+	groups["first"].Name = "renamed"
+	if err := db.UpdateCardGroup(ctx, groups["first"]); err != nil {
+		t.Fatal("Failed to rename group", err)
+	}
+	if g, _ := db.GetCardGroupById(ctx, "first"); g.Name != "renamed" {
+		t.Fatal("Expected group to be renamed, got:", g.Name)
+	}
+
+	first := "first"
+	moveToGroup(t, db, cs[0], &first, "Mixed", 0)
+	moveToGroup(t, db, cs[1], &first, "Mixed", 1)
+	expectIds(t, "group members", []string{cs[0], cs[1]}, members(t, db, "retro", "first"))
+	moveToGroup(t, db, cs[1], &first, "Mixed", 0)
+	expectIds(t, "group members", []string{cs[1], cs[0]}, members(t, db, "retro", "first"))
+
+	// Cards outside of groups are ordered among themselves
+	moveToGroup(t, db, cs[0], nil, "Mixed", 1)
+	expectIds(t, "group members", []string{cs[1]}, members(t, db, "retro", "first"))
+	expectIds(t, "top level cards", []string{cs[3], cs[0], cs[2]}, without(column(t, db, "retro", "Mixed"), cs[1]))
+
+	if err := db.DeleteCardGroup(ctx, groups["first"]); err != nil {
+		t.Fatal("Failed to dissolve group", err)
+	}
+	if _, err := db.GetCardGroupById(ctx, "first"); err == nil {
+		t.Fatal("Expected dissolved group to be gone")
+	}
+	if err := db.DeleteCardGroup(ctx, groups["first"]); err == nil {
+		t.Fatal("Expected dissolving a missing group to fail")
+	}
+	c, _ := db.GetCardById(ctx, cs[1])
+	if c.GroupId != nil {
+		t.Fatal("Expected card to leave the dissolved group, got:", *c.GroupId)
+	}
+	cards, _ := db.GetCardsByRetrospectiveId(ctx, "retro")
+	positions := map[int]bool{}
+	for _, c := range cards {
+		if positions[c.Position] {
+			t.Fatal("Expected distinct positions after dissolving a group, got:", cards)
+		}
+		positions[c.Position] = true
+	}
+}
+
+func without(ids []string, id string) []string {
+	res := []string{}
+	for _, other := range ids {
+		if other != id {
+			res = append(res, other)
+		}
+	}
+	return res
+}
+
+func testVotes(t *testing.T, db Repository) {
+	ctx := context.Background()
+	newRetro(t, db, "retro")
+	cs := newCards(t, db, "retro", "Mixed", 1)
+
+	vote := &model.Vote{Id: "vote", Created: now(), Updated: now(), CardId: cs[0], Voter: "voter", Emoji: "clap", Count: 1}
+	for i := 0; i < 3; i++ {
+		if err := db.NewVote(ctx, vote); err != nil {
+			t.Fatal("Failed to vote", err)
+		}
+	}
+	db.NewVote(ctx, &model.Vote{Id: "other", Created: now(), Updated: now(), CardId: cs[0], Voter: "voter", Emoji: "rocket", Count: 1})
+	db.NewVote(ctx, &model.Vote{Id: "another", Created: now(), Updated: now(), CardId: cs[0], Voter: "other voter", Emoji: "clap", Count: 1})
+
+	v, err := db.GetVoteByCardIdAndVoterAndEmoji(ctx, cs[0], "voter", "clap")
+	if err != nil {
+		t.Fatal("Failed to get vote", err)
+	}
+	if v.Count != 3 {
+		t.Fatal("Expected repeated votes to count up to 3, got:", v.Count)
+	}
+	if _, err := db.GetVoteByCardIdAndVoterAndEmoji(ctx, cs[0], "voter", "tada"); err == nil {
+		t.Fatal("Expected error for missing vote")
+	}
+
+	votes, _ := db.GetVotesByCardId(ctx, cs[0])
+	if len(votes) != 3 {
+		t.Fatal("Expected 3 votes, got:", len(votes))
+	}
+	if n, _ := db.GetTotalUniqueEmojis(ctx, cs[0]); n != 2 {
+		t.Fatal("Expected 2 unique emojis, got:", n)
+	}
+	if votes, _ := db.GetVotesByCardId(ctx, "missing"); len(votes) != 0 {
+		t.Fatal("Expected no votes for missing card, got:", votes)
+	}
+}
+
+func testStatusHistory(t *testing.T, db Repository) {
+	ctx := context.Background()
+	newRetro(t, db, "retro")
+	cs := newCards(t, db, "retro", "Mixed", 1)
+
+	types := []model.StatusType{model.InProgress, model.Discussed, model.Archived}
+	for i, st := range types {
+		s := &model.Status{Id: fmt.Sprint("status-", i), Created: now().Add(time.Duration(i) * time.Second), CardId: cs[0], Type: st}
+		if err := db.NewStatus(ctx, s); err != nil {
+			t.Fatal("Failed to create status", err)
+		}
+	}
+
+	s, err := db.GetStatusById(ctx, "status-1")
+	if err != nil || s.Type != model.Discussed || s.CardId != cs[0] {
+		t.Fatal("Failed to get status", s, err)
+	}
+
+	statuses, _ := db.GetStatusesByCardId(ctx, cs[0])
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Created.Before(statuses[j].Created)
+	})
+	if len(statuses) != len(types) {
+		t.Fatal("Expected the full status history, got:", statuses)
+	}
+	for i, s := range statuses {
+		if s.Type != types[i] {
+			t.Fatal("Unexpected status history:", statuses)
+		}
+	}
+}
+
+func closeRetro(t *testing.T, db Repository, r *model.Retrospective, team string, closed time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	r.Team = team
+	r.Closed = &closed
+	if err := db.UpdateRetrospective(ctx, r); err != nil {
+		t.Fatal("Failed to close retrospective", err)
+	}
+}
+
+func testArchives(t *testing.T, db Repository) {
+	ctx := context.Background()
+	old := newRetro(t, db, "old")
+	recent := newRetro(t, db, "recent")
+	newRetro(t, db, "open")
+	closeRetro(t, db, old, "team", now().AddDate(0, 0, -10))
+	closeRetro(t, db, recent, "team", now().AddDate(0, 0, -1))
+
+	cs := newCards(t, db, "old", "Mixed", 3)
+	kept := newCards(t, db, "recent", "Mixed", 1)
+	if err := merge(t, db, cs[1], cs[0]); err != nil {
+		t.Fatal("Failed to merge card", err)
+	}
+	g := &model.CardGroup{Id: "group", Created: now(), Updated: now(), RetrospectiveId: "old", Column: "Mixed", Name: "Group"}
+	if err := db.NewCardGroup(ctx, g); err != nil {
+		t.Fatal("Failed to create group", err)
+	}
+	db.NewVote(ctx, &model.Vote{Id: "vote", Created: now(), Updated: now(), CardId: cs[0], Voter: "voter", Emoji: "clap", Count: 1})
+	db.NewVote(ctx, &model.Vote{Id: "kept", Created: now(), Updated: now(), CardId: kept[0], Voter: "voter", Emoji: "clap", Count: 1})
+	db.NewStatus(ctx, &model.Status{Id: "status", Created: now(), CardId: cs[2], Type: model.Discussed})
+	db.Observe(ctx, "conn", "alice", "old", "Visible")
+
+	closed, err := db.GetClosedRetrospectives(ctx, now().AddDate(0, 0, -5))
+	if err != nil {
+		t.Fatal("Failed to get closed retrospectives", err)
+	}
+	if len(closed) != 1 || closed[0].Id != "old" {
+		t.Fatal("Expected only the old retrospective to be due, got:", closed)
+	}
+	if closed, _ := db.GetClosedRetrospectives(ctx, now()); len(closed) != 2 || closed[0].Id != "old" {
+		t.Fatal("Expected closed retrospectives oldest first, got:", closed)
+	}
+
+	s, err := db.GetSnapshot(ctx, "old")
+	if err != nil {
+		t.Fatal("Failed to get snapshot", err)
+	}
+	if len(s.Cards) != 3 || len(s.Groups) != 1 || len(s.Merges) != 1 || len(s.Votes) != 1 || len(s.Statuses) != 1 {
+		t.Fatal("Unexpected snapshot:", s)
+	}
+
+	a := &model.Archive{Id: "old", Created: now(), Name: old.Name, PetName: old.PetName, Team: "team", Closed: *old.Closed}
+	if err := db.ArchiveRetrospective(ctx, a); err != nil {
+		t.Fatal("Failed to archive retrospective", err)
+	}
+
+	if _, err := db.GetRetrospectiveById(ctx, "old"); err == nil {
+		t.Fatal("Expected archived retrospective to be gone")
+	}
+	if _, err := db.GetSnapshot(ctx, "old"); model.Code(err) != model.CodeNotFound {
+		t.Fatal("Expected no snapshot of an archived retrospective, got:", err)
+	}
+	if cards, _ := db.GetCardsByRetrospectiveId(ctx, "old"); len(cards) != 0 {
+		t.Fatal("Expected cards to be purged, got:", cards)
+	}
+	if votes, _ := db.GetVotesByCardId(ctx, cs[0]); len(votes) != 0 {
+		t.Fatal("Expected votes to be purged, got:", votes)
+	}
+	if statuses, _ := db.GetStatusesByCardId(ctx, cs[2]); len(statuses) != 0 {
+		t.Fatal("Expected statuses to be purged, got:", statuses)
+	}
+	if merges, _ := db.GetMergesByCardId(ctx, cs[1]); len(merges) != 0 {
+		t.Fatal("Expected merges to be purged, got:", merges)
+	}
+	if groups, _ := db.GetCardGroupsByRetrospectiveId(ctx, "old"); len(groups) != 0 {
+		t.Fatal("Expected groups to be purged, got:", groups)
+	}
+	if users, _ := db.GetActiveUsers(ctx, "old"); len(users) != 0 {
+		t.Fatal("Expected observations to be purged, got:", users)
+	}
+	expectIds(t, "other cards", kept, column(t, db, "recent", "Mixed"))
+	if votes, _ := db.GetVotesByCardId(ctx, kept[0]); len(votes) != 1 {
+		t.Fatal("Expected other votes to be kept, got:", votes)
+	}
+
+	stored, err := db.GetArchiveById(ctx, "old")
+	if err != nil {
+		t.Fatal("Failed to get archive", err)
+	}
+	if stored.PetName != old.PetName || !stored.Closed.Equal(*old.Closed) {
+		t.Fatal("Unexpected archive:", stored)
+	}
+	// The snapshot is taken along with the purge
+	s, err = model.DecompressSnapshot(bytes.NewReader(stored.Snapshot))
+	if err != nil {
+		t.Fatal("Failed to read snapshot", err)
+	}
+	if s.Retrospective.Id != "old" || len(s.Cards) != 3 || len(s.Groups) != 1 || len(s.Merges) != 1 || len(s.Votes) != 1 || len(s.Statuses) != 1 {
+		t.Fatal("Expected snapshot to hold everything, got:", s)
+	}
+	missing := &model.Archive{Id: "missing", Created: now(), Closed: now()}
+	if err := db.ArchiveRetrospective(ctx, missing); model.Code(err) != model.CodeNotFound {
+		t.Fatal("Expected archiving a missing retrospective to fail, got:", err)
+	}
+
+	// Archiving again replaces the archive
+	a.Snapshot = []byte("again")
+	if err := db.ArchiveRetrospective(ctx, a); err != nil {
+		t.Fatal("Failed to archive retrospective again", err)
+	}
+	if stored, _ := db.GetArchiveById(ctx, "old"); string(stored.Snapshot) != "again" {
+		t.Fatal("Expected archive to be replaced, got:", string(stored.Snapshot))
+	}
+
+	archives, err := db.GetArchivesByTeam(ctx, "team")
+	if err != nil {
+		t.Fatal("Failed to list archives", err)
+	}
+	if len(archives) != 1 || archives[0].Id != "old" || archives[0].Snapshot != nil {
+		t.Fatal("Expected archive to be listed without snapshot, got:", archives)
+	}
+	if archives, _ := db.GetArchivesByTeam(ctx, "other"); len(archives) != 0 {
+		t.Fatal("Expected no archives for other team, got:", archives)
+	}
+}
+
+func testObservations(t *testing.T, db Repository) {
+	ctx := context.Background()
+	if changed, err := db.Observe(ctx, "conn-1", "alice", "retro", "Visible"); err != nil || !changed {
+		t.Fatal("Expected first observation to be a change", changed, err)
+	}
+	if changed, _ := db.Observe(ctx, "conn-1", "alice", "retro", "Visible"); changed {
+		t.Fatal("Expected same state not to be a change")
+	}
+	if changed, _ := db.Observe(ctx, "conn-1", "alice", "retro", "Hidden"); !changed {
+		t.Fatal("Expected new state to be a change")
+	}
+	db.Observe(ctx, "conn-2", "alice", "retro", "Visible")
+	db.Observe(ctx, "conn-3", "bob", "retro", "Hidden")
+	db.Observe(ctx, "conn-4", "carol", "other", "Visible")
+
+	users, err := db.GetActiveUsers(ctx, "retro")
+	if err != nil {
+		t.Fatal("Failed to get active users", err)
+	}
+	expected := []model.UserState{{User: "alice", State: model.Visible}, {User: "bob", State: model.Hidden}}
+	if fmt.Sprint(users) != fmt.Sprint(expected) {
+		t.Fatal("Expected", expected, "got:", users)
+	}
+
+	db.ClearObservations(ctx, "conn-3")
+	if users, _ := db.GetActiveUsers(ctx, "retro"); len(users) != 1 {
+		t.Fatal("Expected cleared connection to be gone, got:", users)
+	}
+}
+
+func testStaleObservations(t *testing.T, db Repository) {
+	ctx := context.Background()
+	db.Observe(ctx, "conn-1", "alice", "retro", "Visible")
+	db.Observe(ctx, "conn-2", "bob", "retro", "Visible")
+
+	if n, err := db.PurgeObservations(ctx, time.Now().Add(-time.Minute)); err != nil || n != 0 {
+		t.Fatal("Expected live observations to be kept, got:", n, err)
+	}
+	if n, err := db.PurgeObservations(ctx, time.Now().Add(time.Minute)); err != nil || n != 2 {
+		t.Fatal("Expected stale observations to be purged, got:", n, err)
+	}
+	if changed, _ := db.Observe(ctx, "conn-1", "alice", "retro", "Visible"); !changed {
+		t.Fatal("Expected purged connection to be observed anew")
+	}
+}
+
+func testCancellation(t *testing.T, db Repository) {
+	r := newRetro(t, db, "r1")
+	newCards(t, db, r.Id, "Good", 2)
+	before := column(t, db, r.Id, "Good")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := db.GetRetrospectiveById(ctx, r.Id); !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected lookup to be cancelled, got:", err)
+	}
+	c := &model.Card{Id: "c", Created: now(), Updated: now(), RetrospectiveId: r.Id, Message: "Card", Column: "Good"}
+	if err := db.NewCard(ctx, c); !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected new card to be cancelled, got:", err)
+	}
+	card, err := db.GetCardById(context.Background(), before[1])
+	if err != nil {
+		t.Fatal("Failed to get card", err)
+	}
+	if err := db.MoveCard(ctx, card, "Good", 0); !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected move to be cancelled, got:", err)
+	}
+	expectIds(t, "cards", before, column(t, db, r.Id, "Good"))
+}
+
+func testNotFound(t *testing.T, db Repository) {
+	ctx := context.Background()
+	expectNotFound := func(what string, err error) {
+		t.Helper()
+		var notFound *model.NotFoundError
+		if !errors.As(err, &notFound) || !errors.Is(err, sql.ErrNoRows) {
+			t.Fatal("Expected", what, "not to be found, got:", err)
+		}
+	}
+
+	_, err := db.GetRetrospectiveById(ctx, "missing")
+	expectNotFound("retrospective", err)
+	_, err = db.GetCardById(ctx, "missing")
+	expectNotFound("card", err)
+	_, err = db.GetVoteByCardIdAndVoterAndEmoji(ctx, "missing", "alice", "tada")
+	expectNotFound("vote", err)
+	_, err = db.GetStatusById(ctx, "missing")
+	expectNotFound("status", err)
+}
+
+func testHealthcheck(t *testing.T, db Repository) {
+	if err := db.Healthcheck(context.Background()); err != nil {
+		t.Fatal("Expected repository to be healthy, got:", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := db.Healthcheck(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected healthcheck to be cancelled, got:", err)
+	}
+}
@@ -0,0 +1,113 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+func (db *inmemRepository) GetRetrospectives(ctx context.Context, after string, limit int) ([]*model.Retrospective, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rs := []*model.Retrospective{}
+	for _, r := range db.retrosById {
+		if r.Id > after {
+			rs = append(rs, copyRetrospective(r))
+		}
+	}
+	sort.Slice(rs, func(i, j int) bool {
+		return rs[i].Id < rs[j].Id
+	})
+	if len(rs) > limit {
+		rs = rs[:limit]
+	}
+	return rs, nil
+}
+
+func (db *inmemRepository) GetArchives(ctx context.Context, after string, limit int) ([]*model.Archive, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	as := []*model.Archive{}
+	for _, a := range db.archivesById {
+		if a.Id > after {
+			c := *a
+			c.Snapshot = append([]byte{}, a.Snapshot...)
+			as = append(as, &c)
+		}
+	}
+	sort.Slice(as, func(i, j int) bool {
+		return as[i].Id < as[j].Id
+	})
+	if len(as) > limit {
+		as = as[:limit]
+	}
+	return as, nil
+}
+
+func (db *inmemRepository) GetAllWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.webhooks(func(w *model.Webhook) bool {
+		return true
+	}), nil
+}
+
+func (db *inmemRepository) GetSnapshot(ctx context.Context, id string) (*model.Snapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.snapshot(id)
+}
+
+func (db *inmemRepository) RestoreSnapshot(ctx context.Context, s *model.Snapshot) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.retrosById[s.Retrospective.Id]; ok {
+		return model.InputError("retrospective already exists")
+	}
+	db.retrosById[s.Retrospective.Id] = copyRetrospective(s.Retrospective)
+	for _, card := range s.Cards {
+		c := copyCard(card)
+		db.cardsById[c.Id] = c
+		db.cards = append(db.cards, c)
+	}
+	for _, g := range s.Groups {
+		c := *g
+		db.groupsById[c.Id] = &c
+	}
+	for _, m := range s.Merges {
+		c := *m
+		db.merges = append(db.merges, &c)
+	}
+	for _, v := range s.Votes {
+		c := *v
+		db.votesById[c.Id] = &c
+		db.votes = append(db.votes, &c)
+	}
+	for _, st := range s.Statuses {
+		c := *st
+		db.statusesById[c.Id] = &c
+		db.statuses = append(db.statuses, &c)
+	}
+	return nil
+}
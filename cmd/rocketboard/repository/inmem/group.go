@@ -0,0 +1,161 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+// groups returns the groups of a column ordered by position, leaving out the
+// group with the given id. Must be called with the lock held.
+func (db *inmemRepository) groups(rId string, column string, except string) []*model.CardGroup {
+	gs := []*model.CardGroup{}
+	for _, g := range db.groupsById {
+		if g.RetrospectiveId == rId && g.Column == column && g.Id != except {
+			gs = append(gs, g)
+		}
+	}
+	sort.Slice(gs, func(i, j int) bool {
+		if gs[i].Position != gs[j].Position {
+			return gs[i].Position < gs[j].Position
+		}
+		return gs[i].Id < gs[j].Id
+	})
+	return gs
+}
+
+// NewCardGroup adds a group after the other groups of its column.
+func (db *inmemRepository) NewCardGroup(ctx context.Context, g *model.CardGroup) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	g.Position = IDX_SPACING
+	if gs := db.groups(g.RetrospectiveId, g.Column, ""); len(gs) > 0 {
+		g.Position = gs[len(gs)-1].Position + IDX_SPACING
+	}
+	c := *g
+	db.groupsById[g.Id] = &c
+	return nil
+}
+
+func (db *inmemRepository) UpdateCardGroup(ctx context.Context, g *model.CardGroup) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.groupsById[g.Id]
+	if !ok {
+		return &model.NotFoundError{Kind: "card group", Id: g.Id}
+	}
+	stored.Updated = g.Updated
+	stored.Name = g.Name
+	return nil
+}
+
+// MoveCardGroup moves a group to index among the other groups of its column.
+func (db *inmemRepository) MoveCardGroup(ctx context.Context, g *model.CardGroup, index int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if index < 0 {
+		return model.InputError("Cannot move to negative index")
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.groupsById[g.Id]
+	if !ok {
+		return &model.NotFoundError{Kind: "card group", Id: g.Id}
+	}
+
+	// Groups are ordered just like cards
+	gs := db.groups(stored.RetrospectiveId, stored.Column, stored.Id)
+	cs := make([]*model.Card, len(gs))
+	for i, other := range gs {
+		cs[i] = &model.Card{Position: other.Position}
+	}
+	g.Position = place(cs, index)
+	for i, other := range gs {
+		other.Position = cs[i].Position
+	}
+
+	stored.Updated = g.Updated
+	stored.Position = g.Position
+	return nil
+}
+
+// DeleteCardGroup dissolves a group, leaving its cards in its column.
+func (db *inmemRepository) DeleteCardGroup(ctx context.Context, g *model.CardGroup) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.groupsById[g.Id]
+	if !ok {
+		return &model.NotFoundError{Kind: "card group", Id: g.Id}
+	}
+	delete(db.groupsById, g.Id)
+	for _, c := range db.cards {
+		if c.GroupId != nil && *c.GroupId == g.Id {
+			c.GroupId = nil
+			c.Version++
+		}
+	}
+
+	// Cards were ordered within the group, so their positions may clash
+	// with the other cards of the column now
+	for i, c := range db.column(stored.RetrospectiveId, stored.Column, "") {
+		c.Position = IDX_SPACING * (i + 1)
+	}
+	return nil
+}
+
+func (db *inmemRepository) GetCardGroupById(ctx context.Context, id string) (*model.CardGroup, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	g, ok := db.groupsById[id]
+	if !ok {
+		return nil, &model.NotFoundError{Kind: "card group", Id: id}
+	}
+	c := *g
+	return &c, nil
+}
+
+func (db *inmemRepository) GetCardGroupsByRetrospectiveId(ctx context.Context, id string) ([]*model.CardGroup, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	gs := []*model.CardGroup{}
+	for _, g := range db.groupsById {
+		if g.RetrospectiveId == id {
+			c := *g
+			gs = append(gs, &c)
+		}
+	}
+	sort.Slice(gs, func(i, j int) bool {
+		if gs[i].Column != gs[j].Column {
+			return gs[i].Column < gs[j].Column
+		}
+		if gs[i].Position != gs[j].Position {
+			return gs[i].Position < gs[j].Position
+		}
+		return gs[i].Id < gs[j].Id
+	})
+	return gs, nil
+}
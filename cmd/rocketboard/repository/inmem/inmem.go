@@ -1,139 +1,763 @@
 package inmem
 
 import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
 	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
-	"github.com/pkg/errors"
 )
 
+// Space elements by 2^15, like the sql repository
+var IDX_SPACING = int(math.Exp2(15))
+
+// inmemRepository keeps everything in memory, for development and tests.
+// Entities are copied on the way in and out, so that callers can't change
+// stored state without going through the repository, just like with a
+// database. Missing entities are reported as model.NotFoundError.
 type inmemRepository struct {
-	retrosById             map[string]*model.Retrospective
-	cardsById              map[string]*model.Card
-	cardsByRetrospectiveId map[string][]*model.Card
+	mu sync.RWMutex
+
+	retrosById map[string]*model.Retrospective
+	cardsById  map[string]*model.Card
+	cards      []*model.Card
+
+	votesById map[string]*model.Vote
+	votes     []*model.Vote
+
+	statusesById map[string]*model.Status
+	statuses     []*model.Status
+
+	merges []*model.Merge
 
-	votesById     map[string]*model.Vote
-	votesByCardId map[string][]*model.Vote
+	groupsById map[string]*model.CardGroup
 
-	statusesById     map[string]*model.Status
-	statusesByCardId map[string][]*model.Status
+	teamsByName map[string]*model.Team
+
+	webhooksById   map[string]*model.Webhook
+	deliveriesById map[string]*model.WebhookDelivery
+	attemptsById   map[string]*model.WebhookAttempt
+
+	observationsByConnectionId map[string]*model.Observation
+
+	archivesById map[string]*model.Archive
+
+	// Retrospectives hold at most MaxCards cards, and users are present in
+	// a retrospective for PresenceWindow after they were last seen.
+	MaxCards       int
+	PresenceWindow time.Duration
 }
 
 func NewRepository() *inmemRepository {
-	return &inmemRepository{}
+	return &inmemRepository{
+		retrosById:                 map[string]*model.Retrospective{},
+		cardsById:                  map[string]*model.Card{},
+		votesById:                  map[string]*model.Vote{},
+		statusesById:               map[string]*model.Status{},
+		groupsById:                 map[string]*model.CardGroup{},
+		teamsByName:                map[string]*model.Team{},
+		webhooksById:               map[string]*model.Webhook{},
+		deliveriesById:             map[string]*model.WebhookDelivery{},
+		attemptsById:               map[string]*model.WebhookAttempt{},
+		observationsByConnectionId: map[string]*model.Observation{},
+		archivesById:               map[string]*model.Archive{},
+		MaxCards:                   100,
+		PresenceWindow:             10 * time.Second,
+	}
 }
 
-func (db *inmemRepository) NewRetrospective(r *model.Retrospective) error {
-	if db.retrosById == nil {
-		db.retrosById = make(map[string]*model.Retrospective)
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
 	}
+	c := *s
+	return &c
+}
+
+func copyInt(i *int) *int {
+	if i == nil {
+		return nil
+	}
+	c := *i
+	return &c
+}
 
-	db.retrosById[r.Id] = r
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func copyRetrospective(r *model.Retrospective) *model.Retrospective {
+	c := *r
+	if r.Closed != nil {
+		closed := *r.Closed
+		c.Closed = &closed
+	}
+	return &c
+}
+
+func copyCard(card *model.Card) *model.Card {
+	c := *card
+	c.MergedCards = nil
+	c.MergedInto = copyString(card.MergedInto)
+	c.MergeTitle = copyString(card.MergeTitle)
+	c.GroupId = copyString(card.GroupId)
+	c.IssueUrl = copyString(card.IssueUrl)
+	c.ExportClaimed = copyTime(card.ExportClaimed)
+	return &c
+}
+
+func (db *inmemRepository) NewRetrospective(ctx context.Context, r *model.Retrospective) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.retrosById[r.Id]; ok {
+		return model.InputError("retrospective already exists")
+	}
+	for _, other := range db.retrosById {
+		if r.PetName != "" && other.PetName == r.PetName {
+			return model.InputError("pet name already taken")
+		}
+	}
+	db.retrosById[r.Id] = copyRetrospective(r)
 	return nil
 }
 
-func (db *inmemRepository) GetRetrospectiveById(id string) (*model.Retrospective, error) {
+func (db *inmemRepository) UpdateRetrospective(ctx context.Context, r *model.Retrospective) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	old, ok := db.retrosById[r.Id]
+	if !ok {
+		return nil
+	}
+	c := copyRetrospective(r)
+	c.Created = old.Created
+	c.PetName = old.PetName
+	db.retrosById[r.Id] = c
+	return nil
+}
+
+func (db *inmemRepository) GetRetrospectiveById(ctx context.Context, id string) (*model.Retrospective, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
 	r, ok := db.retrosById[id]
 	if !ok {
-		return nil, errors.Errorf("retrospective with ID `%s` does not exist", id)
+		return nil, &model.NotFoundError{Kind: "retrospective", Id: id}
+	}
+	return copyRetrospective(r), nil
+}
+
+func (db *inmemRepository) GetRetrospectiveByPetName(ctx context.Context, petName string) (*model.Retrospective, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
-	return r, nil
+	for _, r := range db.retrosById {
+		if r.PetName == petName {
+			return copyRetrospective(r), nil
+		}
+	}
+	return nil, &model.NotFoundError{Kind: "retrospective", Id: petName}
 }
 
-func (db *inmemRepository) NewCard(c *model.Card) error {
-	if db.cardsById == nil {
-		db.cardsById = make(map[string]*model.Card)
+func (db *inmemRepository) GetTeam(ctx context.Context, name string) (*model.Team, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	if db.cardsByRetrospectiveId == nil {
-		db.cardsByRetrospectiveId = make(map[string][]*model.Card)
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	t, ok := db.teamsByName[name]
+	if !ok {
+		return nil, &model.NotFoundError{Kind: "team", Id: name}
 	}
+	c := *t
+	c.RetentionDays = copyInt(t.RetentionDays)
+	return &c, nil
+}
 
-	db.cardsById[c.Id] = c
-	db.cardsByRetrospectiveId[c.RetrospectiveId] = append(db.cardsByRetrospectiveId[c.RetrospectiveId], c)
+func (db *inmemRepository) SaveTeam(ctx context.Context, t *model.Team) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
 
+	if old, ok := db.teamsByName[t.Name]; ok {
+		old.Updated = t.Updated
+		old.ChatWebhookUrl = t.ChatWebhookUrl
+		old.RetentionDays = copyInt(t.RetentionDays)
+		return nil
+	}
+	c := *t
+	c.RetentionDays = copyInt(t.RetentionDays)
+	db.teamsByName[t.Name] = &c
 	return nil
 }
 
-func (db *inmemRepository) UpdateCard(card *model.Card) error {
-	c := db.cardsById[card.Id]
-	c.Column = card.Column
-	c.Message = card.Message
+// byPosition sorts cards by position, like the sql repository does.
+func byPosition(cs []*model.Card) {
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].Position != cs[j].Position {
+			return cs[i].Position < cs[j].Position
+		}
+		return cs[i].Id < cs[j].Id
+	})
+}
+
+// column returns the top level cards of a column outside of groups ordered by
+// position, leaving out the card with the given id. Must be called with the
+// lock held.
+func (db *inmemRepository) column(rId string, column string, except string) []*model.Card {
+	cs := []*model.Card{}
+	for _, c := range db.cards {
+		if c.RetrospectiveId == rId && c.Column == column && c.MergedInto == nil && c.GroupId == nil && c.Id != except {
+			cs = append(cs, c)
+		}
+	}
+	byPosition(cs)
+	return cs
+}
+
+func (db *inmemRepository) NewCard(ctx context.Context, c *model.Card) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	count := 0
+	for _, card := range db.cards {
+		if card.RetrospectiveId == c.RetrospectiveId {
+			count++
+		}
+	}
+	if count > db.MaxCards {
+		return model.InputError("too many cards")
+	}
+
+	min := 0
+	if cs := db.column(c.RetrospectiveId, c.Column, ""); len(cs) > 0 {
+		min = cs[0].Position
+	}
+	c.Position = min - IDX_SPACING
+
+	stored := copyCard(c)
+	db.cardsById[c.Id] = stored
+	db.cards = append(db.cards, stored)
 	return nil
 }
 
-func (db *inmemRepository) GetCardById(id string) (*model.Card, error) {
-	c, ok := db.cardsById[id]
-	if !ok {
-		return nil, errors.Errorf("card with ID `%s` does not exist", id)
+func (db *inmemRepository) UpdateCard(ctx context.Context, c *model.Card) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
 
-	return c, nil
+	stored, ok := db.cardsById[c.Id]
+	if !ok {
+		return &model.NotFoundError{Kind: "card", Id: c.Id}
+	}
+	if stored.Version != c.Version {
+		return &model.ConflictError{Card: copyCard(stored)}
+	}
+	c.Version++
+	stored.Version = c.Version
+	stored.Updated = c.Updated
+	stored.RetrospectiveId = c.RetrospectiveId
+	stored.Message = c.Message
+	stored.Creator = c.Creator
+	stored.Column = c.Column
+	stored.Position = c.Position
+	stored.MergedInto = copyString(c.MergedInto)
+	stored.MergeTitle = copyString(c.MergeTitle)
+	stored.GroupId = copyString(c.GroupId)
+	stored.IssueUrl = copyString(c.IssueUrl)
+	stored.ExportClaimed = copyTime(c.ExportClaimed)
+	return nil
 }
 
-func (db *inmemRepository) GetCardsByRetrospectiveId(id string) ([]*model.Card, error) {
-	cards, ok := db.cardsByRetrospectiveId[id]
+func (db *inmemRepository) MoveCard(ctx context.Context, c *model.Card, column string, index int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if index < 0 {
+		return model.InputError("Cannot move to negative index")
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.cardsById[c.Id]
 	if !ok {
-		return nil, errors.Errorf("card(s) with retrospective ID `%s` does not exist", id)
+		return &model.NotFoundError{Kind: "card", Id: c.Id}
+	}
+	if stored.Version != c.Version {
+		return &model.ConflictError{Card: copyCard(stored)}
+	}
+
+	c.Position = place(db.siblings(c, column), index)
+	// Merged cards are only ordered within their group
+	if c.MergedInto == nil {
+		c.Column = column
 	}
+	c.Version++
 
-	return cards, nil
+	stored.Version = c.Version
+	stored.Updated = c.Updated
+	stored.Column = c.Column
+	stored.Position = c.Position
+	stored.GroupId = copyString(c.GroupId)
+	return nil
+}
+
+// positionAt returns the position that puts a card at index among cards
+// ordered by position, or false if there is no room left between its
+// neighbours.
+func positionAt(cs []*model.Card, index int) (int, bool) {
+	var position int
+	if len(cs) == 0 {
+		position = IDX_SPACING
+	} else if index >= len(cs) {
+		position = cs[len(cs)-1].Position + IDX_SPACING
+	} else if index == 0 {
+		position = cs[0].Position - IDX_SPACING
+	} else {
+		position = (cs[index].Position + cs[index-1].Position) / 2
+		if position <= cs[index-1].Position || position >= cs[index].Position {
+			return 0, false
+		}
+	}
+	return position, position >= -int(math.Exp2(30)) && position <= int(math.Exp2(30))
 }
 
-func (db *inmemRepository) NewVote(v *model.Vote) error {
-	if db.votesById == nil {
-		db.votesById = make(map[string]*model.Vote)
+// siblings returns the cards c is ordered among, leaving out c itself: the
+// other cards merged into the same card, the other cards of its group, or
+// else the top level cards of column outside of groups. Must be called with
+// the lock held.
+func (db *inmemRepository) siblings(c *model.Card, column string) []*model.Card {
+	if c.MergedInto == nil && c.GroupId == nil {
+		return db.column(c.RetrospectiveId, column, c.Id)
 	}
-	if db.votesByCardId == nil {
-		db.votesByCardId = make(map[string][]*model.Vote)
+	cs := []*model.Card{}
+	for _, other := range db.cards {
+		if other.Id == c.Id {
+			continue
+		}
+		if c.MergedInto != nil && other.MergedInto != nil && *other.MergedInto == *c.MergedInto {
+			cs = append(cs, other)
+		}
+		if c.MergedInto == nil && other.MergedInto == nil && other.GroupId != nil && *other.GroupId == *c.GroupId {
+			cs = append(cs, other)
+		}
 	}
+	byPosition(cs)
+	return cs
+}
 
-	// Update existing vote instead of adding new if we already have it.
-	if db.votesById[v.Id] == nil {
-		db.votesByCardId[v.CardId] = append(db.votesByCardId[v.CardId], v)
-		db.votesById[v.Id] = v
-	} else {
-		db.votesById[v.Id].Count = v.Count
+// place returns the position at index among the stored siblings cs,
+// renumbering them if there is no room left.
+func place(cs []*model.Card, index int) int {
+	position, ok := positionAt(cs, index)
+	if !ok {
+		for i, c := range cs {
+			c.Position = IDX_SPACING * (i + 1)
+		}
+		position, _ = positionAt(cs, index)
 	}
+	return position
+}
 
+// openMerge returns the merge that put the card with the given id where it
+// is, if any. Must be called with the lock held.
+func (db *inmemRepository) openMerge(id string) *model.Merge {
+	for i := len(db.merges) - 1; i >= 0; i-- {
+		if m := db.merges[i]; m.CardId == id && m.Unmerged == nil {
+			return m
+		}
+	}
 	return nil
 }
 
-func (db *inmemRepository) GetVotesByCardId(id string) ([]*model.Vote, error) {
-	votes, ok := db.votesByCardId[id]
+// closeMerges marks the merges of the card with the given id as undone.
+// Must be called with the lock held.
+func (db *inmemRepository) closeMerges(id string, t time.Time) {
+	for _, m := range db.merges {
+		if m.CardId == id && m.Unmerged == nil {
+			unmerged := t
+			m.Unmerged = &unmerged
+		}
+	}
+}
+
+func (db *inmemRepository) MergeCard(ctx context.Context, c *model.Card, m *model.Merge) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.cardsById[c.Id]
 	if !ok {
-		return make([]*model.Vote, 0), nil
+		return &model.NotFoundError{Kind: "card", Id: c.Id}
 	}
+	cards := map[string]*model.Card{}
+	for _, card := range db.cards {
+		if card.RetrospectiveId == stored.RetrospectiveId {
+			cards[card.Id] = card
+		}
+	}
+	if err := model.CheckMerge(cards, stored.Id, m.MergedInto); err != nil {
+		return err
+	}
+	if stored.MergedInto != nil && *stored.MergedInto == m.MergedInto {
+		return nil
+	}
+
+	// A card moved between groups goes back to where it was before it was
+	// first merged.
+	m.Column = stored.Column
+	m.Position = stored.Position
+	if previous := db.openMerge(stored.Id); stored.MergedInto != nil && previous != nil {
+		m.Column = previous.Column
+		m.Position = previous.Position
+	}
+	db.closeMerges(stored.Id, m.Created)
+	merge := *m
+	db.merges = append(db.merges, &merge)
+
+	// Merged cards leave their group, and are put back outside of it when
+	// unmerged
+	stored.MergedInto = copyString(&m.MergedInto)
+	stored.GroupId = nil
+	cs := db.siblings(stored, "")
+	stored.Position = place(cs, len(cs))
+	stored.Version++
 
-	return votes, nil
+	c.MergedInto = copyString(stored.MergedInto)
+	c.GroupId = nil
+	c.Position = stored.Position
+	c.Version = stored.Version
+	return nil
 }
 
-func (db *inmemRepository) NewStatus(s *model.Status) error {
-	if db.statusesById == nil {
-		db.statusesById = make(map[string]*model.Status)
+// UnmergeCard takes a card out of its merge group, and puts it back where it
+// was on the board before it was merged.
+func (db *inmemRepository) UnmergeCard(ctx context.Context, c *model.Card) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.cardsById[c.Id]
+	if !ok {
+		return &model.NotFoundError{Kind: "card", Id: c.Id}
+	}
+	if stored.MergedInto == nil {
+		return model.InputError("Card is not merged")
 	}
-	if db.statusesByCardId == nil {
-		db.statusesByCardId = make(map[string][]*model.Status)
+	db.unmerge(stored)
+
+	c.MergedInto = nil
+	c.Column = stored.Column
+	c.Position = stored.Position
+	c.Version = stored.Version
+	return nil
+}
+
+// UnmergeAll takes every card out of the merge group headed by c at once,
+// and puts each back where it was on the board before it was merged.
+func (db *inmemRepository) UnmergeAll(ctx context.Context, c *model.Card) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
 
-	db.statusesById[s.Id] = s
-	db.statusesByCardId[s.CardId] = append(db.statusesByCardId[s.CardId], s)
+	head, ok := db.cardsById[c.Id]
+	if !ok {
+		return &model.NotFoundError{Kind: "card", Id: c.Id}
+	}
+	// Cards merged into merged cards are unmerged as well
+	ids := []string{head.Id}
+	for i := 0; i < len(ids); i++ {
+		for _, merged := range db.mergedCards(ids[i]) {
+			ids = append(ids, merged.Id)
+		}
+	}
+	if len(ids) == 1 {
+		return model.InputError("No cards are merged into the card")
+	}
+	for _, id := range ids[1:] {
+		db.unmerge(db.cardsById[id])
+	}
 
+	c.MergedCards = nil
+	c.MergeTitle = copyString(head.MergeTitle)
+	c.Version = head.Version
 	return nil
 }
 
-func (db *inmemRepository) GetStatusById(id string) (*model.Status, error) {
-	s, ok := db.statusesById[id]
+// unmerge takes the stored card out of its merge group. Must be called with
+// the lock held.
+func (db *inmemRepository) unmerge(stored *model.Card) {
+	parent := *stored.MergedInto
+	stored.MergedInto = nil
+
+	// Cards merged before merges were recorded keep their position
+	if m := db.openMerge(stored.Id); m != nil {
+		cs := db.column(stored.RetrospectiveId, m.Column, stored.Id)
+		index := sort.Search(len(cs), func(i int) bool {
+			return cs[i].Position >= m.Position
+		})
+		stored.Column = m.Column
+		// Keep the old position, unless another card took it
+		stored.Position = m.Position
+		if index < len(cs) && cs[index].Position == m.Position {
+			stored.Position = place(cs, index)
+		}
+	}
+	db.closeMerges(stored.Id, time.Now())
+	stored.Version++
+
+	// The title goes with the group once its last card is gone
+	if p, ok := db.cardsById[parent]; ok && p.MergeTitle != nil && len(db.mergedCards(parent)) == 0 {
+		p.MergeTitle = nil
+		p.Version++
+	}
+}
+
+func (db *inmemRepository) GetMergesByCardId(ctx context.Context, id string) ([]*model.Merge, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	ms := []*model.Merge{}
+	for _, m := range db.merges {
+		if m.CardId == id {
+			c := *m
+			if m.Unmerged != nil {
+				unmerged := *m.Unmerged
+				c.Unmerged = &unmerged
+			}
+			ms = append(ms, &c)
+		}
+	}
+	return ms, nil
+}
+
+// mergedCards returns the cards merged into the card with the given id,
+// ordered by position. Must be called with the lock held.
+func (db *inmemRepository) mergedCards(id string) []*model.Card {
+	cs := []*model.Card{}
+	for _, c := range db.cards {
+		if c.MergedInto != nil && *c.MergedInto == id {
+			cs = append(cs, copyCard(c))
+		}
+	}
+	byPosition(cs)
+	for _, c := range cs {
+		c.MergedCards = db.mergedCards(c.Id)
+	}
+	return cs
+}
+
+func (db *inmemRepository) GetCardById(ctx context.Context, id string) (*model.Card, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	stored, ok := db.cardsById[id]
 	if !ok {
-		return nil, errors.Errorf("status with ID `%s` does not exist", id)
+		return nil, &model.NotFoundError{Kind: "card", Id: id}
 	}
+	c := copyCard(stored)
+	c.MergedCards = db.mergedCards(id)
+	return c, nil
+}
 
-	return s, nil
+func (db *inmemRepository) GetCardsByRetrospectiveId(ctx context.Context, id string) ([]*model.Card, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	allCards := []*model.Card{}
+	for _, c := range db.cards {
+		if c.RetrospectiveId == id {
+			allCards = append(allCards, copyCard(c))
+		}
+	}
+	byPosition(allCards)
+
+	unmergedCards := []*model.Card{}
+	mergedCards := []*model.Card{}
+	cardsById := map[string]*model.Card{}
+	for _, card := range allCards {
+		cardsById[card.Id] = card
+		if card.MergedInto == nil {
+			unmergedCards = append(unmergedCards, card)
+		} else {
+			mergedCards = append(mergedCards, card)
+		}
+	}
+
+	for _, card := range mergedCards {
+		baseCard := cardsById[*card.MergedInto]
+		if baseCard == nil {
+			continue
+		}
+		if baseCard.MergedCards == nil {
+			baseCard.MergedCards = []*model.Card{}
+		}
+		baseCard.MergedCards = append(baseCard.MergedCards, card)
+	}
+
+	return unmergedCards, nil
+}
+
+func (db *inmemRepository) NewVote(ctx context.Context, v *model.Vote) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// Count up the existing vote instead of adding a new one if we already
+	// have it.
+	if stored, ok := db.votesById[v.Id]; ok {
+		stored.Updated = v.Updated
+		stored.Count += 1
+		return nil
+	}
+	c := *v
+	db.votesById[v.Id] = &c
+	db.votes = append(db.votes, &c)
+	return nil
+}
+
+func (db *inmemRepository) GetVotesByCardId(ctx context.Context, id string) ([]*model.Vote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	vs := []*model.Vote{}
+	for _, v := range db.votes {
+		if v.CardId == id {
+			c := *v
+			vs = append(vs, &c)
+		}
+	}
+	return vs, nil
+}
+
+func (db *inmemRepository) GetVoteByCardIdAndVoterAndEmoji(ctx context.Context, id string, voter string, emoji string) (*model.Vote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, v := range db.votes {
+		if v.CardId == id && v.Voter == voter && v.Emoji == emoji {
+			c := *v
+			return &c, nil
+		}
+	}
+	return nil, &model.NotFoundError{Kind: "vote", Id: emoji}
 }
 
-func (db *inmemRepository) GetStatusesByCardId(id string) ([]*model.Status, error) {
-	statuses, ok := db.statusesByCardId[id]
+func (db *inmemRepository) GetTotalUniqueEmojis(ctx context.Context, id string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	emojis := map[string]bool{}
+	for _, v := range db.votes {
+		if v.CardId == id {
+			emojis[v.Emoji] = true
+		}
+	}
+	return len(emojis), nil
+}
+
+func (db *inmemRepository) NewStatus(ctx context.Context, s *model.Status) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	c := *s
+	db.statusesById[s.Id] = &c
+	db.statuses = append(db.statuses, &c)
+	return nil
+}
+
+func (db *inmemRepository) GetStatusById(ctx context.Context, id string) (*model.Status, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	s, ok := db.statusesById[id]
 	if !ok {
-		return make([]*model.Status, 0), nil
+		return nil, &model.NotFoundError{Kind: "status", Id: id}
+	}
+	c := *s
+	return &c, nil
+}
+
+func (db *inmemRepository) GetStatusesByCardId(ctx context.Context, id string) ([]*model.Status, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
-	return statuses, nil
+	ss := []*model.Status{}
+	for _, s := range db.statuses {
+		if s.CardId == id {
+			c := *s
+			ss = append(ss, &c)
+		}
+	}
+	return ss, nil
+}
+
+func (db *inmemRepository) Healthcheck(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,66 @@
+package inmem
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/repository/conformance"
+)
+
+func TestCardsAreCopied(t *testing.T) {
+	ctx := context.Background()
+	db := NewRepository()
+	db.NewCard(ctx, &model.Card{Id: "card", RetrospectiveId: "retro", Message: "stored"})
+
+	c, _ := db.GetCardById(ctx, "card")
+	c.Message = "changed"
+	if c, _ := db.GetCardById(ctx, "card"); c.Message != "stored" {
+		t.Fatal("Expected card to change only on update, got:", c.Message)
+	}
+
+	db.UpdateCard(ctx, c)
+	if c, _ := db.GetCardById(ctx, "card"); c.Message != "changed" {
+		t.Fatal("Expected card to be updated, got:", c.Message)
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	db := NewRepository()
+	db.NewRetrospective(ctx, &model.Retrospective{Id: "retro"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := &model.Card{Id: fmt.Sprint("card-", i), RetrospectiveId: "retro", Column: "Mixed"}
+			db.NewCard(ctx, c)
+			db.MoveCard(ctx, c, "Mixed", i%3)
+			db.NewVote(ctx, &model.Vote{Id: "vote", CardId: "card-0", Count: 1, Updated: time.Now()})
+			db.Observe(ctx, c.Id, "user", "retro", "Active")
+			db.GetCardsByRetrospectiveId(ctx, "retro")
+			db.GetActiveUsers(ctx, "retro")
+		}(i)
+	}
+	wg.Wait()
+
+	cards, _ := db.GetCardsByRetrospectiveId(ctx, "retro")
+	if len(cards) != 20 {
+		t.Fatal("Expected 20 cards, got:", len(cards))
+	}
+	votes, _ := db.GetVotesByCardId(ctx, "card-0")
+	if len(votes) != 1 || votes[0].Count != 20 {
+		t.Fatal("Expected a single vote counted 20 times, got:", votes)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) conformance.Repository {
+		return NewRepository()
+	})
+}
@@ -0,0 +1,79 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+func (db *inmemRepository) Observe(ctx context.Context, connectionid string, user string, retrospectiveId string, state string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	userState, _ := model.UserStateTypeString(state)
+
+	o, ok := db.observationsByConnectionId[connectionid]
+	if !ok {
+		db.observationsByConnectionId[connectionid] = &model.Observation{
+			User:            user,
+			RetrospectiveId: retrospectiveId,
+			ConnectionId:    connectionid,
+			State:           userState,
+			FirstSeen:       time.Now(),
+			LastSeen:        time.Now(),
+		}
+		return true, nil
+	}
+
+	changed := o.State != userState
+	o.State = userState
+	o.LastSeen = time.Now()
+	return changed, nil
+}
+
+func (db *inmemRepository) ClearObservations(ctx context.Context, connectionid string) {
+	if ctx.Err() != nil {
+		return
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	delete(db.observationsByConnectionId, connectionid)
+}
+
+func (db *inmemRepository) GetActiveUsers(ctx context.Context, retrospectiveId string) ([]model.UserState, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	// Like the sql repository, every user appears once with their highest
+	// state, ordered by when they were first seen.
+	cutoff := time.Now().Add(-db.PresenceWindow)
+	firstSeen := map[string]time.Time{}
+	states := map[string]model.UserStateType{}
+	for _, o := range db.observationsByConnectionId {
+		if o.RetrospectiveId != retrospectiveId || !o.LastSeen.After(cutoff) {
+			continue
+		}
+		if seen, ok := firstSeen[o.User]; !ok || o.FirstSeen.Before(seen) {
+			firstSeen[o.User] = o.FirstSeen
+		}
+		if state, ok := states[o.User]; !ok || o.State > state {
+			states[o.User] = o.State
+		}
+	}
+
+	userStates := []model.UserState{}
+	for user, state := range states {
+		userStates = append(userStates, model.UserState{User: user, State: state})
+	}
+	sort.Slice(userStates, func(i, j int) bool {
+		return firstSeen[userStates[i].User].Before(firstSeen[userStates[j].User])
+	})
+	return userStates, nil
+}
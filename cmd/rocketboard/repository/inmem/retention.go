@@ -0,0 +1,265 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+func (db *inmemRepository) GetTeams(ctx context.Context) ([]*model.Team, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	ts := []*model.Team{}
+	for _, t := range db.teamsByName {
+		c := *t
+		c.RetentionDays = copyInt(t.RetentionDays)
+		ts = append(ts, &c)
+	}
+	sort.Slice(ts, func(i, j int) bool {
+		return ts[i].Name < ts[j].Name
+	})
+	return ts, nil
+}
+
+func (db *inmemRepository) GetClosedRetrospectives(ctx context.Context, before time.Time) ([]*model.Retrospective, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rs := []*model.Retrospective{}
+	for _, r := range db.retrosById {
+		if r.Closed != nil && r.Closed.Before(before) {
+			rs = append(rs, copyRetrospective(r))
+		}
+	}
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].Closed.Equal(*rs[j].Closed) {
+			return rs[i].Id < rs[j].Id
+		}
+		return rs[i].Closed.Before(*rs[j].Closed)
+	})
+	return rs, nil
+}
+
+// ArchiveRetrospective stores the archive and deletes everything recorded
+// about its retrospective. An archive without a snapshot gets one taken
+// under the same lock.
+func (db *inmemRepository) ArchiveRetrospective(ctx context.Context, a *model.Archive) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	c := *a
+	c.Snapshot = append([]byte{}, a.Snapshot...)
+	if a.Snapshot == nil {
+		s, err := db.snapshot(a.Id)
+		if err != nil {
+			return err
+		}
+		if c.Snapshot, err = s.Compress(); err != nil {
+			return err
+		}
+	}
+	db.archivesById[a.Id] = &c
+
+	cardIds := map[string]bool{}
+	cards := []*model.Card{}
+	for _, card := range db.cards {
+		if card.RetrospectiveId == a.Id {
+			cardIds[card.Id] = true
+			delete(db.cardsById, card.Id)
+		} else {
+			cards = append(cards, card)
+		}
+	}
+	db.cards = cards
+
+	votes := []*model.Vote{}
+	for _, v := range db.votes {
+		if cardIds[v.CardId] {
+			delete(db.votesById, v.Id)
+		} else {
+			votes = append(votes, v)
+		}
+	}
+	db.votes = votes
+
+	statuses := []*model.Status{}
+	for _, s := range db.statuses {
+		if cardIds[s.CardId] {
+			delete(db.statusesById, s.Id)
+		} else {
+			statuses = append(statuses, s)
+		}
+	}
+	db.statuses = statuses
+
+	merges := []*model.Merge{}
+	for _, m := range db.merges {
+		if !cardIds[m.CardId] {
+			merges = append(merges, m)
+		}
+	}
+	db.merges = merges
+
+	for id, g := range db.groupsById {
+		if g.RetrospectiveId == a.Id {
+			delete(db.groupsById, id)
+		}
+	}
+	for id, o := range db.observationsByConnectionId {
+		if o.RetrospectiveId == a.Id {
+			delete(db.observationsByConnectionId, id)
+		}
+	}
+	for wId, w := range db.webhooksById {
+		if w.RetrospectiveId == nil || *w.RetrospectiveId != a.Id {
+			continue
+		}
+		for dId, d := range db.deliveriesById {
+			if d.WebhookId != wId {
+				continue
+			}
+			for aId, attempt := range db.attemptsById {
+				if attempt.DeliveryId == dId {
+					delete(db.attemptsById, aId)
+				}
+			}
+			delete(db.deliveriesById, dId)
+		}
+		delete(db.webhooksById, wId)
+	}
+	delete(db.retrosById, a.Id)
+	return nil
+}
+
+// snapshot collects everything recorded about the retrospective with the
+// given id. Must be called with the lock held.
+func (db *inmemRepository) snapshot(id string) (*model.Snapshot, error) {
+	r, ok := db.retrosById[id]
+	if !ok {
+		return nil, &model.NotFoundError{Kind: "retrospective", Id: id}
+	}
+	s := &model.Snapshot{
+		Retrospective: copyRetrospective(r),
+		Cards:         []*model.Card{},
+		Groups:        []*model.CardGroup{},
+		Merges:        []*model.Merge{},
+		Votes:         []*model.Vote{},
+		Statuses:      []*model.Status{},
+	}
+
+	cardIds := map[string]bool{}
+	for _, c := range db.cards {
+		if c.RetrospectiveId == id {
+			cardIds[c.Id] = true
+			s.Cards = append(s.Cards, copyCard(c))
+		}
+	}
+	byPosition(s.Cards)
+	for _, g := range db.groupsById {
+		if g.RetrospectiveId == id {
+			c := *g
+			s.Groups = append(s.Groups, &c)
+		}
+	}
+	sort.Slice(s.Groups, func(i, j int) bool {
+		if s.Groups[i].Column != s.Groups[j].Column {
+			return s.Groups[i].Column < s.Groups[j].Column
+		}
+		if s.Groups[i].Position != s.Groups[j].Position {
+			return s.Groups[i].Position < s.Groups[j].Position
+		}
+		return s.Groups[i].Id < s.Groups[j].Id
+	})
+	for _, m := range db.merges {
+		if cardIds[m.CardId] {
+			c := *m
+			if m.Unmerged != nil {
+				unmerged := *m.Unmerged
+				c.Unmerged = &unmerged
+			}
+			s.Merges = append(s.Merges, &c)
+		}
+	}
+	for _, v := range db.votes {
+		if cardIds[v.CardId] {
+			c := *v
+			s.Votes = append(s.Votes, &c)
+		}
+	}
+	for _, st := range db.statuses {
+		if cardIds[st.CardId] {
+			c := *st
+			s.Statuses = append(s.Statuses, &c)
+		}
+	}
+	return s, nil
+}
+
+func (db *inmemRepository) GetArchivesByTeam(ctx context.Context, team string) ([]*model.Archive, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	as := []*model.Archive{}
+	for _, a := range db.archivesById {
+		if a.Team == team {
+			c := *a
+			c.Snapshot = nil
+			as = append(as, &c)
+		}
+	}
+	sort.Slice(as, func(i, j int) bool {
+		if as[i].Closed.Equal(as[j].Closed) {
+			return as[i].Id < as[j].Id
+		}
+		return as[i].Closed.After(as[j].Closed)
+	})
+	return as, nil
+}
+
+func (db *inmemRepository) GetArchiveById(ctx context.Context, id string) (*model.Archive, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	a, ok := db.archivesById[id]
+	if !ok {
+		return nil, &model.NotFoundError{Kind: "archive", Id: id}
+	}
+	c := *a
+	c.Snapshot = append([]byte{}, a.Snapshot...)
+	return &c, nil
+}
+
+func (db *inmemRepository) PurgeObservations(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	n := 0
+	for id, o := range db.observationsByConnectionId {
+		if o.LastSeen.Before(before) {
+			delete(db.observationsByConnectionId, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,226 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+func (db *inmemRepository) NewWebhook(ctx context.Context, w *model.Webhook) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	c := *w
+	c.RetrospectiveId = copyString(w.RetrospectiveId)
+	db.webhooksById[w.Id] = &c
+	return nil
+}
+
+func (db *inmemRepository) DeleteWebhook(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for dId, d := range db.deliveriesById {
+		if d.WebhookId != id {
+			continue
+		}
+		for aId, a := range db.attemptsById {
+			if a.DeliveryId == dId {
+				delete(db.attemptsById, aId)
+			}
+		}
+		delete(db.deliveriesById, dId)
+	}
+	delete(db.webhooksById, id)
+	return nil
+}
+
+func (db *inmemRepository) GetWebhookById(ctx context.Context, id string) (*model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	w, ok := db.webhooksById[id]
+	if !ok {
+		return nil, &model.NotFoundError{Kind: "webhook", Id: id}
+	}
+	c := *w
+	return &c, nil
+}
+
+// webhooks returns the webhooks matching f ordered by creation. Must be
+// called with the lock held.
+func (db *inmemRepository) webhooks(f func(*model.Webhook) bool) []*model.Webhook {
+	ws := []*model.Webhook{}
+	for _, w := range db.webhooksById {
+		if f(w) {
+			c := *w
+			ws = append(ws, &c)
+		}
+	}
+	// Webhooks created at once are ordered by id, so the order is stable
+	sort.Slice(ws, func(i, j int) bool {
+		if ws[i].Created.Equal(ws[j].Created) {
+			return ws[i].Id < ws[j].Id
+		}
+		return ws[i].Created.Before(ws[j].Created)
+	})
+	return ws
+}
+
+func (db *inmemRepository) GetWebhooksByRetrospectiveId(ctx context.Context, id string) ([]*model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.webhooks(func(w *model.Webhook) bool {
+		return w.RetrospectiveId != nil && *w.RetrospectiveId == id
+	}), nil
+}
+
+func (db *inmemRepository) GetWebhooksByTeam(ctx context.Context, team string) ([]*model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.webhooks(func(w *model.Webhook) bool {
+		return w.RetrospectiveId == nil && w.Team == team
+	}), nil
+}
+
+func (db *inmemRepository) NewWebhookDelivery(ctx context.Context, d *model.WebhookDelivery) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	c := *d
+	db.deliveriesById[d.Id] = &c
+	return nil
+}
+
+func (db *inmemRepository) UpdateWebhookDelivery(ctx context.Context, d *model.WebhookDelivery) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if stored, ok := db.deliveriesById[d.Id]; ok {
+		stored.State = d.State
+		stored.Attempts = d.Attempts
+		stored.NextAttempt = d.NextAttempt
+	}
+	return nil
+}
+
+func (db *inmemRepository) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	ds := []*model.WebhookDelivery{}
+	for _, d := range db.deliveriesById {
+		if d.State == model.Pending && !d.NextAttempt.After(now) {
+			c := *d
+			ds = append(ds, &c)
+		}
+	}
+	sort.Slice(ds, func(i, j int) bool {
+		return ds[i].NextAttempt.Before(ds[j].NextAttempt)
+	})
+	if len(ds) > limit {
+		ds = ds[:limit]
+	}
+	return ds, nil
+}
+
+func (db *inmemRepository) ClaimWebhookDelivery(ctx context.Context, d *model.WebhookDelivery, claim string, until time.Time) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.deliveriesById[d.Id]
+	if !ok || stored.State != model.Pending || stored.Claim != d.Claim {
+		return false, nil
+	}
+	stored.NextAttempt = until
+	stored.Claim = claim
+	d.NextAttempt = until
+	d.Claim = claim
+	return true, nil
+}
+
+func (db *inmemRepository) GetWebhookDeliveriesByWebhookId(ctx context.Context, id string, limit int) ([]*model.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	ds := []*model.WebhookDelivery{}
+	for _, d := range db.deliveriesById {
+		if d.WebhookId == id {
+			c := *d
+			ds = append(ds, &c)
+		}
+	}
+	sort.Slice(ds, func(i, j int) bool {
+		return ds[i].Created.After(ds[j].Created)
+	})
+	if len(ds) > limit {
+		ds = ds[:limit]
+	}
+	return ds, nil
+}
+
+func (db *inmemRepository) NewWebhookAttempt(ctx context.Context, a *model.WebhookAttempt) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	c := *a
+	db.attemptsById[a.Id] = &c
+	return nil
+}
+
+func (db *inmemRepository) GetWebhookAttemptsByDeliveryId(ctx context.Context, id string) ([]*model.WebhookAttempt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	as := []*model.WebhookAttempt{}
+	for _, a := range db.attemptsById {
+		if a.DeliveryId == id {
+			c := *a
+			as = append(as, &c)
+		}
+	}
+	sort.Slice(as, func(i, j int) bool {
+		return as[i].Created.Before(as[j].Created)
+	})
+	return as, nil
+}
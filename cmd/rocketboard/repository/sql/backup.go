@@ -0,0 +1,106 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+// GetRetrospectives returns up to limit retrospectives with an id after the
+// given one, ordered by id, to page through all of them.
+func (db *sqlRepository) GetRetrospectives(ctx context.Context, after string, limit int) ([]*model.Retrospective, error) {
+	rs := []*model.Retrospective{}
+	err := db.SelectContext(ctx, &rs, "SELECT * FROM retrospectives WHERE id > $1 ORDER BY id LIMIT $2", after, limit)
+	return rs, err
+}
+
+// GetArchives returns up to limit archives with an id after the given one,
+// ordered by id and including their snapshots.
+func (db *sqlRepository) GetArchives(ctx context.Context, after string, limit int) ([]*model.Archive, error) {
+	as := []*model.Archive{}
+	err := db.SelectContext(ctx, &as, "SELECT * FROM archives WHERE id > $1 ORDER BY id LIMIT $2", after, limit)
+	return as, err
+}
+
+func (db *sqlRepository) GetAllWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	ws := []*model.Webhook{}
+	err := db.SelectContext(ctx, &ws, "SELECT * FROM webhooks ORDER BY created ASC, id")
+	return ws, err
+}
+
+// GetSnapshot reads everything recorded about a retrospective in one
+// read-only transaction, so that the snapshot is consistent while the
+// retrospective is in use.
+func (db *sqlRepository) GetSnapshot(ctx context.Context, id string) (*model.Snapshot, error) {
+	var s *model.Snapshot
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := db.transactWith(ctx, opts, func(ctx context.Context, tx *sqlx.Tx) error {
+		var err error
+		s, err = snapshot(ctx, tx, id)
+		return err
+	})
+	return s, err
+}
+
+// RestoreSnapshot inserts a retrospective and everything recorded about it
+// as is, keeping ids, positions and versions.
+func (db *sqlRepository) RestoreSnapshot(ctx context.Context, s *model.Snapshot) error {
+	return db.transact(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		_, err := tx.NamedExecContext(ctx, `INSERT INTO retrospectives
+        (id, created, updated, name, petname, team, closed)
+      VALUES (:id, :created, :updated, :name, :petname, :team, :closed)
+    `, s.Retrospective)
+		if err != nil {
+			return err
+		}
+		for _, c := range s.Cards {
+			_, err := tx.NamedExecContext(ctx, `INSERT INTO cards
+          (id, created, updated, retrospectiveid, message, creator, "column", position, mergedinto, mergetitle, groupid, issueurl, version)
+        VALUES (:id, :created, :updated, :retrospectiveid, :message, :creator, :column, :position, :mergedinto, :mergetitle, :groupid, :issueurl, :version)
+      `, c)
+			if err != nil {
+				return err
+			}
+		}
+		for _, g := range s.Groups {
+			_, err := tx.NamedExecContext(ctx, `INSERT INTO cardgroups
+          (id, created, updated, retrospectiveid, "column", name, position)
+        VALUES (:id, :created, :updated, :retrospectiveid, :column, :name, :position)
+      `, g)
+			if err != nil {
+				return err
+			}
+		}
+		for _, m := range s.Merges {
+			_, err := tx.NamedExecContext(ctx, `INSERT INTO merges
+          (id, created, cardid, mergedinto, "column", position, unmerged)
+        VALUES (:id, :created, :cardid, :mergedinto, :column, :position, :unmerged)
+      `, m)
+			if err != nil {
+				return err
+			}
+		}
+		for _, v := range s.Votes {
+			_, err := tx.NamedExecContext(ctx, `INSERT INTO votes
+          (id, created, updated, cardid, voter, emoji, count)
+        VALUES (:id, :created, :updated, :cardid, :voter, :emoji, :count)
+      `, v)
+			if err != nil {
+				return err
+			}
+		}
+		for _, st := range s.Statuses {
+			_, err := tx.NamedExecContext(ctx, `INSERT INTO statuses
+          (id, created, cardid, type)
+        VALUES (:id, :created, :cardid, :type)
+      `, st)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,49 @@
+package sql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/repository/conformance"
+)
+
+func TestConformanceSqlite(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) conformance.Repository {
+		// A file rather than memory, to allow for concurrent connections
+		db, err := NewRepository("sqlite3:" + filepath.Join(t.TempDir(), "rocket.db"))
+		if err != nil {
+			t.Fatal("Failed to open database", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	})
+}
+
+// TestConformancePostgres runs against the database at
+// ROCKET_TEST_POSTGRES_URI, which is emptied before every test.
+func TestConformancePostgres(t *testing.T) {
+	uri := os.Getenv("ROCKET_TEST_POSTGRES_URI")
+	if uri == "" {
+		t.Skip("ROCKET_TEST_POSTGRES_URI not set")
+	}
+	conformance.Run(t, func(t *testing.T) conformance.Repository {
+		db, err := NewRepository(uri)
+		if err != nil {
+			t.Fatal("Failed to open database", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		// Children are emptied before the tables they refer to
+		tables := []string{
+			"webhook_attempts", "webhook_deliveries", "webhooks",
+			"votes", "statuses", "merges", "cards", "cardgroups", "observations",
+			"archives", "retrospectives", "teams",
+		}
+		for _, table := range tables {
+			if _, err := db.Exec("DELETE FROM " + table); err != nil {
+				t.Fatal("Failed to empty", table, err)
+			}
+		}
+		return db
+	})
+}
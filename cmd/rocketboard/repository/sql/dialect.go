@@ -0,0 +1,139 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/metrics"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/tracing"
+)
+
+// The SQL engines we support. PostgreSQL and CockroachDB share a dialect, as
+// both speak the PostgreSQL protocol through lib/pq.
+//
+// Queries are written to run unchanged on all of them:
+//   - Positional placeholders are written as $1, $2, ..., which sqlite
+//     understands as well. Named parameters (:name) are bound by sqlx in the
+//     form of the driver.
+//   - Identifiers are unquoted and lower case, as PostgreSQL folds unquoted
+//     identifiers to lower case while sqlite keeps them as declared. Only
+//     keywords like "column" and "user" are quoted.
+//   - Upserts are built by upsert, using ON CONFLICT as understood by sqlite
+//     3.24+, PostgreSQL 9.5+ and CockroachDB.
+//   - Transactions are run by transact, which retries them when the engine
+//     asks for it.
+const (
+	anyDialect      = ""
+	sqliteDialect   = "sqlite3"
+	postgresDialect = "postgres"
+)
+
+func dialectOf(driver string) string {
+	if driver == "sqlite3" {
+		return sqliteDialect
+	}
+	return postgresDialect
+}
+
+// upsert returns a named statement inserting columns into table, or setting
+// update on the existing row when one with the same key exists. The existing
+// row can be referred to by the name of the table in update.
+func upsert(table string, key string, columns []string, update string) string {
+	params := make([]string, len(columns))
+	for i, c := range columns {
+		params[i] = ":" + strings.Trim(c, `"`)
+	}
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ")" +
+		" VALUES (" + strings.Join(params, ", ") + ")" +
+		" ON CONFLICT(" + key + ") DO UPDATE SET " + update
+}
+
+// notFound reports the sql.ErrNoRows of looking up the entity of the given
+// kind and id as a model.NotFoundError.
+func notFound(err error, kind string, id string) error {
+	if err == sql.ErrNoRows {
+		return &model.NotFoundError{Kind: kind, Id: id}
+	}
+	return err
+}
+
+// Number of times a transaction is attempted before giving up
+const maxTransactionAttempts = 5
+
+// isRetryable reports whether a transaction failed because of a conflict with
+// another one and can be run again. CockroachDB runs all transactions
+// serializably and reports conflicts as serialization failures (SQLSTATE
+// 40001), which clients are expected to retry; sqlite reports a busy database.
+func isRetryable(err error) bool {
+	switch err := err.(type) {
+	case *pq.Error:
+		return err.Code == "40001" || err.Code == "40P01"
+	case sqlite3.Error:
+		return err.Code == sqlite3.ErrBusy || err.Code == sqlite3.ErrLocked
+	}
+	return false
+}
+
+// transact runs f in a transaction and commits it, starting over when the
+// transaction conflicted with another one. f may therefore run more than
+// once and must not have side effects outside of tx. f is passed the context
+// of the attempt and runs its statements with it, so that the statement
+// running when ctx is done or the attempt took longer than the query timeout
+// is cancelled along with the transaction.
+func (db *sqlRepository) transact(ctx context.Context, f func(ctx context.Context, tx *sqlx.Tx) error) error {
+	return db.runTransaction(ctx, transactionName(), nil, f)
+}
+
+// transactWith runs f like transact, in a transaction with the given
+// options, e.g. one isolated from all concurrent writes.
+func (db *sqlRepository) transactWith(ctx context.Context, opts *sql.TxOptions, f func(ctx context.Context, tx *sqlx.Tx) error) error {
+	return db.runTransaction(ctx, transactionName(), opts, f)
+}
+
+func (db *sqlRepository) runTransaction(ctx context.Context, name string, opts *sql.TxOptions, f func(ctx context.Context, tx *sqlx.Tx) error) (err error) {
+	ctx, span := tracing.Start(ctx, "transaction "+name, db.system())
+	defer func(start time.Time) {
+		metrics.DBTransactionDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		tracing.End(span, err)
+	}(time.Now())
+
+	for attempt := 1; attempt <= maxTransactionAttempts; attempt++ {
+		err = db.tryTransaction(ctx, opts, f)
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+		metrics.DBTransactionRetries.WithLabelValues(name).Inc()
+		span.AddEvent("retry", trace.WithAttributes(attribute.String("error", err.Error())))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt*attempt) * 10 * time.Millisecond):
+		}
+	}
+	return err
+}
+
+func (db *sqlRepository) tryTransaction(ctx context.Context, opts *sql.TxOptions, f func(ctx context.Context, tx *sqlx.Tx) error) error {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := db.BeginTxx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
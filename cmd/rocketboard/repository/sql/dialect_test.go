@@ -0,0 +1,53 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func TestUpsert(t *testing.T) {
+	q := upsert("observations", "connectionid", []string{`"user"`, "state"}, "state=:state")
+	expected := `INSERT INTO observations ("user", state) VALUES (:user, :state) ON CONFLICT(connectionid) DO UPDATE SET state=:state`
+	if q != expected {
+		t.Fatal("Unexpected upsert:", q)
+	}
+}
+
+func TestTransactionRetry(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDatabase(t)
+
+	attempts := 0
+	err := db.transact(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		attempts++
+		if attempts < 3 {
+			return &pq.Error{Code: "40001"}
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Fatal("Expected serialization failures to be retried, got:", attempts, err)
+	}
+
+	attempts = 0
+	err = db.transact(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		attempts++
+		return errors.New("failed")
+	})
+	if err == nil || attempts != 1 {
+		t.Fatal("Expected other errors not to be retried, got:", attempts, err)
+	}
+
+	attempts = 0
+	err = db.transact(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		attempts++
+		return &pq.Error{Code: "40001"}
+	})
+	if err == nil || attempts != maxTransactionAttempts {
+		t.Fatal("Expected to give up after", maxTransactionAttempts, "attempts, got:", attempts, err)
+	}
+}
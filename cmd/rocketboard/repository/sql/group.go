@@ -0,0 +1,127 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+// NewCardGroup adds a group after the other groups of its column.
+func (db *sqlRepository) NewCardGroup(ctx context.Context, g *model.CardGroup) error {
+	return db.transact(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		if err := lockRetrospective(ctx, tx, g.RetrospectiveId); err != nil {
+			return err
+		}
+
+		var max int
+		err := tx.GetContext(ctx, &max, `SELECT COALESCE(MAX(position), 0) FROM cardgroups WHERE retrospectiveid=$1 AND "column"=$2`, g.RetrospectiveId, g.Column)
+		if err != nil {
+			return err
+		}
+		g.Position = max + IDX_SPACING
+
+		_, err = tx.NamedExecContext(ctx, `INSERT INTO cardgroups
+        (id, created, updated, retrospectiveid, "column", name, position)
+      VALUES (:id, :created, :updated, :retrospectiveid, :column, :name, :position)
+    `, g)
+		return err
+	})
+}
+
+func (db *sqlRepository) UpdateCardGroup(ctx context.Context, g *model.CardGroup) error {
+	res, err := db.NamedExecContext(ctx, `UPDATE cardgroups
+    SET updated=:updated, name=:name
+    WHERE id=:id
+  `, g)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return &model.NotFoundError{Kind: "card group", Id: g.Id}
+	}
+	return nil
+}
+
+// MoveCardGroup moves a group to index among the other groups of its column.
+func (db *sqlRepository) MoveCardGroup(ctx context.Context, g *model.CardGroup, index int) error {
+	if index < 0 {
+		return model.InputError("Cannot move to negative index")
+	}
+
+	return db.transact(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		if err := lockRetrospective(ctx, tx, g.RetrospectiveId); err != nil {
+			return err
+		}
+
+		gs := []*model.CardGroup{}
+		err := tx.SelectContext(ctx, &gs, `SELECT * FROM cardgroups WHERE retrospectiveid=$1 AND "column"=$2 AND id!=$3 ORDER BY position ASC, id ASC`, g.RetrospectiveId, g.Column, g.Id)
+		if err != nil {
+			return err
+		}
+
+		// Groups are ordered just like cards
+		cs := make([]*model.Card, len(gs))
+		for i, other := range gs {
+			cs[i] = &model.Card{Position: other.Position}
+		}
+		position, ok := positionAt(cs, index)
+		if !ok {
+			for i, other := range gs {
+				other.Position = IDX_SPACING * (i + 1)
+				cs[i].Position = other.Position
+				if _, err := tx.ExecContext(ctx, "UPDATE cardgroups SET position=$1 WHERE id=$2", other.Position, other.Id); err != nil {
+					return err
+				}
+			}
+			position, _ = positionAt(cs, index)
+		}
+		g.Position = position
+
+		_, err = tx.NamedExecContext(ctx, "UPDATE cardgroups SET updated=:updated, position=:position WHERE id=:id", g)
+		return err
+	})
+}
+
+// DeleteCardGroup dissolves a group, leaving its cards in its column.
+func (db *sqlRepository) DeleteCardGroup(ctx context.Context, g *model.CardGroup) error {
+	return db.transact(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		if err := lockRetrospective(ctx, tx, g.RetrospectiveId); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, "DELETE FROM cardgroups WHERE id=$1", g.Id)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return &model.NotFoundError{Kind: "card group", Id: g.Id}
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE cards SET groupid=NULL, version=version + 1 WHERE groupid=$1", g.Id)
+		if err != nil {
+			return err
+		}
+
+		// Cards were ordered within the group, so their positions may
+		// clash with the other cards of the column now
+		cs := []*model.Card{}
+		err = tx.SelectContext(ctx, &cs, `SELECT * FROM cards WHERE retrospectiveid=$1 AND "column"=$2 AND mergedinto IS NULL AND groupid IS NULL ORDER BY position ASC, id ASC`, g.RetrospectiveId, g.Column)
+		if err != nil {
+			return err
+		}
+		return renumber(ctx, tx, cs)
+	})
+}
+
+func (db *sqlRepository) GetCardGroupById(ctx context.Context, id string) (*model.CardGroup, error) {
+	var g model.CardGroup
+	err := db.GetContext(ctx, &g, "SELECT * FROM cardgroups WHERE id=$1", id)
+	return &g, notFound(err, "card group", id)
+}
+
+func (db *sqlRepository) GetCardGroupsByRetrospectiveId(ctx context.Context, id string) ([]*model.CardGroup, error) {
+	gs := []*model.CardGroup{}
+	err := db.SelectContext(ctx, &gs, `SELECT * FROM cardgroups WHERE retrospectiveid=$1 ORDER BY "column" ASC, position ASC, id ASC`, id)
+	return gs, err
+}
@@ -0,0 +1,129 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/metrics"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/tracing"
+)
+
+// The statements run outside of transactions go through these, which shadow
+// the methods of the embedded sqlx.DB to bound, trace and time them.
+// Statements within a transaction are part of its span and duration, see
+// transact.
+
+func (db *sqlRepository) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) (err error) {
+	ctx, done := db.instrument(ctx, query)
+	defer func() { done(err) }()
+	return db.DB.GetContext(ctx, dest, query, args...)
+}
+
+func (db *sqlRepository) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) (err error) {
+	ctx, done := db.instrument(ctx, query)
+	defer func() { done(err) }()
+	return db.DB.SelectContext(ctx, dest, query, args...)
+}
+
+func (db *sqlRepository) ExecContext(ctx context.Context, query string, args ...interface{}) (res sql.Result, err error) {
+	ctx, done := db.instrument(ctx, query)
+	defer func() { done(err) }()
+	return db.DB.ExecContext(ctx, query, args...)
+}
+
+func (db *sqlRepository) NamedExecContext(ctx context.Context, query string, arg interface{}) (res sql.Result, err error) {
+	ctx, done := db.instrument(ctx, query)
+	defer func() { done(err) }()
+	return db.DB.NamedExecContext(ctx, query, arg)
+}
+
+// instrument starts the span of a statement and applies the query timeout,
+// and returns a function ending both and recording the duration of the
+// statement.
+func (db *sqlRepository) instrument(ctx context.Context, query string) (context.Context, func(error)) {
+	name := statementName(query)
+	ctx, cancel := db.withTimeout(ctx)
+	ctx, span := tracing.Start(ctx, name, db.system(), semconv.DBStatementKey.String(query))
+	start := time.Now()
+	return ctx, func(err error) {
+		cancel()
+		metrics.DBQueryDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		if err == sql.ErrNoRows {
+			// Lookups of things that don't exist are not failures
+			err = nil
+		}
+		tracing.End(span, err)
+	}
+}
+
+func (db *sqlRepository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, db.QueryTimeout)
+}
+
+func (db *sqlRepository) system() attribute.KeyValue {
+	if db.dialect == sqliteDialect {
+		return semconv.DBSystemSqlite
+	}
+	return semconv.DBSystemPostgreSQL
+}
+
+// Statement names by query. The queries are constants, so this stays small.
+var statementNames sync.Map
+
+// statementName labels a query by its verb and the table it is about, such
+// as "SELECT cards", to keep the number of series bounded.
+func statementName(query string) string {
+	if name, ok := statementNames.Load(query); ok {
+		return name.(string)
+	}
+
+	fields := strings.Fields(query)
+	name := "unknown"
+	if len(fields) > 0 {
+		verb := strings.ToUpper(fields[0])
+		name = verb
+		var after string
+		switch verb {
+		case "SELECT", "DELETE":
+			after = "FROM"
+		case "INSERT":
+			after = "INTO"
+		case "UPDATE":
+			after = "UPDATE"
+		}
+		for i, f := range fields[:len(fields)-1] {
+			if after != "" && strings.ToUpper(f) == after {
+				name += " " + strings.Trim(fields[i+1], `"(;`)
+				break
+			}
+		}
+	}
+	statementNames.Store(query, name)
+	return name
+}
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+|\.\d+)+$`)
+
+// transactionName names a transaction after the repository method running
+// it, which called transact.
+func transactionName() string {
+	pc, _, _, ok := runtime.Caller(2)
+	if !ok {
+		return "unknown"
+	}
+	name := runtime.FuncForPC(pc).Name()
+	// Closures are named like the function they are in, with suffixes.
+	name = closureSuffix.ReplaceAllString(name, "")
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -0,0 +1,43 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestStatementName(t *testing.T) {
+	for query, expected := range map[string]string{
+		"SELECT * FROM cards WHERE id=$1":                         "SELECT cards",
+		`SELECT DISTINCT "user" FROM observations WHERE x=$1`:     "SELECT observations",
+		"INSERT INTO votes (id) VALUES (:id)":                     "INSERT votes",
+		"update cards\n    SET message=:message WHERE id=:id":     "UPDATE cards",
+		"DELETE FROM statuses WHERE cardid IN (SELECT id FROM c)": "DELETE statuses",
+		"CREATE TABLE things (id TEXT)":                           "CREATE",
+		"":                                                        "unknown",
+	} {
+		if name := statementName(query); name != expected {
+			t.Fatal("Expected", expected, "for", query, "got:", name)
+		}
+	}
+}
+
+func TestTransactionName(t *testing.T) {
+	var name string
+	record := func(f func(tx *sqlx.Tx) error) error {
+		name = transactionName()
+		return nil
+	}
+	record(nil)
+	if name != "TestTransactionName" {
+		t.Fatal("Expected transaction to be named after its caller, got:", name)
+	}
+
+	// Transactions started within closures are named after the function too
+	func() {
+		record(nil)
+	}()
+	if name != "TestTransactionName" {
+		t.Fatal("Expected transaction to be named after its caller, got:", name)
+	}
+}
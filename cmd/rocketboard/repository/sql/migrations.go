@@ -0,0 +1,578 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// statements of a migration by dialect. Statements stored under anyDialect
+// are used for every dialect without statements of its own.
+type statements map[string][]string
+
+func forAll(s ...string) statements {
+	return statements{anyDialect: s}
+}
+
+func (s statements) For(dialect string) []string {
+	if stmts, ok := s[dialect]; ok {
+		return stmts
+	}
+	return s[anyDialect]
+}
+
+type migration struct {
+	Version int
+	Name    string
+	Up      statements
+	Down    statements
+
+	// Legacy migrations were executed on every start before versions were
+	// tracked, and can be replayed when adopting such a database.
+	Legacy bool
+}
+
+// MigrationStatus describes a known migration and when it was applied, if at
+// all.
+type MigrationStatus struct {
+	Version int        `db:"version"`
+	Name    string     `db:"name"`
+	Applied *time.Time `db:"applied"`
+}
+
+const (
+	// The version of the schema_migrations row held by the instance that is
+	// migrating, which no migration has.
+	lockVersion = 0
+	// A lock held for longer was left behind by an instance that died while
+	// migrating, and is taken over.
+	lockTimeout = 15 * time.Minute
+	// How often an instance waiting for the lock tries to take it.
+	lockRetryInterval = time.Second
+)
+
+var versionTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+  version INTEGER PRIMARY KEY,
+  name TEXT,
+  applied TIMESTAMP
+);
+`
+
+// Migrations are applied in order of their version, each in its own
+// transaction. Never change a released migration, add a new one instead.
+var migrations = []migration{{
+	Version: 1,
+	Name:    "initial schema",
+	Up: forAll(`
+CREATE TABLE IF NOT EXISTS retrospectives (
+  id TEXT PRIMARY KEY,
+  created TIMESTAMP,
+  updated TIMESTAMP,
+  name TEXT
+)`, `
+CREATE TABLE IF NOT EXISTS cards (
+  id TEXT PRIMARY KEY,
+  created TIMESTAMP,
+  updated TIMESTAMP,
+  retrospectiveid TEXT,
+  message TEXT,
+  creator TEXT,
+  "column" TEXT,
+  position INTEGER
+)`, `
+CREATE TABLE IF NOT EXISTS votes (
+  id TEXT PRIMARY KEY,
+  created TIMESTAMP,
+  updated TIMESTAMP,
+  cardid TEXT,
+  voter TEXT,
+  count INTEGER
+)`, `
+CREATE TABLE IF NOT EXISTS statuses (
+  id TEXT PRIMARY KEY,
+  created TIMESTAMP,
+  cardid  TEXT,
+  type INTEGER
+)`, `
+CREATE TABLE IF NOT EXISTS observations (
+  "user" TEXT,
+  retrospectiveid TEXT,
+  connectionid TEXT,
+  state INTEGER,
+  firstseen TIMESTAMP,
+  lastseen TIMESTAMP
+)`,
+		`CREATE INDEX IF NOT EXISTS obs_retro ON observations(retrospectiveid)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS obs_connectionid ON observations(connectionid)`,
+		`CREATE INDEX IF NOT EXISTS obs_firstseen ON observations(firstseen)`,
+		`CREATE INDEX IF NOT EXISTS obs_lastseen ON observations(lastseen)`,
+	),
+	Down: forAll(
+		`DROP TABLE observations`,
+		`DROP TABLE statuses`,
+		`DROP TABLE votes`,
+		`DROP TABLE cards`,
+		`DROP TABLE retrospectives`,
+	),
+	Legacy: true,
+}, {
+	Version: 2,
+	Name:    "vote emoji",
+	Up:      forAll(`ALTER TABLE votes ADD emoji TEXT DEFAULT('clap')`),
+	Down:    forAll(`ALTER TABLE votes DROP COLUMN emoji`),
+	Legacy:  true,
+}, {
+	Version: 3,
+	Name:    "retrospective pet names",
+	Up:      forAll(`ALTER TABLE retrospectives ADD petname TEXT`),
+	Down:    forAll(`ALTER TABLE retrospectives DROP COLUMN petname`),
+	Legacy:  true,
+}, {
+	// Backfilling is kept apart from adding the column, as CockroachDB
+	// does not allow writing to a column added in the same transaction.
+	Version: 4,
+	Name:    "backfill retrospective pet names",
+	Up:      forAll(`UPDATE retrospectives SET petname = id WHERE petname IS NULL`),
+	Down:    forAll(),
+	Legacy:  true,
+}, {
+	Version: 5,
+	Name:    "unique retrospective pet names",
+	Up:      forAll(`CREATE UNIQUE INDEX IF NOT EXISTS retro_petname on retrospectives(petname)`),
+	Down: statements{
+		sqliteDialect:   {`DROP INDEX IF EXISTS retro_petname`},
+		postgresDialect: {`DROP INDEX IF EXISTS retro_petname CASCADE`},
+	},
+	Legacy: true,
+}, {
+	Version: 6,
+	Name:    "cards by retrospective",
+	Up:      forAll(`CREATE INDEX IF NOT EXISTS cards_retro on cards(retrospectiveid)`),
+	Down:    forAll(`DROP INDEX IF EXISTS cards_retro`),
+	Legacy:  true,
+}, {
+	Version: 7,
+	Name:    "merged cards",
+	Up:      forAll(`ALTER TABLE cards ADD mergedInto TEXT`),
+	Down:    forAll(`ALTER TABLE cards DROP COLUMN mergedInto`),
+	Legacy:  true,
+}, {
+	Version: 8,
+	Name:    "cards by merge parent",
+	Up:      forAll(`CREATE INDEX IF NOT EXISTS cards_mergedInto ON cards(mergedInto)`),
+	Down:    forAll(`DROP INDEX IF EXISTS cards_mergedInto`),
+	Legacy:  true,
+}, {
+	Version: 9,
+	Name:    "retrospective teams",
+	Up:      forAll(`ALTER TABLE retrospectives ADD team TEXT DEFAULT('')`),
+	Down:    forAll(`ALTER TABLE retrospectives DROP COLUMN team`),
+}, {
+	Version: 10,
+	Name:    "closed retrospectives",
+	Up:      forAll(`ALTER TABLE retrospectives ADD closed TIMESTAMP`),
+	Down:    forAll(`ALTER TABLE retrospectives DROP COLUMN closed`),
+}, {
+	Version: 11,
+	Name:    "webhooks",
+	Up: forAll(`
+CREATE TABLE IF NOT EXISTS webhooks (
+  id TEXT PRIMARY KEY,
+  created TIMESTAMP,
+  retrospectiveid TEXT,
+  team TEXT,
+  url TEXT,
+  secret TEXT,
+  events TEXT
+)`,
+		`CREATE INDEX IF NOT EXISTS webhooks_retro ON webhooks(retrospectiveid)`,
+		`CREATE INDEX IF NOT EXISTS webhooks_team ON webhooks(team)`, `
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id TEXT PRIMARY KEY,
+  created TIMESTAMP,
+  webhookid TEXT,
+  event TEXT,
+  payload TEXT,
+  state INTEGER,
+  attempts INTEGER,
+  nextattempt TIMESTAMP,
+  claim TEXT
+)`,
+		`CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook ON webhook_deliveries(webhookid)`,
+		`CREATE INDEX IF NOT EXISTS webhook_deliveries_due ON webhook_deliveries(state, nextattempt)`, `
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+  id TEXT PRIMARY KEY,
+  created TIMESTAMP,
+  deliveryid TEXT,
+  statuscode INTEGER,
+  error TEXT,
+  duration INTEGER
+)`,
+		`CREATE INDEX IF NOT EXISTS webhook_attempts_delivery ON webhook_attempts(deliveryid)`,
+	),
+	Down: forAll(
+		`DROP TABLE webhook_attempts`,
+		`DROP TABLE webhook_deliveries`,
+		`DROP TABLE webhooks`,
+	),
+}, {
+	Version: 12,
+	Name:    "teams",
+	Up: forAll(`
+CREATE TABLE IF NOT EXISTS teams (
+  name TEXT PRIMARY KEY,
+  created TIMESTAMP,
+  updated TIMESTAMP,
+  chatwebhookurl TEXT
+)`),
+	Down: forAll(`DROP TABLE teams`),
+}, {
+	Version: 13,
+	Name:    "card issue links",
+	Up:      forAll(`ALTER TABLE cards ADD issueurl TEXT`),
+	Down:    forAll(`ALTER TABLE cards DROP COLUMN issueurl`),
+}, {
+	// PostgreSQL folded the column to lower case already.
+	Version: 14,
+	Name:    "lower case merge parent column",
+	Up: statements{
+		sqliteDialect:   {`ALTER TABLE cards RENAME COLUMN mergedInto TO mergedinto`},
+		postgresDialect: {},
+	},
+	Down: statements{
+		sqliteDialect:   {`ALTER TABLE cards RENAME COLUMN mergedinto TO mergedInto`},
+		postgresDialect: {},
+	},
+}, {
+	Version: 15,
+	Name:    "card versions",
+	Up:      forAll(`ALTER TABLE cards ADD version INTEGER NOT NULL DEFAULT(0)`),
+	Down:    forAll(`ALTER TABLE cards DROP COLUMN version`),
+}, {
+	Version: 16,
+	Name:    "merge groups",
+	Up: forAll(
+		`ALTER TABLE cards ADD mergetitle TEXT`, `
+CREATE TABLE merges (
+  id TEXT PRIMARY KEY,
+  created TIMESTAMP,
+  cardid TEXT,
+  mergedinto TEXT,
+  "column" TEXT,
+  position INTEGER,
+  unmerged TIMESTAMP
+)`,
+		`CREATE INDEX merges_card ON merges(cardid)`,
+	),
+	Down: forAll(
+		`DROP TABLE merges`,
+		`ALTER TABLE cards DROP COLUMN mergetitle`,
+	),
+}, {
+	Version: 17,
+	Name:    "card groups",
+	Up: forAll(
+		`ALTER TABLE cards ADD groupid TEXT`, `
+CREATE TABLE cardgroups (
+  id TEXT PRIMARY KEY,
+  created TIMESTAMP,
+  updated TIMESTAMP,
+  retrospectiveid TEXT,
+  "column" TEXT,
+  name TEXT,
+  position INTEGER
+)`,
+		`CREATE INDEX cardgroups_retro ON cardgroups(retrospectiveid)`,
+	),
+	Down: forAll(
+		`DROP TABLE cardgroups`,
+		`ALTER TABLE cards DROP COLUMN groupid`,
+	),
+}, {
+	Version: 18,
+	Name:    "retention",
+	Up: statements{
+		sqliteDialect: {
+			`ALTER TABLE teams ADD retentiondays INTEGER`, `
+CREATE TABLE archives (
+  id TEXT PRIMARY KEY,
+  created TIMESTAMP,
+  name TEXT,
+  petname TEXT,
+  team TEXT,
+  closed TIMESTAMP,
+  snapshot BLOB
+)`,
+			`CREATE INDEX archives_team ON archives(team)`,
+		},
+		postgresDialect: {
+			`ALTER TABLE teams ADD retentiondays INTEGER`, `
+CREATE TABLE archives (
+  id TEXT PRIMARY KEY,
+  created TIMESTAMP,
+  name TEXT,
+  petname TEXT,
+  team TEXT,
+  closed TIMESTAMP,
+  snapshot BYTEA
+)`,
+			`CREATE INDEX archives_team ON archives(team)`,
+		},
+	},
+	Down: forAll(
+		`DROP TABLE archives`,
+		`ALTER TABLE teams DROP COLUMN retentiondays`,
+	),
+}, {
+	// Indexing is kept apart from adding the column, as CockroachDB does
+	// not allow using a column added in the same transaction.
+	Version: 19,
+	Name:    "cards by group",
+	Up:      forAll(`CREATE INDEX cards_group ON cards(groupid)`),
+	Down:    forAll(`DROP INDEX cards_group`),
+}, {
+	Version: 20,
+	Name:    "card export claims",
+	Up:      forAll(`ALTER TABLE cards ADD exportclaimed TIMESTAMP`),
+	Down:    forAll(`ALTER TABLE cards DROP COLUMN exportclaimed`),
+}}
+
+func init() {
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+}
+
+// Migrations run their statements on the embedded sqlx.DB, bypassing the
+// query timeout, as they may take a while on large tables. They are bounded by
+// ctx only.
+
+// lock makes other instances wait with migrating until the returned function
+// is called, by holding the lock row of schema_migrations. Unlike advisory
+// locks, this works the same on every database.
+func (db *sqlRepository) lock(ctx context.Context) (func(), error) {
+	waiting := false
+	for {
+		now := time.Now().UTC()
+		_, err := db.DB.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, applied) VALUES ($1, $2, $3)",
+			lockVersion, "lock", now)
+		if err == nil {
+			return func() {
+				// Released even when ctx is done, or others wait for lockTimeout
+				_, err := db.DB.ExecContext(context.Background(), "DELETE FROM schema_migrations WHERE version=$1", lockVersion)
+				if err != nil {
+					db.Logger.WithError(err).Error("Failed to release migration lock")
+				}
+			}, nil
+		}
+
+		var locked time.Time
+		if err := db.DB.GetContext(ctx, &locked, "SELECT applied FROM schema_migrations WHERE version=$1", lockVersion); err == sql.ErrNoRows {
+			// Released in the meantime
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if now.Sub(locked) > lockTimeout {
+			db.Logger.WithField("locked", locked).Warn("Taking over abandoned migration lock")
+			_, err := db.DB.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version=$1 AND applied<$2", lockVersion, now.Add(-lockTimeout))
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !waiting {
+			db.Logger.Info("Waiting for another instance to finish migrating")
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+func (db *sqlRepository) appliedVersions(ctx context.Context) (map[int]bool, error) {
+	versions := []int{}
+	err := db.DB.SelectContext(ctx, &versions, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	applied := map[int]bool{}
+	for _, v := range versions {
+		applied[v] = true
+	}
+	return applied, nil
+}
+
+// adoptLegacy takes over a database created before migrations were versioned,
+// by executing the legacy migrations the way they used to be, ignoring
+// errors, and recording them as applied. It must be called with the lock
+// held, so that only one instance adopts the database.
+func (db *sqlRepository) adoptLegacy(ctx context.Context) error {
+	var count int
+	if err := db.DB.GetContext(ctx, &count, "SELECT COUNT(*) FROM schema_migrations WHERE version<>$1", lockVersion); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	// Without any table of its own the database is new, not legacy.
+	if _, err := db.DB.ExecContext(ctx, "SELECT COUNT(*) FROM retrospectives"); err != nil {
+		return nil
+	}
+
+	db.Logger.Info("Adopting database created before versioned migrations")
+	for _, m := range migrations {
+		if !m.Legacy {
+			continue
+		}
+		for _, stmt := range m.Up.For(db.dialect) {
+			if _, err := db.DB.ExecContext(ctx, stmt); err != nil {
+				db.Logger.WithError(err).WithField("version", m.Version).Warn("Ignoring legacy migration")
+			}
+		}
+		_, err := db.DB.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, applied) VALUES ($1, $2, $3)",
+			m.Version, m.Name, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *sqlRepository) apply(ctx context.Context, m migration, up bool) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmts := m.Down.For(db.dialect)
+	if up {
+		stmts = m.Up.For(db.dialect)
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	// The version row doubles as a lock: when two instances race for the
+	// same migration, only one of them can commit.
+	if up {
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, applied) VALUES ($1, $2, $3)",
+			m.Version, m.Name, time.Now())
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version=$1", m.Version)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MigrateUp applies all pending migrations in order. It stops at the first
+// migration that fails, leaving the database at the last successful version.
+// Instances migrating the same database take turns.
+func (db *sqlRepository) MigrateUp(ctx context.Context) error {
+	if _, err := db.DB.ExecContext(ctx, versionTable); err != nil {
+		return err
+	}
+	unlock, err := db.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := db.adoptLegacy(ctx); err != nil {
+		return err
+	}
+
+	applied, err := db.appliedVersions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		err := db.apply(ctx, m, true)
+		if err != nil {
+			// Another instance may have applied it in the meantime
+			if applied, _ := db.appliedVersions(ctx); applied[m.Version] {
+				continue
+			}
+			return fmt.Errorf("migration %d (%s) failed: %s", m.Version, m.Name, err)
+		}
+		db.Logger.WithField("version", m.Version).Infof("Applied migration %d (%s)", m.Version, m.Name)
+	}
+	return nil
+}
+
+// MigrateDown reverts the latest steps applied migrations.
+func (db *sqlRepository) MigrateDown(ctx context.Context, steps int) error {
+	if _, err := db.DB.ExecContext(ctx, versionTable); err != nil {
+		return err
+	}
+	unlock, err := db.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	applied, err := db.appliedVersions(ctx)
+	if err != nil {
+		return err
+	}
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		m := migrations[i]
+		if !applied[m.Version] {
+			continue
+		}
+		if err := db.apply(ctx, m, false); err != nil {
+			return fmt.Errorf("reverting migration %d (%s) failed: %s", m.Version, m.Name, err)
+		}
+		db.Logger.WithField("version", m.Version).Infof("Reverted migration %d (%s)", m.Version, m.Name)
+		steps--
+	}
+	return nil
+}
+
+// MigrationStatus lists all known migrations along with when they were
+// applied.
+func (db *sqlRepository) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	if _, err := db.DB.ExecContext(ctx, versionTable); err != nil {
+		return nil, err
+	}
+	status := []MigrationStatus{}
+	for _, m := range migrations {
+		s := MigrationStatus{Version: m.Version, Name: m.Name}
+		var applied time.Time
+		err := db.DB.GetContext(ctx, &applied, "SELECT applied FROM schema_migrations WHERE version=$1", m.Version)
+		if err == nil {
+			s.Applied = &applied
+		} else if err != sql.ErrNoRows {
+			return nil, err
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
+
+// LatestVersion returns the version of the newest migration, which
+// NewRepository migrates databases to.
+func LatestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion returns the latest applied migration, or 0 for an empty
+// database.
+func (db *sqlRepository) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := db.GetContext(ctx, &version, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations")
+	return version, err
+}
@@ -0,0 +1,138 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func openTestDatabase(t *testing.T) *sqlRepository {
+	db, err := Open("sqlite3::memory:")
+	if err != nil {
+		t.Fatal("Failed to open database", err)
+	}
+	db.SetMaxOpenConns(1)
+	return db
+}
+
+func TestMigrationsAreAppliedOnce(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDatabase(t)
+	if err := db.MigrateUp(ctx); err != nil {
+		t.Fatal("Failed to migrate", err)
+	}
+	if err := db.MigrateUp(ctx); err != nil {
+		t.Fatal("Failed to migrate again", err)
+	}
+
+	var count int
+	db.Get(&count, "SELECT COUNT(*) FROM schema_migrations")
+	if count != len(migrations) {
+		t.Fatal("Expected", len(migrations), "applied migrations, got:", count)
+	}
+	version, _ := db.SchemaVersion(ctx)
+	if version != LatestVersion() {
+		t.Fatal("Expected latest schema version, got:", version)
+	}
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDatabase(t)
+	if err := db.MigrateUp(ctx); err != nil {
+		t.Fatal("Failed to migrate", err)
+	}
+
+	if err := db.MigrateDown(ctx, len(migrations)); err != nil {
+		t.Fatal("Failed to revert all migrations", err)
+	}
+	if version, _ := db.SchemaVersion(ctx); version != 0 {
+		t.Fatal("Expected empty database, got version:", version)
+	}
+	if _, err := db.Exec("SELECT * FROM retrospectives"); err == nil {
+		t.Fatal("Expected retrospectives to be dropped")
+	}
+
+	if err := db.MigrateUp(ctx); err != nil {
+		t.Fatal("Failed to migrate after reverting", err)
+	}
+	status, err := db.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatal("Failed to get status", err)
+	}
+	for _, s := range status {
+		if s.Applied == nil {
+			t.Fatal("Expected migration to be applied:", s.Version)
+		}
+	}
+}
+
+func TestFailingMigrationIsRolledBack(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDatabase(t)
+	defer func(ms []migration) { migrations = ms }(migrations)
+	migrations = append(migrations[:len(migrations):len(migrations)], migration{
+		Version: 10000,
+		Name:    "broken",
+		Up:      forAll(`CREATE TABLE broken (id TEXT)`, `INVALID SQL`),
+	})
+
+	if err := db.MigrateUp(ctx); err == nil {
+		t.Fatal("Expected broken migration to fail")
+	}
+	if _, err := db.Exec("SELECT * FROM broken"); err == nil {
+		t.Fatal("Expected broken migration to be rolled back")
+	}
+	if version, _ := db.SchemaVersion(ctx); version != migrations[len(migrations)-2].Version {
+		t.Fatal("Expected database at last good version, got:", version)
+	}
+}
+
+func TestAdoptLegacyDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDatabase(t)
+	// Part of the schema as created before migrations were versioned
+	for _, m := range migrations[:3] {
+		for _, stmt := range m.Up.For(db.dialect) {
+			if _, err := db.Exec(stmt); err != nil {
+				t.Fatal("Failed to create legacy schema", err)
+			}
+		}
+	}
+	db.MustExec("INSERT INTO retrospectives (id, created, updated, name) VALUES ($1, $2, $2, $3)", "legacy", time.Now(), "Legacy")
+
+	if err := db.MigrateUp(ctx); err != nil {
+		t.Fatal("Failed to adopt legacy database", err)
+	}
+	r, err := db.GetRetrospectiveById(ctx, "legacy")
+	if err != nil || r.PetName != "legacy" {
+		t.Fatal("Expected legacy retrospective to be migrated, got:", r, err)
+	}
+}
+
+func TestMigrationsWaitForLock(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDatabase(t)
+	db.MustExec(versionTable)
+	db.MustExec("INSERT INTO schema_migrations (version, name, applied) VALUES ($1, $2, $3)", lockVersion, "lock", time.Now().UTC())
+
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := db.MigrateUp(waitCtx); err != context.DeadlineExceeded {
+		t.Fatal("Expected migrating to wait for the lock, got:", err)
+	}
+	if version, _ := db.SchemaVersion(ctx); version != lockVersion {
+		t.Fatal("Expected no migrations while locked, got version:", version)
+	}
+
+	// An abandoned lock is taken over
+	db.MustExec("UPDATE schema_migrations SET applied=$1 WHERE version=$2", time.Now().UTC().Add(-2*lockTimeout), lockVersion)
+	if err := db.MigrateUp(ctx); err != nil {
+		t.Fatal("Failed to migrate", err)
+	}
+	var count int
+	db.Get(&count, "SELECT COUNT(*) FROM schema_migrations WHERE version=$1", lockVersion)
+	if count != 0 {
+		t.Fatal("Expected the lock to be released")
+	}
+}
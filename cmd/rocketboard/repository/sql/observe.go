@@ -1,21 +1,20 @@
 package sql
 
 import (
-	"log"
+	"context"
 	"time"
 
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/logging"
 	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
 )
 
-func (db *sqlRepository) getObservation(connectionid string) (*model.Observation, error) {
+func (db *sqlRepository) getObservation(ctx context.Context, connectionid string) (*model.Observation, error) {
 	var observation model.Observation
-	err := db.Get(&observation, "SELECT * FROM observation WHERE connectionid=$1", connectionid)
+	err := db.GetContext(ctx, &observation, "SELECT * FROM observations WHERE connectionid=$1", connectionid)
 	return &observation, err
 }
 
-func (db *sqlRepository) Observe(connectionid string, user string, retrospectiveId string, state string) (bool, error) {
-	tx := db.MustBegin()
-	defer tx.Commit()
+func (db *sqlRepository) Observe(ctx context.Context, connectionid string, user string, retrospectiveId string, state string) (bool, error) {
 	userState, _ := model.UserStateTypeString(state)
 
 	observation := model.Observation{
@@ -26,31 +25,32 @@ func (db *sqlRepository) Observe(connectionid string, user string, retrospective
 		FirstSeen:       time.Now(),
 		LastSeen:        time.Now(),
 	}
-	oldObservation, err := db.getObservation(connectionid)
+	oldObservation, err := db.getObservation(ctx, connectionid)
 
 	changed := err != nil || observation.State != oldObservation.State
 
-	_, err = db.NamedExec(`INSERT INTO observations
-      ("user", retrospectiveid, connectionid, state, firstseen, lastseen)
-      VALUES (:user, :retrospectiveid, :connectionid, :state, :firstseen, :lastseen)
-      ON CONFLICT(connectionid) DO UPDATE SET lastseen=:lastseen, state=:state
-    `, observation)
+	_, err = db.NamedExecContext(ctx, upsert("observations", "connectionid",
+		[]string{`"user"`, "retrospectiveid", "connectionid", "state", "firstseen", "lastseen"},
+		"lastseen=:lastseen, state=:state",
+	), observation)
 	if err != nil {
-		log.Println("Error saving observation", err)
+		logging.Retro(ctx, db.Logger, retrospectiveId).WithError(err).Error("Failed to save observation")
 	}
 
 	return changed, err
 }
 
-func (db *sqlRepository) ClearObservations(connectionid string) {
-	db.Exec(`DELETE FROM observations WHERE connectionid=$1`, connectionid)
+func (db *sqlRepository) ClearObservations(ctx context.Context, connectionid string) {
+	db.ExecContext(ctx, `DELETE FROM observations WHERE connectionid=$1`, connectionid)
 }
 
-func (db *sqlRepository) GetActiveUsers(retrospectiveId string) ([]model.UserState, error) {
+func (db *sqlRepository) GetActiveUsers(ctx context.Context, retrospectiveId string) ([]model.UserState, error) {
 	var userStates []model.UserState
-	err := db.Unsafe().Select(&userStates, "SELECT DISTINCT \"user\", max(state) as state, min(firstseen) as firstseen FROM observations WHERE retrospectiveid=$1 AND lastseen > $2 GROUP BY \"user\" ORDER BY firstseen ASC", retrospectiveId, time.Now().Add(-10*time.Second))
+	unsafe := *db
+	unsafe.DB = db.Unsafe()
+	err := unsafe.SelectContext(ctx, &userStates, "SELECT DISTINCT \"user\", max(state) as state, min(firstseen) as firstseen FROM observations WHERE retrospectiveid=$1 AND lastseen > $2 GROUP BY \"user\" ORDER BY firstseen ASC", retrospectiveId, time.Now().Add(-db.PresenceWindow))
 	if err != nil {
-		log.Println("Failed to select active users:", err)
+		logging.Retro(ctx, db.Logger, retrospectiveId).WithError(err).Error("Failed to select active users")
 	}
 	return userStates, err
 }
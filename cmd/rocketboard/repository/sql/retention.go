@@ -0,0 +1,132 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+func (db *sqlRepository) GetTeams(ctx context.Context) ([]*model.Team, error) {
+	ts := []*model.Team{}
+	err := db.SelectContext(ctx, &ts, "SELECT * FROM teams ORDER BY name")
+	return ts, err
+}
+
+// GetClosedRetrospectives returns the retrospectives closed before the given
+// time, oldest first.
+func (db *sqlRepository) GetClosedRetrospectives(ctx context.Context, before time.Time) ([]*model.Retrospective, error) {
+	rs := []*model.Retrospective{}
+	err := db.SelectContext(ctx, &rs, "SELECT * FROM retrospectives WHERE closed IS NOT NULL AND closed < $1 ORDER BY closed, id", before)
+	return rs, err
+}
+
+// ArchiveRetrospective stores the archive and deletes everything recorded
+// about its retrospective, in one transaction. An archive without a snapshot
+// gets one taken in the same transaction, which is serializable so that
+// nothing written to the retrospective in the meantime is purged without
+// being archived. Archiving a retrospective again replaces its archive.
+func (db *sqlRepository) ArchiveRetrospective(ctx context.Context, a *model.Archive) error {
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	return db.transactWith(ctx, opts, func(ctx context.Context, tx *sqlx.Tx) error {
+		archive := *a
+		if archive.Snapshot == nil {
+			s, err := snapshot(ctx, tx, a.Id)
+			if err != nil {
+				return err
+			}
+			if archive.Snapshot, err = s.Compress(); err != nil {
+				return err
+			}
+		}
+		_, err := tx.NamedExecContext(ctx, upsert("archives", "id",
+			[]string{"id", "created", "name", "petname", "team", "closed", "snapshot"},
+			"created=:created, snapshot=:snapshot",
+		), &archive)
+		if err != nil {
+			return err
+		}
+
+		purge := []string{
+			`DELETE FROM votes WHERE cardid IN (SELECT id FROM cards WHERE retrospectiveid=$1)`,
+			`DELETE FROM statuses WHERE cardid IN (SELECT id FROM cards WHERE retrospectiveid=$1)`,
+			`DELETE FROM merges WHERE cardid IN (SELECT id FROM cards WHERE retrospectiveid=$1)`,
+			`DELETE FROM cards WHERE retrospectiveid=$1`,
+			`DELETE FROM cardgroups WHERE retrospectiveid=$1`,
+			`DELETE FROM observations WHERE retrospectiveid=$1`,
+			`DELETE FROM webhook_attempts WHERE deliveryid IN (SELECT d.id FROM webhook_deliveries d JOIN webhooks w ON d.webhookid=w.id WHERE w.retrospectiveid=$1)`,
+			`DELETE FROM webhook_deliveries WHERE webhookid IN (SELECT id FROM webhooks WHERE retrospectiveid=$1)`,
+			`DELETE FROM webhooks WHERE retrospectiveid=$1`,
+			`DELETE FROM retrospectives WHERE id=$1`,
+		}
+		for _, stmt := range purge {
+			if _, err := tx.ExecContext(ctx, stmt, a.Id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// snapshot reads everything recorded about the retrospective with the given
+// id, within the transaction q so that the snapshot is consistent.
+func snapshot(ctx context.Context, q sqlx.QueryerContext, id string) (*model.Snapshot, error) {
+	s := &model.Snapshot{
+		Retrospective: &model.Retrospective{},
+		Cards:         []*model.Card{},
+		Groups:        []*model.CardGroup{},
+		Merges:        []*model.Merge{},
+		Votes:         []*model.Vote{},
+		Statuses:      []*model.Status{},
+	}
+	err := sqlx.GetContext(ctx, q, s.Retrospective, "SELECT * FROM retrospectives WHERE id=$1", id)
+	if err != nil {
+		return nil, notFound(err, "retrospective", id)
+	}
+
+	reads := []struct {
+		dest  interface{}
+		query string
+	}{
+		{&s.Cards, `SELECT * FROM cards WHERE retrospectiveid=$1 ORDER BY position ASC, id ASC`},
+		{&s.Groups, `SELECT * FROM cardgroups WHERE retrospectiveid=$1 ORDER BY "column" ASC, position ASC, id ASC`},
+		{&s.Merges, `SELECT * FROM merges WHERE cardid IN (SELECT id FROM cards WHERE retrospectiveid=$1) ORDER BY created ASC, id ASC`},
+		{&s.Votes, `SELECT * FROM votes WHERE cardid IN (SELECT id FROM cards WHERE retrospectiveid=$1) ORDER BY created ASC, id ASC`},
+		{&s.Statuses, `SELECT * FROM statuses WHERE cardid IN (SELECT id FROM cards WHERE retrospectiveid=$1) ORDER BY created ASC, id ASC`},
+	}
+	for _, r := range reads {
+		if err := sqlx.SelectContext(ctx, q, r.dest, r.query, id); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// GetArchivesByTeam lists the archives of a team, most recently closed
+// first, without their snapshots.
+func (db *sqlRepository) GetArchivesByTeam(ctx context.Context, team string) ([]*model.Archive, error) {
+	as := []*model.Archive{}
+	err := db.SelectContext(ctx, &as, "SELECT id, created, name, petname, team, closed FROM archives WHERE team=$1 ORDER BY closed DESC, id", team)
+	return as, err
+}
+
+func (db *sqlRepository) GetArchiveById(ctx context.Context, id string) (*model.Archive, error) {
+	var a model.Archive
+	err := db.GetContext(ctx, &a, "SELECT * FROM archives WHERE id=$1", id)
+	return &a, notFound(err, "archive", id)
+}
+
+// PurgeObservations deletes observations last seen before the given time,
+// which were left behind by instances that stopped without clearing them,
+// and returns how many were deleted.
+func (db *sqlRepository) PurgeObservations(ctx context.Context, before time.Time) (int, error) {
+	res, err := db.ExecContext(ctx, "DELETE FROM observations WHERE lastseen < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
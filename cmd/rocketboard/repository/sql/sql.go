@@ -1,11 +1,14 @@
 package sql
 
 import (
-	"fmt"
+	"context"
+	"database/sql"
 	"github.com/jmoiron/sqlx"
-	"log"
+	"github.com/sirupsen/logrus"
 	"math"
 	"net/url"
+	"sort"
+	"time"
 
 	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
@@ -15,75 +18,28 @@ import (
 
 type sqlRepository struct {
 	*sqlx.DB
+	dialect string
+
+	// Statements outside of transactions and attempts of transactions are
+	// cancelled after QueryTimeout, 0 lets them run for as long as the
+	// context they are run with allows.
+	QueryTimeout time.Duration
+	Logger       logrus.FieldLogger
+
+	// Retrospectives hold at most MaxCards cards, and users are present in
+	// a retrospective for PresenceWindow after they were last seen.
+	MaxCards       int
+	PresenceWindow time.Duration
 }
 
-var schema = `
-CREATE TABLE IF NOT EXISTS retrospectives (
-  id TEXT PRIMARY KEY,
-  created TIMESTAMP,
-  updated TIMESTAMP,
-  name TEXT
-);
-CREATE TABLE IF NOT EXISTS cards (
-  id TEXT PRIMARY KEY,
-  created TIMESTAMP,
-  updated TIMESTAMP,
-  retrospectiveid TEXT,
-  message TEXT,
-  creator TEXT,
-  "column" TEXT,
-  position INTEGER
-);
-CREATE TABLE IF NOT EXISTS votes (
-  id TEXT PRIMARY KEY,
-  created TIMESTAMP,
-  updated TIMESTAMP,
-  cardid TEXT,
-  voter TEXT,
-  count INTEGER
-);
-CREATE TABLE IF NOT EXISTS statuses (
-  id TEXT PRIMARY KEY,
-  created TIMESTAMP,
-  cardid  TEXT,
-  type INTEGER
-);
-CREATE TABLE IF NOT EXISTS observations (
-  "user" TEXT,
-  retrospectiveid TEXT,
-  connectionid TEXT,
-  state INTEGER,
-  firstseen TIMESTAMP,
-  lastseen TIMESTAMP
-);
-CREATE INDEX IF NOT EXISTS obs_retro ON observations(retrospectiveid);
-CREATE UNIQUE INDEX IF NOT EXISTS obs_connectionid ON observations(connectionid);
-CREATE INDEX IF NOT EXISTS obs_firstseen ON observations(firstseen);
-CREATE INDEX IF NOT EXISTS obs_lastseen ON observations(lastseen);
-`
-
-var migrations = []string{`
-  ALTER TABLE votes ADD
-    emoji TEXT DEFAULT('clap');
-  `, `
-  ALTER TABLE retrospectives ADD
-    petname TEXT;
-  UPDATE retrospectives SET petname = id WHERE petname IS NULL;
-  CREATE UNIQUE INDEX IF NOT EXISTS retro_petname on retrospectives(petname);
-  `, `
-  CREATE INDEX IF NOT EXISTS cards_retro on cards(retrospectiveid);
-	`, `
-	ALTER TABLE cards ADD
-    mergedInto TEXT;
-  `, `
-	CREATE INDEX IF NOT EXISTS cards_mergedInto ON cards(mergedInto);
-  `,
-}
+// DefaultQueryTimeout is the QueryTimeout of repositories opened by Open.
+const DefaultQueryTimeout = 10 * time.Second
 
 // Space elements by 2^15, which allows for 15 divisions before re-sorting
 var IDX_SPACING = int(math.Exp2(15))
 
-func NewRepository(dbURI string) (*sqlRepository, error) {
+// Open connects to the database without migrating it.
+func Open(dbURI string) (*sqlRepository, error) {
 	url, err := url.Parse(dbURI)
 	if err != nil {
 		return nil, err
@@ -104,203 +60,474 @@ func NewRepository(dbURI string) (*sqlRepository, error) {
 		return nil, err
 	}
 
-	_, err = db.Exec(schema)
+	return &sqlRepository{
+		DB:             db,
+		dialect:        dialectOf(dbDriver),
+		QueryTimeout:   DefaultQueryTimeout,
+		Logger:         logrus.StandardLogger(),
+		MaxCards:       100,
+		PresenceWindow: 10 * time.Second,
+	}, nil
+}
+
+// NewRepository connects to the database and applies all pending migrations.
+func NewRepository(dbURI string) (*sqlRepository, error) {
+	db, err := Open(dbURI)
 	if err != nil {
 		return nil, err
 	}
-
-	for _, migration := range migrations {
-		_, err = db.Exec(migration)
-		if err != nil {
-			log.Println(err)
-		}
+	if err := db.MigrateUp(context.Background()); err != nil {
+		db.Close()
+		return nil, err
 	}
-	return &sqlRepository{db}, nil
+	return db, nil
 }
 
-func (db *sqlRepository) NewRetrospective(r *model.Retrospective) error {
-	_, err := db.NamedExec("INSERT INTO retrospectives (id, created, updated, name, petname) VALUES (:id, :created, :updated, :name, :petname)", r)
+func (db *sqlRepository) NewRetrospective(ctx context.Context, r *model.Retrospective) error {
+	_, err := db.NamedExecContext(ctx, "INSERT INTO retrospectives (id, created, updated, name, petname, team) VALUES (:id, :created, :updated, :name, :petname, :team)", r)
 	return err
 }
 
-func (db *sqlRepository) GetRetrospectiveById(id string) (*model.Retrospective, error) {
+func (db *sqlRepository) UpdateRetrospective(ctx context.Context, r *model.Retrospective) error {
+	_, err := db.NamedExecContext(ctx, `UPDATE retrospectives
+    SET updated=:updated, name=:name, team=:team, closed=:closed
+    WHERE id=:id
+  `, r)
+	return err
+}
+
+func (db *sqlRepository) GetRetrospectiveById(ctx context.Context, id string) (*model.Retrospective, error) {
 	var r model.Retrospective
-	err := db.Get(&r, "SELECT * FROM retrospectives WHERE id=$1", id)
-	return &r, err
+	err := db.GetContext(ctx, &r, "SELECT * FROM retrospectives WHERE id=$1", id)
+	return &r, notFound(err, "retrospective", id)
 }
 
-func (db *sqlRepository) GetRetrospectiveByPetName(petName string) (*model.Retrospective, error) {
+func (db *sqlRepository) GetRetrospectiveByPetName(ctx context.Context, petName string) (*model.Retrospective, error) {
 	var r model.Retrospective
-	err := db.Get(&r, "SELECT * FROM retrospectives WHERE petname=$1", petName)
-	return &r, err
+	err := db.GetContext(ctx, &r, "SELECT * FROM retrospectives WHERE petname=$1", petName)
+	return &r, notFound(err, "retrospective", petName)
 }
 
-func (db *sqlRepository) NewCard(c *model.Card) error {
-	var count int
-	var min int
-	err := db.Get(&count, `SELECT COUNT(*) FROM cards WHERE retrospectiveid=$1`, c.RetrospectiveId)
-	if err != nil {
-		return err
-	}
-	err = db.Get(&min, `SELECT min(position) FROM cards WHERE retrospectiveid=$1 AND "column"=$2`, c.RetrospectiveId, c.Column)
-	if err != nil {
-		min = 0
-	}
+func (db *sqlRepository) GetTeam(ctx context.Context, name string) (*model.Team, error) {
+	var t model.Team
+	err := db.GetContext(ctx, &t, "SELECT * FROM teams WHERE name=$1", name)
+	return &t, notFound(err, "team", name)
+}
 
-	if count > 100 {
-		return fmt.Errorf("too many cards")
-	}
-	c.Position = min - IDX_SPACING
+func (db *sqlRepository) SaveTeam(ctx context.Context, t *model.Team) error {
+	_, err := db.NamedExecContext(ctx, upsert("teams", "name",
+		[]string{"name", "created", "updated", "chatwebhookurl", "retentiondays"},
+		"updated=:updated, chatwebhookurl=:chatwebhookurl, retentiondays=:retentiondays",
+	), t)
+	return err
+}
 
-	_, err = db.NamedExec(`INSERT INTO cards
-      (id, created, updated, retrospectiveid, message, creator, "column", position)
-    VALUES (:id, :created, :updated, :retrospectiveid, :message, :creator, :column, :position)
-  `, c)
+// lockRetrospective serializes changes to the card order of a retrospective,
+// by writing to its row before reading any positions. Concurrent transactions
+// wait for the lock, or fail with a conflict and are retried by transact.
+func lockRetrospective(ctx context.Context, tx *sqlx.Tx, rId string) error {
+	_, err := tx.ExecContext(ctx, "UPDATE retrospectives SET updated=updated WHERE id=$1", rId)
 	return err
 }
 
-func (db *sqlRepository) reorderColumn(rId string, column string) {
-	tx := db.MustBegin()
-	defer tx.Rollback()
-	cs := []*model.Card{}
-	err := tx.Select(&cs, `SELECT * FROM cards WHERE retrospectiveid=$1 AND "column"=$2 ORDER BY position ASC`, rId, column)
-	if err != nil {
-		log.Println("ERROR: Error reordering", err)
-	}
+func (db *sqlRepository) NewCard(ctx context.Context, c *model.Card) error {
+	return db.transact(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		if err := lockRetrospective(ctx, tx, c.RetrospectiveId); err != nil {
+			return err
+		}
 
-	for i, c := range cs {
-		c.Position = IDX_SPACING * (i + 1)
+		var count int
+		var min int
+		err := tx.GetContext(ctx, &count, `SELECT COUNT(*) FROM cards WHERE retrospectiveid=$1`, c.RetrospectiveId)
+		if err != nil {
+			return err
+		}
+		err = tx.GetContext(ctx, &min, `SELECT COALESCE(MIN(position), 0) FROM cards WHERE retrospectiveid=$1 AND "column"=$2 AND mergedinto IS NULL AND groupid IS NULL`, c.RetrospectiveId, c.Column)
+		if err != nil {
+			return err
+		}
+
+		if count > db.MaxCards {
+			return model.InputError("too many cards")
+		}
+		c.Position = min - IDX_SPACING
 
-		_, err := tx.NamedExec(`UPDATE cards
-      SET updated=:updated, retrospectiveid=:retrospectiveid, message=:message, creator=:creator, "column"=:column, position=:position
-      WHERE id=:id
+		_, err = tx.NamedExecContext(ctx, `INSERT INTO cards
+        (id, created, updated, retrospectiveid, message, creator, "column", position)
+      VALUES (:id, :created, :updated, :retrospectiveid, :message, :creator, :column, :position)
     `, c)
+		return err
+	})
+}
 
+// positionAt returns the position that puts a card at index among cards
+// ordered by position, or false if there is no room left between its
+// neighbours.
+func positionAt(cs []*model.Card, index int) (int, bool) {
+	var position int
+	if len(cs) == 0 {
+		position = IDX_SPACING
+	} else if index >= len(cs) {
+		position = cs[len(cs)-1].Position + IDX_SPACING
+	} else if index == 0 {
+		position = cs[0].Position - IDX_SPACING
+	} else {
+		position = (cs[index].Position + cs[index-1].Position) / 2
+		if position <= cs[index-1].Position || position >= cs[index].Position {
+			return 0, false
+		}
+	}
+	return position, position >= -int(math.Exp2(30)) && position <= int(math.Exp2(30))
+}
+
+// renumber spaces out the positions of cards ordered by position again.
+func renumber(ctx context.Context, tx *sqlx.Tx, cs []*model.Card) error {
+	for i, c := range cs {
+		c.Position = IDX_SPACING * (i + 1)
+		_, err := tx.ExecContext(ctx, "UPDATE cards SET position=$1 WHERE id=$2", c.Position, c.Id)
 		if err != nil {
-			log.Println("ERROR: Failed to reorder card indexes", err)
+			return err
 		}
 	}
-	err = tx.Commit()
-	if err != nil {
-		log.Println("ERROR: Error reordering", err)
+	return nil
+}
+
+// siblings returns the cards c is ordered among, leaving out c itself: the
+// other cards merged into the same card, the other cards of its group, or
+// else the top level cards of column outside of groups.
+func siblings(ctx context.Context, tx *sqlx.Tx, c *model.Card, column string) ([]*model.Card, error) {
+	cs := []*model.Card{}
+	if c.MergedInto != nil {
+		err := tx.SelectContext(ctx, &cs, `SELECT * FROM cards WHERE mergedinto=$1 AND id!=$2 ORDER BY position ASC, id ASC`, *c.MergedInto, c.Id)
+		return cs, err
+	}
+	if c.GroupId != nil {
+		err := tx.SelectContext(ctx, &cs, `SELECT * FROM cards WHERE groupid=$1 AND mergedinto IS NULL AND id!=$2 ORDER BY position ASC, id ASC`, *c.GroupId, c.Id)
+		return cs, err
 	}
+	err := tx.SelectContext(ctx, &cs, `SELECT * FROM cards WHERE retrospectiveid=$1 AND "column"=$2 AND mergedinto IS NULL AND groupid IS NULL AND id!=$3 ORDER BY position ASC, id ASC`, c.RetrospectiveId, column, c.Id)
+	return cs, err
 }
 
-func (db *sqlRepository) MergeCard(c *model.Card, mergedInto string) error {
-	c.MergedInto = &mergedInto
-	tx := db.MustBegin()
-	defer tx.Rollback()
+// place sets the position of c to index among its siblings cs, renumbering
+// them if there is no room left.
+func place(ctx context.Context, tx *sqlx.Tx, c *model.Card, cs []*model.Card, index int) error {
+	position, ok := positionAt(cs, index)
+	if !ok {
+		if err := renumber(ctx, tx, cs); err != nil {
+			return err
+		}
+		position, _ = positionAt(cs, index)
+	}
+	c.Position = position
+	return nil
+}
 
-	_, err := tx.NamedExec(`UPDATE cards
-    SET mergedInto=:mergedInto
-    WHERE id=:id
-  `, c)
+// openMerge returns the merge that put the card with the given id where it is.
+func openMerge(ctx context.Context, tx *sqlx.Tx, id string) (*model.Merge, error) {
+	var m model.Merge
+	err := tx.GetContext(ctx, &m, `SELECT * FROM merges WHERE cardid=$1 AND unmerged IS NULL ORDER BY created DESC, id DESC LIMIT 1`, id)
+	return &m, err
+}
 
-	if err != nil {
-		return err
-	}
+func (db *sqlRepository) MergeCard(ctx context.Context, c *model.Card, m *model.Merge) error {
+	var merged *model.Card
+	err := db.transact(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		merged = nil
+		if err := lockRetrospective(ctx, tx, c.RetrospectiveId); err != nil {
+			return err
+		}
 
-	for _, card := range c.MergedCards {
-		card.MergedInto = &mergedInto
-		_, err := tx.NamedExec(`UPDATE cards
-	    SET mergedInto=:mergedInto
-	    WHERE id=:id
-	  `, card)
+		cs := []*model.Card{}
+		err := tx.SelectContext(ctx, &cs, "SELECT * FROM cards WHERE retrospectiveid=$1 ORDER BY position ASC, id ASC", c.RetrospectiveId)
+		if err != nil {
+			return err
+		}
+		cards := map[string]*model.Card{}
+		children := []*model.Card{}
+		for _, card := range cs {
+			cards[card.Id] = card
+			if card.MergedInto != nil && *card.MergedInto == m.MergedInto {
+				children = append(children, card)
+			}
+		}
+		card, ok := cards[c.Id]
+		if !ok {
+			return &model.NotFoundError{Kind: "card", Id: c.Id}
+		}
+		if err := model.CheckMerge(cards, card.Id, m.MergedInto); err != nil {
+			return err
+		}
+		if card.MergedInto != nil && *card.MergedInto == m.MergedInto {
+			return nil
+		}
+
+		// A card moved between groups goes back to where it was before
+		// it was first merged.
+		m.Column = card.Column
+		m.Position = card.Position
+		if card.MergedInto != nil {
+			previous, err := openMerge(ctx, tx, card.Id)
+			if err == nil {
+				m.Column = previous.Column
+				m.Position = previous.Position
+			} else if err != sql.ErrNoRows {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE merges SET unmerged=$1 WHERE cardid=$2 AND unmerged IS NULL`, m.Created, card.Id); err != nil {
+			return err
+		}
+		_, err = tx.NamedExecContext(ctx, `INSERT INTO merges
+        (id, created, cardid, mergedinto, "column", position)
+      VALUES (:id, :created, :cardid, :mergedinto, :column, :position)
+    `, m)
 		if err != nil {
 			return err
 		}
-	}
 
-	return tx.Commit()
+		// Merged cards leave their group, and are put back outside of
+		// it when unmerged
+		card.MergedInto = &m.MergedInto
+		card.GroupId = nil
+		if err := place(ctx, tx, card, children, len(children)); err != nil {
+			return err
+		}
+		if err := updateCard(ctx, tx, card); err != nil {
+			return err
+		}
+		merged = card
+		return nil
+	})
+	if err != nil || merged == nil {
+		return err
+	}
+	c.MergedInto = merged.MergedInto
+	c.GroupId = nil
+	c.Position = merged.Position
+	c.Version = merged.Version + 1
+	return nil
 }
 
-func (db *sqlRepository) UnmergeCard(c *model.Card) error {
-	_, err := db.NamedExec(`UPDATE cards
-    SET mergedInto=NULL
-    WHERE id=:id
-  `, c)
+// UnmergeCard takes a card out of its merge group, and puts it back where it
+// was on the board before it was merged.
+func (db *sqlRepository) UnmergeCard(ctx context.Context, c *model.Card) error {
+	var card *model.Card
+	err := db.transact(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		if err := lockRetrospective(ctx, tx, c.RetrospectiveId); err != nil {
+			return err
+		}
+		var err error
+		card, err = unmerge(ctx, tx, c.Id)
+		return err
+	})
 	if err != nil {
-		panic(err)
+		return err
 	}
-
-	return err
+	c.MergedInto = nil
+	c.Column = card.Column
+	c.Position = card.Position
+	c.Version = card.Version + 1
+	return nil
 }
 
-func (db *sqlRepository) MoveCard(c *model.Card, column string, index int) error {
-	cs := []*model.Card{}
-	tx := db.MustBegin()
-	defer tx.Rollback()
-	err := tx.Select(&cs, `SELECT * FROM cards WHERE retrospectiveid=$1 AND "column"=$2 AND id!=$3 ORDER BY position ASC LIMIT $4`, c.RetrospectiveId, column, c.Id, index+1)
+// UnmergeAll takes every card out of the merge group headed by c in one
+// transaction, and puts each back where it was on the board before it was
+// merged.
+func (db *sqlRepository) UnmergeAll(ctx context.Context, c *model.Card) error {
+	var head model.Card
+	err := db.transact(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		if err := lockRetrospective(ctx, tx, c.RetrospectiveId); err != nil {
+			return err
+		}
+
+		// Cards merged into merged cards are unmerged as well
+		ids := []string{c.Id}
+		for i := 0; i < len(ids); i++ {
+			var merged []string
+			err := tx.SelectContext(ctx, &merged, "SELECT id FROM cards WHERE mergedinto=$1 ORDER BY position ASC, id ASC", ids[i])
+			if err != nil {
+				return err
+			}
+			ids = append(ids, merged...)
+		}
+		if len(ids) == 1 {
+			return model.InputError("No cards are merged into the card")
+		}
+		for _, id := range ids[1:] {
+			if _, err := unmerge(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		err := tx.GetContext(ctx, &head, "SELECT * FROM cards WHERE id=$1", c.Id)
+		return notFound(err, "card", c.Id)
+	})
 	if err != nil {
 		return err
 	}
+	c.MergedCards = nil
+	c.MergeTitle = head.MergeTitle
+	c.Version = head.Version
+	return nil
+}
 
-	if index < 0 {
-		return fmt.Errorf("Cannot move to negative index")
+// unmerge takes the card with the given id out of its merge group and
+// returns it as it was read, before its version was bumped. The caller must
+// hold the lock of the retrospective.
+func unmerge(ctx context.Context, tx *sqlx.Tx, id string) (*model.Card, error) {
+	var card model.Card
+	if err := tx.GetContext(ctx, &card, "SELECT * FROM cards WHERE id=$1", id); err != nil {
+		return nil, notFound(err, "card", id)
+	}
+	if card.MergedInto == nil {
+		return nil, model.InputError("Card is not merged")
 	}
+	parent := *card.MergedInto
+	card.MergedInto = nil
 
-	if len(cs) == 0 {
-		c.Position = IDX_SPACING
-	} else if index >= len(cs) {
-		c.Position = cs[len(cs)-1].Position + IDX_SPACING
-	} else if index == 0 {
-		c.Position = cs[0].Position - IDX_SPACING
-	} else {
-		c.Position = (cs[index].Position + cs[index-1].Position) / 2
+	// Cards merged before merges were recorded keep their position
+	m, err := openMerge(ctx, tx, card.Id)
+	if err == nil {
+		cs, err := siblings(ctx, tx, &card, m.Column)
+		if err != nil {
+			return nil, err
+		}
+		index := sort.Search(len(cs), func(i int) bool {
+			return cs[i].Position >= m.Position
+		})
+		card.Column = m.Column
+		// Keep the old position, unless another card took it
+		card.Position = m.Position
+		if index < len(cs) && cs[index].Position == m.Position {
+			if err := place(ctx, tx, &card, cs, index); err != nil {
+				return nil, err
+			}
+		}
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE merges SET unmerged=$1 WHERE cardid=$2 AND unmerged IS NULL`, time.Now(), card.Id); err != nil {
+		return nil, err
+	}
+	if err := updateCard(ctx, tx, &card); err != nil {
+		return nil, err
 	}
 
-	c.Column = column
+	// The title goes with the group once its last card is gone
+	_, err = tx.ExecContext(ctx, `UPDATE cards
+      SET mergetitle=NULL, version=version + 1
+      WHERE id=$1 AND mergetitle IS NOT NULL AND NOT EXISTS (SELECT 1 FROM cards WHERE mergedinto=$1)
+    `, parent)
+	return &card, err
+}
 
-	tx.NamedExec(`UPDATE cards
-    SET updated=:updated, retrospectiveid=:retrospectiveid, message=:message, creator=:creator, "column"=:column, position=:position
-    WHERE id=:id
-  `, c)
+func (db *sqlRepository) GetMergesByCardId(ctx context.Context, id string) ([]*model.Merge, error) {
+	ms := []*model.Merge{}
+	err := db.SelectContext(ctx, &ms, "SELECT * FROM merges WHERE cardid=$1 ORDER BY created ASC, id ASC", id)
+	return ms, err
+}
 
-	if index > 0 && len(cs) >= index && c.Position-cs[index-1].Position < 4 || c.Position < -int(math.Exp2(30)) || c.Position > int(math.Exp2(30)) {
-		go db.reorderColumn(c.RetrospectiveId, column)
+func (db *sqlRepository) MoveCard(ctx context.Context, c *model.Card, column string, index int) error {
+	if index < 0 {
+		return model.InputError("Cannot move to negative index")
 	}
 
-	err = tx.Commit()
+	err := db.transact(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		if err := lockRetrospective(ctx, tx, c.RetrospectiveId); err != nil {
+			return err
+		}
 
-	return err
+		cs, err := siblings(ctx, tx, c, column)
+		if err != nil {
+			return err
+		}
+		if err := place(ctx, tx, c, cs, index); err != nil {
+			return err
+		}
+		// Merged cards are only ordered within their group
+		if c.MergedInto == nil {
+			c.Column = column
+		}
+		return updateCard(ctx, tx, c)
+	})
+	if err != nil {
+		return err
+	}
+	c.Version++
+	return nil
 }
 
-func (db *sqlRepository) UpdateCard(c *model.Card) error {
-	_, err := db.NamedExec(`UPDATE cards
-    SET updated=:updated, retrospectiveid=:retrospectiveid, message=:message, creator=:creator, "column"=:column, position=:position
-    WHERE id=:id
-  `, c)
-	return err
+func (db *sqlRepository) UpdateCard(ctx context.Context, c *model.Card) error {
+	if err := updateCard(ctx, db, c); err != nil {
+		return err
+	}
+	c.Version++
+	return nil
 }
 
-func (db *sqlRepository) GetCardById(id string) (*model.Card, error) {
-	var c model.Card
-	err := db.Get(&c, "SELECT * FROM cards WHERE id=$1", id)
+// updateCard writes c unless the card changed since c was read, in which case
+// a model.ConflictError is returned. The version is only bumped in the
+// database, so that a transaction can be retried with the same card.
+func updateCard(ctx context.Context, e sqlx.ExtContext, c *model.Card) error {
+	res, err := sqlx.NamedExecContext(ctx, e, `UPDATE cards
+    SET updated=:updated, retrospectiveid=:retrospectiveid, message=:message, creator=:creator, "column"=:column, position=:position, mergedinto=:mergedinto, mergetitle=:mergetitle, groupid=:groupid, issueurl=:issueurl, exportclaimed=:exportclaimed, version=version + 1
+    WHERE id=:id AND version=:version
+  `, c)
 	if err != nil {
-		panic(err)
-		return nil, err
+		return err
 	}
-	mergedCards := []*model.Card{}
-	err = db.Select(&mergedCards, "SELECT * FROM cards WHERE mergedInto=$1 ORDER BY position ASC", id)
-	c.MergedCards = mergedCards
+	n, err := res.RowsAffected()
 	if err != nil {
-		panic(err)
+		return err
+	}
+	if n == 0 {
+		var current model.Card
+		if err := sqlx.GetContext(ctx, e, &current, "SELECT * FROM cards WHERE id=$1", c.Id); err != nil {
+			return err
+		}
+		return &model.ConflictError{Card: &current}
 	}
+	return nil
+}
 
+func (db *sqlRepository) GetCardById(ctx context.Context, id string) (*model.Card, error) {
+	var c model.Card
+	err := db.GetContext(ctx, &c, "SELECT * FROM cards WHERE id=$1", id)
+	if err != nil {
+		return nil, notFound(err, "card", id)
+	}
+	c.MergedCards, err = db.mergedCards(ctx, id)
 	return &c, err
 }
 
-func (db *sqlRepository) GetCardsByRetrospectiveId(id string) ([]*model.Card, error) {
+// mergedCards returns the cards merged into the card with the given id, each
+// along with the cards merged into it.
+func (db *sqlRepository) mergedCards(ctx context.Context, id string) ([]*model.Card, error) {
+	cs := []*model.Card{}
+	err := db.SelectContext(ctx, &cs, "SELECT * FROM cards WHERE mergedinto=$1 ORDER BY position ASC, id ASC", id)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cs {
+		if c.MergedCards, err = db.mergedCards(ctx, c.Id); err != nil {
+			return nil, err
+		}
+	}
+	return cs, nil
+}
+
+func (db *sqlRepository) GetCardsByRetrospectiveId(ctx context.Context, id string) ([]*model.Card, error) {
 	allCards := []*model.Card{}
 	unmergedCards := []*model.Card{}
 	mergedCards := []*model.Card{}
-	err := db.Select(&allCards, "SELECT * FROM cards WHERE retrospectiveid=$1 ORDER BY position ASC", id)
+	err := db.SelectContext(ctx, &allCards, "SELECT * FROM cards WHERE retrospectiveid=$1 ORDER BY position ASC, id ASC", id)
 
 	cardsById := map[string]*model.Card{}
 	for _, card := range allCards {
+		cardsById[card.Id] = card
 		if card.MergedInto == nil {
 			unmergedCards = append(unmergedCards, card)
-			cardsById[card.Id] = card
 		} else {
 			mergedCards = append(mergedCards, card)
 		}
@@ -320,54 +547,55 @@ func (db *sqlRepository) GetCardsByRetrospectiveId(id string) ([]*model.Card, er
 	return unmergedCards, err
 }
 
-func (db *sqlRepository) NewVote(v *model.Vote) error {
-	_, err := db.NamedExec(`INSERT INTO votes
-      (id, created, updated, cardid, voter, emoji, count)
-    VALUES (:id, :created, :updated, :cardid, :voter, :emoji, :count)
-    ON CONFLICT(id) DO UPDATE SET updated=:updated, count=votes.count + 1
-  `, v)
+func (db *sqlRepository) NewVote(ctx context.Context, v *model.Vote) error {
+	_, err := db.NamedExecContext(ctx, upsert("votes", "id",
+		[]string{"id", "created", "updated", "cardid", "voter", "emoji", "count"},
+		"updated=:updated, count=votes.count + 1",
+	), v)
 	return err
 }
 
-func (db *sqlRepository) GetVotesByCardId(id string) ([]*model.Vote, error) {
+func (db *sqlRepository) GetVotesByCardId(ctx context.Context, id string) ([]*model.Vote, error) {
 	vs := []*model.Vote{}
-	err := db.Select(&vs, "SELECT * FROM votes WHERE cardid=$1", id)
+	err := db.SelectContext(ctx, &vs, "SELECT * FROM votes WHERE cardid=$1", id)
 	return vs, err
 }
 
-func (db *sqlRepository) GetVoteByCardIdAndVoterAndEmoji(id string, voter string, emoji string) (*model.Vote, error) {
+func (db *sqlRepository) GetVoteByCardIdAndVoterAndEmoji(ctx context.Context, id string, voter string, emoji string) (*model.Vote, error) {
 	v := model.Vote{}
-	err := db.Get(&v, "SELECT * FROM votes WHERE cardid=$1 AND voter=$2 AND emoji=$3", id, voter, emoji)
-	return &v, err
+	err := db.GetContext(ctx, &v, "SELECT * FROM votes WHERE cardid=$1 AND voter=$2 AND emoji=$3", id, voter, emoji)
+	return &v, notFound(err, "vote", emoji)
 }
 
-func (db *sqlRepository) GetTotalUniqueEmojis(id string) (int, error) {
+func (db *sqlRepository) GetTotalUniqueEmojis(ctx context.Context, id string) (int, error) {
 	var count int
-	err := db.Get(&count, "SELECT COUNT(distinct emoji) FROM votes WHERE cardid=$1", id)
+	err := db.GetContext(ctx, &count, "SELECT COUNT(distinct emoji) FROM votes WHERE cardid=$1", id)
 	return count, err
 }
 
-func (db *sqlRepository) NewStatus(s *model.Status) error {
-	_, err := db.NamedExec(`INSERT INTO statuses
+func (db *sqlRepository) NewStatus(ctx context.Context, s *model.Status) error {
+	_, err := db.NamedExecContext(ctx, `INSERT INTO statuses
       (id, created, cardid, type)
     VALUES (:id, :created, :cardid, :type)
   `, s)
 	return err
 }
 
-func (db *sqlRepository) GetStatusById(id string) (*model.Status, error) {
+func (db *sqlRepository) GetStatusById(ctx context.Context, id string) (*model.Status, error) {
 	var s model.Status
-	err := db.Get(&s, "SELECT * FROM statuses WHERE id=$1", id)
-	return &s, err
+	err := db.GetContext(ctx, &s, "SELECT * FROM statuses WHERE id=$1", id)
+	return &s, notFound(err, "status", id)
 }
 
-func (db *sqlRepository) GetStatusesByCardId(id string) ([]*model.Status, error) {
+func (db *sqlRepository) GetStatusesByCardId(ctx context.Context, id string) ([]*model.Status, error) {
 	ss := []*model.Status{}
-	err := db.Select(&ss, "SELECT * FROM statuses WHERE cardid=$1", id)
+	err := db.SelectContext(ctx, &ss, "SELECT * FROM statuses WHERE cardid=$1", id)
 	return ss, err
 }
 
-func (db *sqlRepository) Healthcheck() error {
-	_, err := db.Exec(`SELECT COUNT(*) FROM repositories`)
-	return err
+// Healthcheck checks that the database can be reached.
+func (db *sqlRepository) Healthcheck(ctx context.Context) error {
+	ctx, cancel := db.withTimeout(ctx)
+	defer cancel()
+	return db.PingContext(ctx)
 }
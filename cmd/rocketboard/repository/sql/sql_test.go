@@ -1,6 +1,7 @@
 package sql
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math/rand"
@@ -15,13 +16,14 @@ func init() {
 }
 
 func newTestRepository() *sqlRepository {
+	ctx := context.Background()
 	db, err := NewRepository("sqlite3::memory:")
 	db.SetMaxOpenConns(1)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	err = db.NewRetrospective(&model.Retrospective{
+	err = db.NewRetrospective(ctx, &model.Retrospective{
 		Id: "test-retro",
 	})
 
@@ -30,7 +32,7 @@ func newTestRepository() *sqlRepository {
 	}
 
 	for i := 0; i < 100; i++ {
-		err := db.NewCard(&model.Card{
+		err := db.NewCard(ctx, &model.Card{
 			Id:              "test-card-" + fmt.Sprint(i),
 			RetrospectiveId: "test-retro",
 			Column:          "Mixed",
@@ -43,6 +45,7 @@ func newTestRepository() *sqlRepository {
 }
 
 func TestNewVote(t *testing.T) {
+	ctx := context.Background()
 	db := newTestRepository()
 
 	vote := &model.Vote{
@@ -55,11 +58,11 @@ func TestNewVote(t *testing.T) {
 		Count:   1,
 	}
 
-	err := db.NewVote(vote)
+	err := db.NewVote(ctx, vote)
 	if err != nil {
 		t.Fatal("Failed to create vote", err)
 	}
-	v, err := db.GetVoteByCardIdAndVoterAndEmoji(vote.CardId, vote.Voter, vote.Emoji)
+	v, err := db.GetVoteByCardIdAndVoterAndEmoji(ctx, vote.CardId, vote.Voter, vote.Emoji)
 	if err != nil {
 		t.Fatal("Failed to get vote", err)
 	}
@@ -68,10 +71,10 @@ func TestNewVote(t *testing.T) {
 	}
 
 	for i := 0; i < 100; i++ {
-		db.NewVote(vote)
+		db.NewVote(ctx, vote)
 	}
 
-	v, err = db.GetVoteByCardIdAndVoterAndEmoji(vote.CardId, vote.Voter, vote.Emoji)
+	v, err = db.GetVoteByCardIdAndVoterAndEmoji(ctx, vote.CardId, vote.Voter, vote.Emoji)
 	if err != nil {
 		t.Fatal("Failed to get vote", err)
 	}
@@ -81,15 +84,16 @@ func TestNewVote(t *testing.T) {
 }
 
 func TestCardSorting(t *testing.T) {
+	ctx := context.Background()
 	db := newTestRepository()
 
 	for i := 0; i < 50; i++ {
-		cards, _ := db.GetCardsByRetrospectiveId("test-retro")
-		err := db.MoveCard(cards[0], "Mixed", 1)
+		cards, _ := db.GetCardsByRetrospectiveId(ctx, "test-retro")
+		err := db.MoveCard(ctx, cards[0], "Mixed", 1)
 		if err != nil {
 			t.Fatal("Failed to move card", err)
 		}
-		newCards, _ := db.GetCardsByRetrospectiveId("test-retro")
+		newCards, _ := db.GetCardsByRetrospectiveId(ctx, "test-retro")
 		if newCards[1].Id != cards[0].Id {
 			t.Fatal("Card didn't move correctly after", i, "swaps")
 		}
@@ -97,19 +101,21 @@ func TestCardSorting(t *testing.T) {
 }
 
 func BenchmarkMoveCard(b *testing.B) {
+	ctx := context.Background()
 	db := newTestRepository()
 
-	cards, _ := db.GetCardsByRetrospectiveId("test-retro")
+	cards, _ := db.GetCardsByRetrospectiveId(ctx, "test-retro")
 	for i := 0; i < b.N; i++ {
 		from := rand.Intn(len(cards))
 		to := rand.Intn(len(cards))
-		err := db.MoveCard(cards[from], "Mixed", to)
+		err := db.MoveCard(ctx, cards[from], "Mixed", to)
 		if err != nil {
 			b.Fatal("Failed to move card", err)
 		}
 	}
 }
 func BenchmarkNewVote(b *testing.B) {
+	ctx := context.Background()
 	db := newTestRepository()
 
 	vote := &model.Vote{
@@ -122,6 +128,6 @@ func BenchmarkNewVote(b *testing.B) {
 	}
 
 	for i := 0; i < b.N; i++ {
-		db.NewVote(vote)
+		db.NewVote(ctx, vote)
 	}
 }
@@ -0,0 +1,110 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+func (db *sqlRepository) NewWebhook(ctx context.Context, w *model.Webhook) error {
+	_, err := db.NamedExecContext(ctx, `INSERT INTO webhooks
+      (id, created, retrospectiveid, team, url, secret, events)
+    VALUES (:id, :created, :retrospectiveid, :team, :url, :secret, :events)
+  `, w)
+	return err
+}
+
+func (db *sqlRepository) DeleteWebhook(ctx context.Context, id string) error {
+	return db.transact(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM webhook_attempts WHERE deliveryid IN (SELECT id FROM webhook_deliveries WHERE webhookid=$1)`, id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhookid=$1`, id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id=$1`, id)
+		return err
+	})
+}
+
+func (db *sqlRepository) GetWebhookById(ctx context.Context, id string) (*model.Webhook, error) {
+	var w model.Webhook
+	err := db.GetContext(ctx, &w, "SELECT * FROM webhooks WHERE id=$1", id)
+	return &w, notFound(err, "webhook", id)
+}
+
+func (db *sqlRepository) GetWebhooksByRetrospectiveId(ctx context.Context, id string) ([]*model.Webhook, error) {
+	ws := []*model.Webhook{}
+	err := db.SelectContext(ctx, &ws, "SELECT * FROM webhooks WHERE retrospectiveid=$1 ORDER BY created ASC", id)
+	return ws, err
+}
+
+func (db *sqlRepository) GetWebhooksByTeam(ctx context.Context, team string) ([]*model.Webhook, error) {
+	ws := []*model.Webhook{}
+	err := db.SelectContext(ctx, &ws, "SELECT * FROM webhooks WHERE team=$1 AND retrospectiveid IS NULL ORDER BY created ASC", team)
+	return ws, err
+}
+
+func (db *sqlRepository) NewWebhookDelivery(ctx context.Context, d *model.WebhookDelivery) error {
+	_, err := db.NamedExecContext(ctx, `INSERT INTO webhook_deliveries
+      (id, created, webhookid, event, payload, state, attempts, nextattempt, claim)
+    VALUES (:id, :created, :webhookid, :event, :payload, :state, :attempts, :nextattempt, :claim)
+  `, d)
+	return err
+}
+
+func (db *sqlRepository) UpdateWebhookDelivery(ctx context.Context, d *model.WebhookDelivery) error {
+	_, err := db.NamedExecContext(ctx, `UPDATE webhook_deliveries
+    SET state=:state, attempts=:attempts, nextattempt=:nextattempt
+    WHERE id=:id
+  `, d)
+	return err
+}
+
+func (db *sqlRepository) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	ds := []*model.WebhookDelivery{}
+	err := db.SelectContext(ctx, &ds, "SELECT * FROM webhook_deliveries WHERE state=$1 AND nextattempt <= $2 ORDER BY nextattempt ASC LIMIT $3", model.Pending, now, limit)
+	return ds, err
+}
+
+// ClaimWebhookDelivery pushes back the next attempt of a pending delivery to
+// until, but only if nobody else claimed it since it was read. This stops
+// several instances from sending the same delivery at once.
+func (db *sqlRepository) ClaimWebhookDelivery(ctx context.Context, d *model.WebhookDelivery, claim string, until time.Time) (bool, error) {
+	res, err := db.ExecContext(ctx, "UPDATE webhook_deliveries SET nextattempt=$1, claim=$2 WHERE id=$3 AND state=$4 AND claim=$5", until, claim, d.Id, model.Pending, d.Claim)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	d.NextAttempt = until
+	d.Claim = claim
+	return true, nil
+}
+
+func (db *sqlRepository) GetWebhookDeliveriesByWebhookId(ctx context.Context, id string, limit int) ([]*model.WebhookDelivery, error) {
+	ds := []*model.WebhookDelivery{}
+	err := db.SelectContext(ctx, &ds, "SELECT * FROM webhook_deliveries WHERE webhookid=$1 ORDER BY created DESC LIMIT $2", id, limit)
+	return ds, err
+}
+
+func (db *sqlRepository) NewWebhookAttempt(ctx context.Context, a *model.WebhookAttempt) error {
+	_, err := db.NamedExecContext(ctx, `INSERT INTO webhook_attempts
+      (id, created, deliveryid, statuscode, error, duration)
+    VALUES (:id, :created, :deliveryid, :statuscode, :error, :duration)
+  `, a)
+	return err
+}
+
+func (db *sqlRepository) GetWebhookAttemptsByDeliveryId(ctx context.Context, id string) ([]*model.WebhookAttempt, error) {
+	as := []*model.WebhookAttempt{}
+	err := db.SelectContext(ctx, &as, "SELECT * FROM webhook_attempts WHERE deliveryid=$1 ORDER BY created ASC", id)
+	return as, err
+}
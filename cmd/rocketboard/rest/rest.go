@@ -0,0 +1,512 @@
+package rest
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/logging"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+const prefix = "/api/v1/"
+
+type rocketboardService interface {
+	StartRetrospective(context.Context, string, string) (string, error)
+	GetRetrospectiveById(context.Context, string) (*model.Retrospective, error)
+	GetRetrospectiveByPetName(context.Context, string) (*model.Retrospective, error)
+	AddCardToRetrospective(context.Context, string, string, string, string) (string, error)
+	MoveCard(context.Context, string, string, int, int) error
+	MergeCard(context.Context, string, string) error
+	UnmergeCard(context.Context, string) error
+	UnmergeAll(context.Context, string) error
+	GetMergeHistory(context.Context, string) ([]*model.Merge, error)
+	UpdateMessage(context.Context, string, string, int) error
+	GetCardsForRetrospective(context.Context, string) ([]*model.Card, error)
+	GetCardById(context.Context, string) (*model.Card, error)
+	GetVotesByCardId(context.Context, string) ([]*model.Vote, error)
+	NewVote(context.Context, string, string, string) (*model.Vote, error)
+	GetCardStatuses(context.Context, string) ([]*model.Status, error)
+	SetStatus(context.Context, string, model.StatusType) (string, error)
+	GetStatusById(context.Context, string) (*model.Status, error)
+	GetArchive(context.Context, string) (*model.Archive, error)
+}
+
+// card is the JSON representation of a card, including its votes and
+// statuses which are resolved separately over GraphQL.
+type card struct {
+	*model.Card
+	Statuses []*model.Status `json:"statuses"`
+	Votes    []*model.Vote   `json:"votes"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+
+	// The current card when a change was based on an outdated one
+	Card *model.Card `json:"card,omitempty"`
+}
+
+type handler struct {
+	s       rocketboardService
+	publish func(context.Context, *model.Card)
+	logger  logrus.FieldLogger
+}
+
+// NewHandler returns the versioned REST API under /api/v1/. Changes to cards
+// are passed to publish so that GraphQL subscribers see them as well.
+func NewHandler(s rocketboardService, publish func(context.Context, *model.Card), logger logrus.FieldLogger) http.Handler {
+	return &handler{s, publish, logger}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/"), "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "openapi.json":
+		h.openAPI(w, r)
+	case parts[0] == "retrospectives":
+		h.retrospectives(w, r, parts[1:])
+	case parts[0] == "cards":
+		h.cards(w, r, parts[1:])
+	case len(parts) == 1 && parts[0] == "votes":
+		h.votes(w, r)
+	case parts[0] == "statuses":
+		h.statuses(w, r, parts[1:])
+	case parts[0] == "archives":
+		h.archives(w, r, parts[1:])
+	default:
+		h.writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+func (h *handler) openAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIDocument)
+}
+
+func (h *handler) retrospectives(w http.ResponseWriter, r *http.Request, parts []string) {
+	ctx := r.Context()
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		petName := r.URL.Query().Get("petName")
+		if petName == "" {
+			h.writeError(w, http.StatusBadRequest, errors.New("petName is required"))
+			return
+		}
+		retro, err := h.s.GetRetrospectiveByPetName(ctx, petName)
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		h.writeJSON(w, http.StatusOK, retro)
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		var req struct {
+			Name string `json:"name"`
+			Team string `json:"team"`
+		}
+		if !h.readJSON(w, r, &req) {
+			return
+		}
+		petName, err := h.s.StartRetrospective(ctx, req.Name, req.Team)
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		retro, err := h.s.GetRetrospectiveByPetName(ctx, petName)
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		h.writeJSON(w, http.StatusCreated, retro)
+	case len(parts) == 0:
+		h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		retro, err := h.s.GetRetrospectiveById(ctx, parts[0])
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		h.writeJSON(w, http.StatusOK, retro)
+	case len(parts) == 1:
+		h.methodNotAllowed(w, http.MethodGet)
+	case len(parts) == 2 && parts[1] == "cards" && r.Method == http.MethodGet:
+		if _, err := h.s.GetRetrospectiveById(ctx, parts[0]); err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		cards, err := h.s.GetCardsForRetrospective(ctx, parts[0])
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		res := []card{}
+		for _, c := range cards {
+			res = append(res, h.card(ctx, c))
+		}
+		h.writeJSON(w, http.StatusOK, res)
+	case len(parts) == 2 && parts[1] == "cards":
+		h.methodNotAllowed(w, http.MethodGet)
+	default:
+		h.writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+// The sub-resources of cards that are only posted to.
+var cardActions = map[string]bool{
+	"move":        true,
+	"merge":       true,
+	"unmerge":     true,
+	"unmerge-all": true,
+}
+
+func (h *handler) cards(w http.ResponseWriter, r *http.Request, parts []string) {
+	ctx := r.Context()
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		var req struct {
+			RetrospectiveId string `json:"retrospectiveId"`
+			Column          string `json:"column"`
+			Message         string `json:"message"`
+		}
+		if !h.readJSON(w, r, &req) {
+			return
+		}
+		if req.RetrospectiveId == "" || req.Column == "" {
+			h.writeError(w, http.StatusBadRequest, errors.New("retrospectiveId and column are required"))
+			return
+		}
+		id, err := h.s.AddCardToRetrospective(ctx, req.RetrospectiveId, req.Column, req.Message, user(r))
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		h.writeCard(ctx, w, http.StatusCreated, id, true)
+	case len(parts) == 0:
+		h.methodNotAllowed(w, http.MethodPost)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		h.writeCard(ctx, w, http.StatusOK, parts[0], false)
+	case len(parts) == 1 && r.Method == http.MethodPatch:
+		var req struct {
+			Message *string `json:"message"`
+			Version *int    `json:"version"`
+		}
+		if !h.readJSON(w, r, &req) {
+			return
+		}
+		if req.Message == nil || req.Version == nil {
+			h.writeError(w, http.StatusBadRequest, errors.New("message and version are required"))
+			return
+		}
+		if err := h.s.UpdateMessage(ctx, parts[0], *req.Message, *req.Version); err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		h.writeCard(ctx, w, http.StatusOK, parts[0], true)
+	case len(parts) == 1:
+		h.methodNotAllowed(w, http.MethodGet, http.MethodPatch)
+	case len(parts) == 2 && cardActions[parts[1]] && r.Method != http.MethodPost:
+		h.methodNotAllowed(w, http.MethodPost)
+	case len(parts) == 2 && parts[1] == "move":
+		var req struct {
+			Column  string `json:"column"`
+			Index   *int   `json:"index"`
+			Version *int   `json:"version"`
+		}
+		if !h.readJSON(w, r, &req) {
+			return
+		}
+		if req.Column == "" || req.Index == nil || *req.Index < 0 || req.Version == nil {
+			h.writeError(w, http.StatusBadRequest, errors.New("column, a non-negative index and version are required"))
+			return
+		}
+		if err := h.s.MoveCard(ctx, parts[0], req.Column, *req.Index, *req.Version); err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		h.writeCard(ctx, w, http.StatusOK, parts[0], true)
+	case len(parts) == 2 && parts[1] == "merge":
+		var req struct {
+			MergedInto string `json:"mergedInto"`
+		}
+		if !h.readJSON(w, r, &req) {
+			return
+		}
+		if req.MergedInto == "" {
+			h.writeError(w, http.StatusBadRequest, errors.New("mergedInto is required"))
+			return
+		}
+		if err := h.s.MergeCard(ctx, parts[0], req.MergedInto); err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		h.publishGroup(ctx, req.MergedInto)
+		h.writeCard(ctx, w, http.StatusOK, parts[0], true)
+	case len(parts) == 2 && parts[1] == "unmerge":
+		c, err := h.s.GetCardById(ctx, parts[0])
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		if c.MergedInto == nil {
+			h.writeError(w, http.StatusConflict, errors.New("card is not merged"))
+			return
+		}
+		if err := h.s.UnmergeCard(ctx, parts[0]); err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		h.publishGroup(ctx, *c.MergedInto)
+		h.writeCard(ctx, w, http.StatusOK, parts[0], true)
+	case len(parts) == 2 && parts[1] == "unmerge-all":
+		c, err := h.s.GetCardById(ctx, parts[0])
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		if err := h.s.UnmergeAll(ctx, parts[0]); err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		for _, merged := range c.Group()[1:] {
+			if unmerged, err := h.s.GetCardById(ctx, merged.Id); err == nil {
+				h.publish(ctx, unmerged)
+			}
+		}
+		h.publishGroup(ctx, parts[0])
+		h.writeCard(ctx, w, http.StatusOK, parts[0], false)
+	case len(parts) == 2 && parts[1] == "merges" && r.Method == http.MethodGet:
+		merges, err := h.s.GetMergeHistory(ctx, parts[0])
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		h.writeJSON(w, http.StatusOK, merges)
+	case len(parts) == 2 && parts[1] == "votes" && r.Method == http.MethodGet:
+		votes, err := h.s.GetVotesByCardId(ctx, parts[0])
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		h.writeJSON(w, http.StatusOK, votes)
+	case len(parts) == 2 && parts[1] == "statuses" && r.Method == http.MethodGet:
+		statuses, err := h.s.GetCardStatuses(ctx, parts[0])
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		h.writeJSON(w, http.StatusOK, statuses)
+	case len(parts) == 2 && (parts[1] == "votes" || parts[1] == "statuses" || parts[1] == "merges"):
+		h.methodNotAllowed(w, http.MethodGet)
+	default:
+		h.writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+func (h *handler) votes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		h.methodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var req struct {
+		CardId string `json:"cardId"`
+		Emoji  string `json:"emoji"`
+	}
+	if !h.readJSON(w, r, &req) {
+		return
+	}
+	if req.CardId == "" || req.Emoji == "" {
+		h.writeError(w, http.StatusBadRequest, errors.New("cardId and emoji are required"))
+		return
+	}
+	if _, err := h.s.GetCardById(ctx, req.CardId); err != nil {
+		h.writeServiceError(ctx, w, err)
+		return
+	}
+	v, err := h.s.NewVote(ctx, req.CardId, user(r), req.Emoji)
+	if err != nil {
+		h.writeServiceError(ctx, w, err)
+		return
+	}
+	if c, err := h.s.GetCardById(ctx, req.CardId); err == nil {
+		h.publish(ctx, c)
+	}
+	h.writeJSON(w, http.StatusCreated, v)
+}
+
+func (h *handler) statuses(w http.ResponseWriter, r *http.Request, parts []string) {
+	ctx := r.Context()
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		var req struct {
+			CardId string           `json:"cardId"`
+			Type   model.StatusType `json:"type"`
+		}
+		if !h.readJSON(w, r, &req) {
+			return
+		}
+		if req.CardId == "" || !req.Type.IsAStatusType() {
+			h.writeError(w, http.StatusBadRequest, errors.New("cardId and type are required"))
+			return
+		}
+		id, err := h.s.SetStatus(ctx, req.CardId, req.Type)
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		s, err := h.s.GetStatusById(ctx, id)
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		if c, err := h.s.GetCardById(ctx, req.CardId); err == nil {
+			h.publish(ctx, c)
+		}
+		h.writeJSON(w, http.StatusCreated, s)
+	case len(parts) == 0:
+		h.methodNotAllowed(w, http.MethodPost)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		s, err := h.s.GetStatusById(ctx, parts[0])
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		h.writeJSON(w, http.StatusOK, s)
+	case len(parts) == 1:
+		h.methodNotAllowed(w, http.MethodGet)
+	default:
+		h.writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+// archives serves the snapshot of an archived retrospective as gzipped JSON.
+func (h *handler) archives(w http.ResponseWriter, r *http.Request, parts []string) {
+	ctx := r.Context()
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		a, err := h.s.GetArchive(ctx, parts[0])
+		if err != nil {
+			h.writeServiceError(ctx, w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+a.PetName+`.json.gz"`)
+		w.Write(a.Snapshot)
+	case len(parts) == 1:
+		h.methodNotAllowed(w, http.MethodGet)
+	default:
+		h.writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+func (h *handler) card(ctx context.Context, c *model.Card) card {
+	statuses, _ := h.s.GetCardStatuses(ctx, c.Id)
+	votes, _ := h.s.GetVotesByCardId(ctx, c.Id)
+	return card{c, statuses, votes}
+}
+
+// writeCard writes the current state of a card, publishing it to subscribers
+// first if it was changed by the request.
+func (h *handler) writeCard(ctx context.Context, w http.ResponseWriter, code int, id string, changed bool) {
+	c, err := h.s.GetCardById(ctx, id)
+	if err != nil {
+		h.writeServiceError(ctx, w, err)
+		return
+	}
+	if changed {
+		h.publish(ctx, c)
+	}
+	h.writeJSON(w, code, h.card(ctx, c))
+}
+
+// publishGroup publishes the card heading the merge group that the card with
+// the given id is in, which carries the whole group.
+func (h *handler) publishGroup(ctx context.Context, id string) {
+	c, err := h.s.GetCardById(ctx, id)
+	for err == nil && c.MergedInto != nil {
+		c, err = h.s.GetCardById(ctx, *c.MergedInto)
+	}
+	if err == nil {
+		h.publish(ctx, c)
+	}
+}
+
+func user(r *http.Request) string {
+	if email, ok := r.Context().Value("email").(string); ok {
+		return email
+	}
+	return "unknown"
+}
+
+func (h *handler) readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+func (h *handler) writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		// The client went away
+		h.logger.WithError(err).Debug("Failed to write response")
+	}
+}
+
+func (h *handler) writeError(w http.ResponseWriter, code int, err error) {
+	h.writeJSON(w, code, errorResponse{Error: err.Error()})
+}
+
+func (h *handler) methodNotAllowed(w http.ResponseWriter, methods ...string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	h.writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
+
+// Status codes of the kinds of errors returned by the service.
+var statusOfCode = map[string]int{
+	model.CodeNotFound:    http.StatusNotFound,
+	model.CodeForbidden:   http.StatusForbidden,
+	model.CodeConflict:    http.StatusConflict,
+	model.CodeValidation:  http.StatusUnprocessableEntity,
+	model.CodeRateLimited: http.StatusTooManyRequests,
+}
+
+// writeServiceError maps errors returned by the service to HTTP status codes.
+func (h *handler) writeServiceError(ctx context.Context, w http.ResponseWriter, err error) {
+	code := model.Code(err)
+	status, ok := statusOfCode[code]
+	if !ok {
+		logging.Request(ctx, h.logger).WithError(err).Error("Request failed")
+		h.writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error", Code: code})
+		return
+	}
+
+	res := errorResponse{Error: err.Error(), Code: code}
+	var conflict *model.ConflictError
+	if errors.As(err, &conflict) {
+		res.Card = conflict.Card
+	}
+	if code == model.CodeNotFound && !errors.As(err, new(*model.NotFoundError)) {
+		// A plain sql.ErrNoRows says nothing about what was missing
+		res.Error = "not found"
+	}
+	h.writeJSON(w, status, res)
+}
@@ -0,0 +1,238 @@
+package rest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+type fakeService struct {
+	rocketboardService
+
+	retro *model.Retrospective
+	cards map[string]*model.Card
+}
+
+func newFakeService() *fakeService {
+	return &fakeService{
+		retro: &model.Retrospective{Id: "retro", PetName: "pet-name"},
+		cards: map[string]*model.Card{},
+	}
+}
+
+func (s *fakeService) StartRetrospective(ctx context.Context, name string, team string) (string, error) {
+	s.retro.Name = name
+	s.retro.Team = team
+	return s.retro.PetName, nil
+}
+
+func (s *fakeService) GetRetrospectiveById(ctx context.Context, id string) (*model.Retrospective, error) {
+	if id != s.retro.Id {
+		return nil, sql.ErrNoRows
+	}
+	return s.retro, nil
+}
+
+func (s *fakeService) GetRetrospectiveByPetName(ctx context.Context, petName string) (*model.Retrospective, error) {
+	if petName != s.retro.PetName {
+		return nil, sql.ErrNoRows
+	}
+	return s.retro, nil
+}
+
+func (s *fakeService) AddCardToRetrospective(ctx context.Context, rId string, column string, message string, creator string) (string, error) {
+	if rId != s.retro.Id {
+		return "", sql.ErrNoRows
+	}
+	c := &model.Card{Id: "card", RetrospectiveId: rId, Column: column, Message: message, Creator: creator}
+	s.cards[c.Id] = c
+	return c.Id, nil
+}
+
+func (s *fakeService) GetCardById(ctx context.Context, id string) (*model.Card, error) {
+	c, ok := s.cards[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return c, nil
+}
+
+func (s *fakeService) GetVotesByCardId(ctx context.Context, id string) ([]*model.Vote, error) {
+	return []*model.Vote{}, nil
+}
+
+func (s *fakeService) GetCardStatuses(ctx context.Context, id string) ([]*model.Status, error) {
+	return []*model.Status{}, nil
+}
+
+func (s *fakeService) UpdateMessage(ctx context.Context, id string, message string, version int) error {
+	c, ok := s.cards[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if c.Version != version {
+		return &model.ConflictError{Card: c}
+	}
+	c.Message = message
+	c.Version++
+	return nil
+}
+
+func (s *fakeService) GetMergeHistory(ctx context.Context, id string) ([]*model.Merge, error) {
+	return []*model.Merge{}, nil
+}
+
+func (s *fakeService) GetArchive(ctx context.Context, id string) (*model.Archive, error) {
+	if id != "archived" {
+		return nil, sql.ErrNoRows
+	}
+	return &model.Archive{Id: id, PetName: "archived-pet", Snapshot: []byte("snapshot")}, nil
+}
+
+func (s *fakeService) NewVote(ctx context.Context, cardId string, voter string, emoji string) (*model.Vote, error) {
+	return nil, model.InputError("Invalid emoji")
+}
+
+func do(t *testing.T, h http.Handler, method string, path string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateCard(t *testing.T) {
+	published := 0
+	h := NewHandler(newFakeService(), func(context.Context, *model.Card) { published++ }, logrus.StandardLogger())
+
+	w := do(t, h, "POST", "/api/v1/cards", `{"retrospectiveId": "retro", "column": "Mixed", "message": "hello"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatal("Expected 201, got:", w.Code, w.Body.String())
+	}
+	var c card
+	if err := json.Unmarshal(w.Body.Bytes(), &c); err != nil {
+		t.Fatal("Failed to decode card", err)
+	}
+	if c.Message != "hello" || c.Column != "Mixed" || c.Votes == nil {
+		t.Fatal("Unexpected card:", w.Body.String())
+	}
+	if published != 1 {
+		t.Fatal("Expected new card to be published once, got:", published)
+	}
+
+	w = do(t, h, "GET", "/api/v1/cards/card", "")
+	if w.Code != http.StatusOK {
+		t.Fatal("Expected 200, got:", w.Code)
+	}
+	if published != 1 {
+		t.Fatal("Reading a card should not publish it")
+	}
+}
+
+func TestConflictingUpdate(t *testing.T) {
+	h := NewHandler(newFakeService(), func(context.Context, *model.Card) {}, logrus.StandardLogger())
+
+	do(t, h, "POST", "/api/v1/cards", `{"retrospectiveId": "retro", "column": "Mixed", "message": "hello"}`)
+
+	w := do(t, h, "PATCH", "/api/v1/cards/card", `{"message": "first", "version": 0}`)
+	if w.Code != http.StatusOK {
+		t.Fatal("Expected 200, got:", w.Code, w.Body.String())
+	}
+
+	w = do(t, h, "PATCH", "/api/v1/cards/card", `{"message": "second", "version": 0}`)
+	if w.Code != http.StatusConflict {
+		t.Fatal("Expected 409, got:", w.Code, w.Body.String())
+	}
+	var resp errorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal("Failed to decode error", err)
+	}
+	if resp.Card == nil || resp.Card.Message != "first" || resp.Card.Version != 1 {
+		t.Fatal("Expected the current card in the conflict, got:", w.Body.String())
+	}
+}
+
+func TestStatusCodes(t *testing.T) {
+	h := NewHandler(newFakeService(), func(context.Context, *model.Card) {}, logrus.StandardLogger())
+
+	cases := []struct {
+		method string
+		path   string
+		body   string
+		code   int
+	}{
+		{"GET", "/api/v1/retrospectives/retro", "", http.StatusOK},
+		{"GET", "/api/v1/retrospectives?petName=pet-name", "", http.StatusOK},
+		{"POST", "/api/v1/retrospectives", `{"name": "weekly"}`, http.StatusCreated},
+		{"GET", "/api/v1/retrospectives/missing", "", http.StatusNotFound},
+		{"GET", "/api/v1/retrospectives/missing/cards", "", http.StatusNotFound},
+		{"DELETE", "/api/v1/retrospectives/retro", "", http.StatusMethodNotAllowed},
+		{"POST", "/api/v1/cards", `{"retrospectiveId": "missing", "column": "Mixed"}`, http.StatusNotFound},
+		{"POST", "/api/v1/cards", `{"column": "Mixed"}`, http.StatusBadRequest},
+		{"POST", "/api/v1/cards", `{"unknown": true}`, http.StatusBadRequest},
+		{"POST", "/api/v1/cards", `not json`, http.StatusBadRequest},
+		{"GET", "/api/v1/cards/missing", "", http.StatusNotFound},
+		{"POST", "/api/v1/cards/missing/move", `{"column": "Mixed", "index": -1}`, http.StatusBadRequest},
+		{"POST", "/api/v1/cards/missing/move", `{"column": "Mixed", "index": 0}`, http.StatusBadRequest},
+		{"PATCH", "/api/v1/cards/missing", `{"message": "hello"}`, http.StatusBadRequest},
+		{"POST", "/api/v1/cards", `{"retrospectiveId": "retro", "column": "Mixed"}`, http.StatusCreated},
+		{"POST", "/api/v1/votes", `{"cardId": "card", "emoji": "invalid"}`, http.StatusUnprocessableEntity},
+		{"POST", "/api/v1/statuses", `{"cardId": "card", "type": "Unknown"}`, http.StatusBadRequest},
+		{"GET", "/api/v1/cards/card/merges", "", http.StatusOK},
+		{"PUT", "/api/v1/cards/card/merges", "", http.StatusMethodNotAllowed},
+		{"POST", "/api/v1/cards/missing/unmerge-all", "", http.StatusNotFound},
+		{"GET", "/api/v1/cards/card/move", "", http.StatusMethodNotAllowed},
+		{"GET", "/api/v1/cards/card/unknown", "", http.StatusNotFound},
+		{"POST", "/api/v1/cards/card/unknown", "", http.StatusNotFound},
+		{"GET", "/api/v1/archives/archived", "", http.StatusOK},
+		{"GET", "/api/v1/archives/missing", "", http.StatusNotFound},
+		{"DELETE", "/api/v1/archives/archived", "", http.StatusMethodNotAllowed},
+		{"GET", "/api/v1/openapi.json", "", http.StatusOK},
+		{"GET", "/api/v1/unknown", "", http.StatusNotFound},
+	}
+
+	for _, c := range cases {
+		w := do(t, h, c.method, c.path, c.body)
+		if w.Code != c.code {
+			t.Error(c.method, c.path, "expected", c.code, "got", w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	h := &handler{logger: logrus.StandardLogger()}
+
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{sql.ErrNoRows, http.StatusNotFound, model.CodeNotFound},
+		{&model.NotFoundError{Kind: "card", Id: "missing"}, http.StatusNotFound, model.CodeNotFound},
+		{model.ForbiddenError("no"), http.StatusForbidden, model.CodeForbidden},
+		{&model.ConflictError{Card: &model.Card{Id: "card"}}, http.StatusConflict, model.CodeConflict},
+		{model.InputError("invalid"), http.StatusUnprocessableEntity, model.CodeValidation},
+		{&model.RateLimitedError{}, http.StatusTooManyRequests, model.CodeRateLimited},
+		{context.DeadlineExceeded, http.StatusInternalServerError, model.CodeInternal},
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		h.writeServiceError(context.Background(), w, c.err)
+		var resp errorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal("Failed to decode error", err)
+		}
+		if w.Code != c.status || resp.Code != c.code {
+			t.Fatal("Expected", c.status, c.code, "for", c.err, "got:", w.Code, resp.Code)
+		}
+	}
+}
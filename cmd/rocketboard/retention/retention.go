@@ -0,0 +1,138 @@
+// Package retention archives closed retrospectives once the retention period
+// of their team is over, and cleans up after instances that went away.
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+type store interface {
+	GetTeams(context.Context) ([]*model.Team, error)
+	GetClosedRetrospectives(context.Context, time.Time) ([]*model.Retrospective, error)
+	// ArchiveRetrospective takes the snapshot of archives without one in
+	// the same transaction as it purges the retrospective.
+	ArchiveRetrospective(context.Context, *model.Archive) error
+	PurgeObservations(context.Context, time.Time) (int, error)
+}
+
+// Janitor periodically archives retrospectives that were closed for longer
+// than the retention period of their team, and purges stale observations.
+// Several instances may run a janitor at the same time, archiving a
+// retrospective twice only replaces its archive.
+type Janitor struct {
+	db store
+
+	// Retention in days for teams without one of their own, and for
+	// retrospectives without a team. 0 keeps them forever.
+	DefaultRetentionDays int
+	// Observations are refreshed by every heartbeat, ones older than this
+	// were left behind by an instance that stopped without clearing them.
+	ObservationTTL time.Duration
+	// How often the janitor sweeps.
+	Interval time.Duration
+	Logger   logrus.FieldLogger
+}
+
+func NewJanitor(db store) *Janitor {
+	return &Janitor{
+		db:             db,
+		ObservationTTL: time.Hour,
+		Interval:       time.Hour,
+		Logger:         logrus.StandardLogger(),
+	}
+}
+
+// Run sweeps until ctx is cancelled.
+func (j *Janitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		j.Sweep(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep archives the retrospectives whose retention is over at now, and
+// purges observations that were not seen for ObservationTTL.
+func (j *Janitor) Sweep(ctx context.Context, now time.Time) {
+	if n, err := j.db.PurgeObservations(ctx, now.Add(-j.ObservationTTL)); err != nil {
+		j.Logger.WithError(err).Error("Failed to purge observations")
+	} else if n > 0 {
+		j.Logger.Infof("Purged %d stale observations", n)
+	}
+
+	teams, err := j.db.GetTeams(ctx)
+	if err != nil {
+		j.Logger.WithError(err).Error("Failed to load teams")
+		return
+	}
+	retention := map[string]int{}
+	for _, t := range teams {
+		if t.RetentionDays != nil {
+			retention[t.Name] = *t.RetentionDays
+		}
+	}
+	days := func(team string) int {
+		if d, ok := retention[team]; ok {
+			return d
+		}
+		return j.DefaultRetentionDays
+	}
+
+	// Only retrospectives past the shortest retention can be due.
+	shortest := j.DefaultRetentionDays
+	for _, d := range retention {
+		if d > 0 && (shortest == 0 || d < shortest) {
+			shortest = d
+		}
+	}
+	if shortest == 0 {
+		return
+	}
+
+	closed, err := j.db.GetClosedRetrospectives(ctx, cutoff(now, shortest))
+	if err != nil {
+		j.Logger.WithError(err).Error("Failed to load closed retrospectives")
+		return
+	}
+	for _, r := range closed {
+		if ctx.Err() != nil {
+			return
+		}
+		d := days(r.Team)
+		if d == 0 || !r.Closed.Before(cutoff(now, d)) {
+			continue
+		}
+		if err := j.archive(ctx, r, now); err != nil {
+			j.Logger.WithError(err).WithField("retro", r.Id).Error("Failed to archive retrospective")
+			continue
+		}
+		j.Logger.WithFields(logrus.Fields{"retro": r.Id, "closed": r.Closed.Format(time.RFC3339)}).Info("Archived retrospective")
+	}
+}
+
+func cutoff(now time.Time, days int) time.Time {
+	return now.AddDate(0, 0, -days)
+}
+
+func (j *Janitor) archive(ctx context.Context, r *model.Retrospective, now time.Time) error {
+	return j.db.ArchiveRetrospective(ctx, &model.Archive{
+		Id:      r.Id,
+		Created: now,
+		Name:    r.Name,
+		PetName: r.PetName,
+		Team:    r.Team,
+		Closed:  *r.Closed,
+	})
+}
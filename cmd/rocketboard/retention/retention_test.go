@@ -0,0 +1,142 @@
+package retention
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/repository/inmem"
+)
+
+// repository is the part of the inmem repository the tests set up data with.
+type repository interface {
+	store
+	NewRetrospective(context.Context, *model.Retrospective) error
+	UpdateRetrospective(context.Context, *model.Retrospective) error
+}
+
+func closedRetro(t *testing.T, db repository, id string, team string, closed time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	r := &model.Retrospective{Id: id, Name: id, PetName: "pet-" + id, Team: team}
+	if err := db.NewRetrospective(ctx, r); err != nil {
+		t.Fatal("Failed to create retrospective", err)
+	}
+	if !closed.IsZero() {
+		r.Closed = &closed
+		db.UpdateRetrospective(ctx, r)
+	}
+}
+
+func days(n int) *int {
+	return &n
+}
+
+func TestSweep(t *testing.T) {
+	ctx := context.Background()
+	db := inmem.NewRepository()
+	now := time.Now()
+	db.SaveTeam(ctx, &model.Team{Name: "short", RetentionDays: days(7)})
+	db.SaveTeam(ctx, &model.Team{Name: "forever", RetentionDays: days(0)})
+	db.SaveTeam(ctx, &model.Team{Name: "default"})
+
+	closedRetro(t, db, "short-old", "short", now.AddDate(0, 0, -8))
+	closedRetro(t, db, "short-new", "short", now.AddDate(0, 0, -6))
+	closedRetro(t, db, "forever", "forever", now.AddDate(0, 0, -100))
+	closedRetro(t, db, "default-old", "default", now.AddDate(0, 0, -31))
+	closedRetro(t, db, "default-new", "default", now.AddDate(0, 0, -29))
+	closedRetro(t, db, "no-team", "", now.AddDate(0, 0, -31))
+	closedRetro(t, db, "open", "short", time.Time{})
+
+	j := NewJanitor(db)
+	j.DefaultRetentionDays = 30
+	j.Sweep(ctx, now)
+
+	for id, archived := range map[string]bool{
+		"short-old":   true,
+		"short-new":   false,
+		"forever":     false,
+		"default-old": true,
+		"default-new": false,
+		"no-team":     true,
+		"open":        false,
+	} {
+		_, err := db.GetRetrospectiveById(ctx, id)
+		if archived != (err != nil) {
+			t.Fatal("Expected", id, "archived:", archived, "got:", err)
+		}
+		_, err = db.GetArchiveById(ctx, id)
+		if archived != (err == nil) {
+			t.Fatal("Expected archive of", id, "to exist:", archived, "got:", err)
+		}
+	}
+}
+
+func TestSweepWithoutRetention(t *testing.T) {
+	ctx := context.Background()
+	db := inmem.NewRepository()
+	closedRetro(t, db, "old", "", time.Now().AddDate(-10, 0, 0))
+
+	NewJanitor(db).Sweep(ctx, time.Now())
+	if _, err := db.GetRetrospectiveById(ctx, "old"); err != nil {
+		t.Fatal("Expected retrospectives to be kept forever by default, got:", err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	db := inmem.NewRepository()
+	now := time.Now()
+	closedRetro(t, db, "retro", "", now.AddDate(0, 0, -2))
+	for _, id := range []string{"a", "b", "c"} {
+		db.NewCard(ctx, &model.Card{Id: id, RetrospectiveId: "retro", Column: "Mixed", Message: "Card " + id})
+	}
+	b, _ := db.GetCardById(ctx, "b")
+	db.MergeCard(ctx, b, &model.Merge{Id: "merge", CardId: "b", MergedInto: "a"})
+	c, _ := db.GetCardById(ctx, "c")
+	db.MergeCard(ctx, c, &model.Merge{Id: "nested", CardId: "c", MergedInto: "b"})
+	db.NewVote(ctx, &model.Vote{Id: "vote", CardId: "c", Voter: "voter", Emoji: "clap", Count: 1})
+
+	j := NewJanitor(db)
+	j.DefaultRetentionDays = 1
+	j.Sweep(ctx, now)
+
+	a, err := db.GetArchiveById(ctx, "retro")
+	if err != nil {
+		t.Fatal("Expected retrospective to be archived, got:", err)
+	}
+	s, err := model.DecompressSnapshot(bytes.NewReader(a.Snapshot))
+	if err != nil {
+		t.Fatal("Failed to read snapshot", err)
+	}
+	if s.Retrospective.Id != "retro" || len(s.Cards) != 3 || len(s.Merges) != 2 || len(s.Votes) != 1 {
+		t.Fatal("Expected snapshot to hold everything, got:", s)
+	}
+	for _, c := range s.Cards {
+		if c.MergedCards != nil {
+			t.Fatal("Expected cards to be flat, got:", c.MergedCards)
+		}
+		if c.Id == "c" && (c.MergedInto == nil || *c.MergedInto != "b") {
+			t.Fatal("Expected nested merge to be kept, got:", c.MergedInto)
+		}
+	}
+}
+
+func TestPurgeObservations(t *testing.T) {
+	ctx := context.Background()
+	db := inmem.NewRepository()
+	db.Observe(ctx, "conn", "alice", "retro", "Visible")
+
+	j := NewJanitor(db)
+	j.Sweep(ctx, time.Now())
+	if users, _ := db.GetActiveUsers(ctx, "retro"); len(users) != 1 {
+		t.Fatal("Expected live observation to be kept, got:", users)
+	}
+
+	j.Sweep(ctx, time.Now().Add(2*j.ObservationTTL))
+	if changed, _ := db.Observe(ctx, "conn", "alice", "retro", "Visible"); !changed {
+		t.Fatal("Expected stale observation to be purged")
+	}
+}
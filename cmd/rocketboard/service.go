@@ -1,48 +1,107 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"github.com/dustinkirkland/golang-petname"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/chatops"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/exporter"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/logging"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/metrics"
 	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/ratelimit"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/tracing"
 	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/utils"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/webhook"
+	"github.com/sirupsen/logrus"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 )
 
 type repository interface {
-	NewRetrospective(*model.Retrospective) error
-	GetRetrospectiveById(string) (*model.Retrospective, error)
-	GetRetrospectiveByPetName(string) (*model.Retrospective, error)
+	NewRetrospective(context.Context, *model.Retrospective) error
+	UpdateRetrospective(context.Context, *model.Retrospective) error
+	GetRetrospectiveById(context.Context, string) (*model.Retrospective, error)
+	GetRetrospectiveByPetName(context.Context, string) (*model.Retrospective, error)
 
-	NewCard(*model.Card) error
-	UpdateCard(*model.Card) error
-	MoveCard(*model.Card, string, int) error
-	MergeCard(*model.Card, string) error
-	UnmergeCard(*model.Card) error
-	GetCardById(string) (*model.Card, error)
-	GetCardsByRetrospectiveId(string) ([]*model.Card, error)
+	NewCard(context.Context, *model.Card) error
+	UpdateCard(context.Context, *model.Card) error
+	MoveCard(context.Context, *model.Card, string, int) error
+	MergeCard(context.Context, *model.Card, *model.Merge) error
+	UnmergeCard(context.Context, *model.Card) error
+	UnmergeAll(context.Context, *model.Card) error
+	GetMergesByCardId(context.Context, string) ([]*model.Merge, error)
 
-	NewVote(*model.Vote) error
-	GetVotesByCardId(string) ([]*model.Vote, error)
-	GetVoteByCardIdAndVoterAndEmoji(string, string, string) (*model.Vote, error)
-	GetTotalUniqueEmojis(string) (int, error)
+	NewCardGroup(context.Context, *model.CardGroup) error
+	UpdateCardGroup(context.Context, *model.CardGroup) error
+	MoveCardGroup(context.Context, *model.CardGroup, int) error
+	DeleteCardGroup(context.Context, *model.CardGroup) error
+	GetCardGroupById(context.Context, string) (*model.CardGroup, error)
+	GetCardGroupsByRetrospectiveId(context.Context, string) ([]*model.CardGroup, error)
+	GetCardById(context.Context, string) (*model.Card, error)
+	GetCardsByRetrospectiveId(context.Context, string) ([]*model.Card, error)
 
-	NewStatus(*model.Status) error
-	GetStatusById(id string) (*model.Status, error)
-	GetStatusesByCardId(string) ([]*model.Status, error)
+	NewVote(context.Context, *model.Vote) error
+	GetVotesByCardId(context.Context, string) ([]*model.Vote, error)
+	GetVoteByCardIdAndVoterAndEmoji(context.Context, string, string, string) (*model.Vote, error)
+	GetTotalUniqueEmojis(context.Context, string) (int, error)
 
-	Healthcheck() error
+	NewStatus(context.Context, *model.Status) error
+	GetStatusById(context.Context, string) (*model.Status, error)
+	GetStatusesByCardId(context.Context, string) ([]*model.Status, error)
+
+	NewWebhook(context.Context, *model.Webhook) error
+	DeleteWebhook(context.Context, string) error
+	GetWebhookById(context.Context, string) (*model.Webhook, error)
+	GetWebhooksByRetrospectiveId(context.Context, string) ([]*model.Webhook, error)
+	GetWebhooksByTeam(context.Context, string) ([]*model.Webhook, error)
+	NewWebhookDelivery(context.Context, *model.WebhookDelivery) error
+	UpdateWebhookDelivery(context.Context, *model.WebhookDelivery) error
+	GetDueWebhookDeliveries(context.Context, time.Time, int) ([]*model.WebhookDelivery, error)
+	ClaimWebhookDelivery(context.Context, *model.WebhookDelivery, string, time.Time) (bool, error)
+	GetWebhookDeliveriesByWebhookId(context.Context, string, int) ([]*model.WebhookDelivery, error)
+	NewWebhookAttempt(context.Context, *model.WebhookAttempt) error
+	GetWebhookAttemptsByDeliveryId(context.Context, string) ([]*model.WebhookAttempt, error)
+
+	GetTeam(context.Context, string) (*model.Team, error)
+	SaveTeam(context.Context, *model.Team) error
+
+	GetTeams(context.Context) ([]*model.Team, error)
+	GetClosedRetrospectives(context.Context, time.Time) ([]*model.Retrospective, error)
+	ArchiveRetrospective(context.Context, *model.Archive) error
+	GetArchivesByTeam(context.Context, string) ([]*model.Archive, error)
+	GetArchiveById(context.Context, string) (*model.Archive, error)
+	PurgeObservations(context.Context, time.Time) (int, error)
+
+	Healthcheck(context.Context) error
 
 	observationStore
 }
 
 type observationStore interface {
-	Observe(string, string, string, string) (bool, error)
-	GetActiveUsers(string) ([]model.UserState, error)
-	ClearObservations(string)
+	Observe(context.Context, string, string, string, string) (bool, error)
+	GetActiveUsers(context.Context, string) ([]model.UserState, error)
+	ClearObservations(context.Context, string)
+}
+
+type eventEmitter interface {
+	Emit(context.Context, string, *model.Retrospective, interface{}) error
 }
 
 type rocketboardService struct {
-	db repository
+	db     repository
+	events eventEmitter
+	logger logrus.FieldLogger
+
+	// Base URL the frontend is served on, used to link back to boards.
+	publicUrl string
+	// Issue trackers cards can be exported to, by name.
+	exporters map[string]exporter.Provider
+	// Longer names and messages are cut off.
+	maxMessageLength int
+	// Limits how often each user may vote.
+	voteLimiter *ratelimit.Limiter
 }
 
 var VALID_EMOJIS = map[string]bool{
@@ -59,48 +118,108 @@ var VALID_EMOJIS = map[string]bool{
 	"mushroom": true,
 }
 
-func sanitizeString(str string) string {
-	if len(str) > 500 {
-		str = str[0:500]
+func (s *rocketboardService) sanitizeString(str string) string {
+	if len(str) > s.maxMessageLength {
+		str = str[0:s.maxMessageLength]
 	}
 	return str
 }
 
-func NewRocketboardService(r repository) *rocketboardService {
-	return &rocketboardService{r}
+func NewRocketboardService(r repository, e eventEmitter, logger logrus.FieldLogger) *rocketboardService {
+	return &rocketboardService{db: r, events: e, logger: logger, maxMessageLength: 500, voteLimiter: ratelimit.New(10, 100)}
 }
 
 func NewObservationStore(r repository) observationStore {
 	return r
 }
 
-func (s *rocketboardService) StartRetrospective(name string) (string, error) {
+// emit notifies webhooks of an event in a retrospective. Failing to do so is
+// logged rather than failing the change that caused the event.
+func (s *rocketboardService) emit(ctx context.Context, event string, rId string, data interface{}) {
+	log := logging.Retro(ctx, s.logger, rId).WithField("event", event)
+	// The change was made already, record it even when the caller went away
+	ctx = tracing.Detach(ctx)
+	r, err := s.db.GetRetrospectiveById(ctx, rId)
+	if err == nil {
+		err = s.events.Emit(ctx, event, r, data)
+	}
+	if err != nil {
+		log.WithError(err).Error("Failed to emit event")
+	}
+}
+
+func (s *rocketboardService) StartRetrospective(ctx context.Context, name string, team string) (string, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.StartRetrospective")
+	defer span.End()
+
 	id := utils.NewUlid()
 
 	r := &model.Retrospective{
 		Id:      id,
 		Created: time.Now(),
 		Updated: time.Now(),
-		Name:    sanitizeString(name),
+		Name:    s.sanitizeString(name),
 		PetName: petname.Generate(3, "-"),
+		Team:    s.sanitizeString(team),
 	}
-	if err := s.db.NewRetrospective(r); err != nil {
+	if err := s.db.NewRetrospective(ctx, r); err != nil {
 		return "", err
 	}
 
 	return r.PetName, nil
 }
 
-func (s *rocketboardService) GetRetrospectiveById(id string) (*model.Retrospective, error) {
-	return s.db.GetRetrospectiveById(id)
+func (s *rocketboardService) GetRetrospectiveById(ctx context.Context, id string) (*model.Retrospective, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetRetrospectiveById")
+	defer span.End()
+
+	return s.db.GetRetrospectiveById(ctx, id)
 }
 
-func (s *rocketboardService) GetRetrospectiveByPetName(petName string) (*model.Retrospective, error) {
-	return s.db.GetRetrospectiveByPetName(petName)
+func (s *rocketboardService) GetRetrospectiveByPetName(ctx context.Context, petName string) (*model.Retrospective, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetRetrospectiveByPetName")
+	defer span.End()
+
+	return s.db.GetRetrospectiveByPetName(ctx, petName)
 }
 
-func (s *rocketboardService) GetVotesByCardId(id string) ([]*model.Vote, error) {
-	votes, err := s.db.GetVotesByCardId(id)
+// retrospectiveUrl returns a link to the board of a retrospective, or an
+// empty string if the public URL is unknown.
+func (s *rocketboardService) retrospectiveUrl(r *model.Retrospective) string {
+	if s.publicUrl == "" {
+		return ""
+	}
+	return strings.TrimSuffix(s.publicUrl, "/") + "/retrospective/" + r.PetName + "/"
+}
+
+func (s *rocketboardService) CloseRetrospective(ctx context.Context, id string) (*model.Retrospective, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.CloseRetrospective")
+	defer span.End()
+
+	r, err := s.db.GetRetrospectiveById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Closed != nil {
+		return r, nil
+	}
+
+	now := time.Now()
+	r.Closed = &now
+	r.Updated = now
+	if err := s.db.UpdateRetrospective(ctx, r); err != nil {
+		return nil, err
+	}
+
+	s.emit(ctx, webhook.RetrospectiveClosed, r.Id, nil)
+	return r, nil
+}
+
+func (s *rocketboardService) GetVotesByCardId(ctx context.Context, id string) ([]*model.Vote, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetVotesByCardId")
+	defer span.End()
+
+	votes, err := s.db.GetVotesByCardId(ctx, id)
 	if err == nil {
 		for _, v := range votes {
 			if v.Emoji == "" {
@@ -111,19 +230,33 @@ func (s *rocketboardService) GetVotesByCardId(id string) ([]*model.Vote, error)
 	return votes, err
 }
 
-func (s *rocketboardService) GetVoteByCardIdAndVoterAndEmoji(id string, voter string, emoji string) (*model.Vote, error) {
-	return s.db.GetVoteByCardIdAndVoterAndEmoji(id, voter, emoji)
+func (s *rocketboardService) GetVoteByCardIdAndVoterAndEmoji(ctx context.Context, id string, voter string, emoji string) (*model.Vote, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetVoteByCardIdAndVoterAndEmoji")
+	defer span.End()
+
+	return s.db.GetVoteByCardIdAndVoterAndEmoji(ctx, id, voter, emoji)
 }
 
-func (s *rocketboardService) NewVote(cardId string, voter string, emoji string) (*model.Vote, error) {
+func (s *rocketboardService) NewVote(ctx context.Context, cardId string, voter string, emoji string) (*model.Vote, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.NewVote")
+	defer span.End()
+
 	if !VALID_EMOJIS[emoji] {
-		return nil, fmt.Errorf("Invalid emoji")
+		return nil, model.InputError("Invalid emoji")
 	}
-	vote, err := s.db.GetVoteByCardIdAndVoterAndEmoji(cardId, voter, emoji)
-	if err != nil {
-		numEmojis, err := s.db.GetTotalUniqueEmojis(cardId)
-		if err != nil || numEmojis >= 5 {
-			return nil, fmt.Errorf("Cannot create more than 5 emoji reactions")
+	if !s.voteLimiter.Allow(voter) {
+		metrics.RateLimitedVotes.Inc()
+		logging.Request(ctx, s.logger).WithField("card", cardId).Warn("Rate limited vote")
+		return nil, &model.RateLimitedError{}
+	}
+	vote, err := s.db.GetVoteByCardIdAndVoterAndEmoji(ctx, cardId, voter, emoji)
+	if model.Code(err) == model.CodeNotFound {
+		numEmojis, err := s.db.GetTotalUniqueEmojis(ctx, cardId)
+		if err != nil {
+			return nil, err
+		}
+		if numEmojis >= 5 {
+			return nil, model.InputError("Cannot create more than 5 emoji reactions")
 		}
 		vote = &model.Vote{
 			Id:      utils.NewUlid(),
@@ -134,96 +267,419 @@ func (s *rocketboardService) NewVote(cardId string, voter string, emoji string)
 			Emoji:   emoji,
 			Count:   0,
 		}
+	} else if err != nil {
+		return nil, err
 	}
 
 	vote.Count += 1
 	vote.Updated = time.Now()
-	err = s.db.NewVote(vote)
+	err = s.db.NewVote(ctx, vote)
 	if vote.Emoji == "" {
 		vote.Emoji = emoji
 	}
 	return vote, err
 }
 
-func (s *rocketboardService) AddCardToRetrospective(rId string, column string, message string, creator string) (string, error) {
-	if _, err := s.GetRetrospectiveById(rId); err != nil {
+func (s *rocketboardService) AddCardToRetrospective(ctx context.Context, rId string, column string, message string, creator string) (string, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.AddCardToRetrospective")
+	defer span.End()
+
+	if _, err := s.GetRetrospectiveById(ctx, rId); err != nil {
 		return "", err
 	}
 
 	id := utils.NewUlid()
 
-	if err := s.db.NewCard(&model.Card{
+	c := &model.Card{
 		Id:              id,
 		Created:         time.Now(),
 		Updated:         time.Now(),
 		RetrospectiveId: rId,
-		Message:         sanitizeString(message),
+		Message:         s.sanitizeString(message),
 		Creator:         creator,
 		Column:          column,
-	}); err != nil {
+	}
+	if err := s.db.NewCard(ctx, c); err != nil {
 		return "", err
 	}
 
+	s.emit(ctx, webhook.CardCreated, rId, c)
 	return id, nil
 }
 
-func (s *rocketboardService) MoveCard(id string, column string, index int) error {
-	c, err := s.db.GetCardById(id)
+// MoveCard moves a card to index in column, unless it changed since the
+// caller saw it at version.
+func (s *rocketboardService) MoveCard(ctx context.Context, id string, column string, index int, version int) error {
+	ctx, span := tracing.Start(ctx, "rocketboardService.MoveCard")
+	defer span.End()
+
+	c, err := s.db.GetCardById(ctx, id)
 	if err != nil {
 		return err
 	}
+	if c.Version != version {
+		return &model.ConflictError{Card: c}
+	}
+	if c.MergedInto != nil {
+		return model.InputError("Merged cards have to be unmerged to move them to a column")
+	}
 
-	return s.db.MoveCard(c, column, index)
+	// Moving a card to a column takes it out of its group
+	c.GroupId = nil
+	if err := s.db.MoveCard(ctx, c, column, index); err != nil {
+		return err
+	}
+
+	s.emit(ctx, webhook.CardMoved, c.RetrospectiveId, c)
+	return nil
 }
 
-func (s *rocketboardService) MergeCard(id string, mergedInto string) error {
-	c, err := s.db.GetCardById(id)
+// MoveMergedCard moves a merged card to index among the other cards of its
+// merge group, unless it changed since the caller saw it at version.
+func (s *rocketboardService) MoveMergedCard(ctx context.Context, id string, index int, version int) error {
+	ctx, span := tracing.Start(ctx, "rocketboardService.MoveMergedCard")
+	defer span.End()
+
+	c, err := s.db.GetCardById(ctx, id)
 	if err != nil {
 		return err
 	}
+	if c.Version != version {
+		return &model.ConflictError{Card: c}
+	}
+	if c.MergedInto == nil {
+		return model.InputError("Card is not merged")
+	}
 
-	return s.db.MergeCard(c, mergedInto)
+	return s.db.MoveCard(ctx, c, c.Column, index)
 }
 
-func (s *rocketboardService) UnmergeCard(id string) error {
-	c, err := s.db.GetCardById(id)
+// MergeCard merges a card, along with the cards merged into it, into another
+// card of the same retrospective.
+func (s *rocketboardService) MergeCard(ctx context.Context, id string, mergedInto string) error {
+	ctx, span := tracing.Start(ctx, "rocketboardService.MergeCard")
+	defer span.End()
+
+	c, err := s.db.GetCardById(ctx, id)
+	if err != nil {
+		return err
+	}
+	target, err := s.db.GetCardById(ctx, mergedInto)
 	if err != nil {
-		panic(err)
 		return err
 	}
+	if target.RetrospectiveId != c.RetrospectiveId {
+		return model.InputError("Cards can only be merged within a retrospective")
+	}
 
-	return s.db.UnmergeCard(c)
+	m := &model.Merge{
+		Id:         utils.NewUlid(),
+		Created:    time.Now(),
+		CardId:     id,
+		MergedInto: mergedInto,
+	}
+	if err := s.db.MergeCard(ctx, c, m); err != nil {
+		return err
+	}
+
+	s.emit(ctx, webhook.CardMerged, c.RetrospectiveId, c)
+	return nil
 }
 
-func (s *rocketboardService) UpdateMessage(id string, message string) error {
-	c, err := s.db.GetCardById(id)
+func (s *rocketboardService) UnmergeCard(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "rocketboardService.UnmergeCard")
+	defer span.End()
+
+	c, err := s.db.GetCardById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.db.UnmergeCard(ctx, c)
+}
+
+// UnmergeAll breaks up the merge group headed by a card, putting every card
+// in it back where it was before it was merged.
+func (s *rocketboardService) UnmergeAll(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "rocketboardService.UnmergeAll")
+	defer span.End()
+
+	c, err := s.db.GetCardById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(c.MergedCards) == 0 {
+		return model.InputError("No cards are merged into the card")
+	}
+
+	return s.db.UnmergeAll(ctx, c)
+}
+
+// SetMergeTitle names the merge group headed by a card, unless the card
+// changed since the caller saw it at version. An empty title removes it.
+func (s *rocketboardService) SetMergeTitle(ctx context.Context, id string, title string, version int) error {
+	ctx, span := tracing.Start(ctx, "rocketboardService.SetMergeTitle")
+	defer span.End()
+
+	c, err := s.db.GetCardById(ctx, id)
 	if err != nil {
 		return err
 	}
+	if c.Version != version {
+		return &model.ConflictError{Card: c}
+	}
+	if len(c.MergedCards) == 0 {
+		return model.InputError("Only cards with merged cards can have a title")
+	}
+
+	c.MergeTitle = nil
+	if title = strings.TrimSpace(title); title != "" {
+		title = s.sanitizeString(title)
+		c.MergeTitle = &title
+	}
+	return s.db.UpdateCard(ctx, c)
+}
 
-	c.Message = sanitizeString(message)
+// GetMergeHistory returns every time a card was merged, oldest first.
+func (s *rocketboardService) GetMergeHistory(ctx context.Context, id string) ([]*model.Merge, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetMergeHistory")
+	defer span.End()
 
-	return s.db.UpdateCard(c)
+	return s.db.GetMergesByCardId(ctx, id)
 }
 
-func (s *rocketboardService) GetCardById(id string) (*model.Card, error) {
-	return s.db.GetCardById(id)
+// groupName validates and sanitizes the name of a card group.
+func (s *rocketboardService) groupName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", model.InputError("Groups need a name")
+	}
+	return s.sanitizeString(name), nil
+}
+
+// CreateCardGroup adds a named group to the end of a column.
+func (s *rocketboardService) CreateCardGroup(ctx context.Context, rId string, column string, name string) (*model.CardGroup, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.CreateCardGroup")
+	defer span.End()
+
+	if _, err := s.db.GetRetrospectiveById(ctx, rId); err != nil {
+		return nil, err
+	}
+	name, err := s.groupName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &model.CardGroup{
+		Id:              utils.NewUlid(),
+		Created:         time.Now(),
+		Updated:         time.Now(),
+		RetrospectiveId: rId,
+		Column:          column,
+		Name:            name,
+	}
+	if err := s.db.NewCardGroup(ctx, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (s *rocketboardService) RenameCardGroup(ctx context.Context, id string, name string) (*model.CardGroup, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.RenameCardGroup")
+	defer span.End()
+
+	g, err := s.db.GetCardGroupById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if g.Name, err = s.groupName(name); err != nil {
+		return nil, err
+	}
+	g.Updated = time.Now()
+	if err := s.db.UpdateCardGroup(ctx, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// MoveCardGroup moves a group to index among the groups of its column.
+func (s *rocketboardService) MoveCardGroup(ctx context.Context, id string, index int) (*model.CardGroup, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.MoveCardGroup")
+	defer span.End()
+
+	g, err := s.db.GetCardGroupById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	g.Updated = time.Now()
+	if err := s.db.MoveCardGroup(ctx, g, index); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// DissolveCardGroup removes a group, leaving its cards in its column.
+func (s *rocketboardService) DissolveCardGroup(ctx context.Context, id string) (*model.CardGroup, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.DissolveCardGroup")
+	defer span.End()
+
+	g, err := s.db.GetCardGroupById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.DeleteCardGroup(ctx, g); err != nil {
+		return nil, err
+	}
+	return g, nil
 }
 
-func (s *rocketboardService) GetCardsForRetrospective(rId string) ([]*model.Card, error) {
-	return s.db.GetCardsByRetrospectiveId(rId)
+// MoveCardToGroup moves a card to index among the cards of a group, unless
+// it changed since the caller saw it at version.
+func (s *rocketboardService) MoveCardToGroup(ctx context.Context, id string, groupId string, index int, version int) error {
+	ctx, span := tracing.Start(ctx, "rocketboardService.MoveCardToGroup")
+	defer span.End()
+
+	c, err := s.db.GetCardById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.Version != version {
+		return &model.ConflictError{Card: c}
+	}
+	if c.MergedInto != nil {
+		return model.InputError("Merged cards have to be unmerged to move them to a group")
+	}
+	g, err := s.db.GetCardGroupById(ctx, groupId)
+	if err != nil {
+		return err
+	}
+	if g.RetrospectiveId != c.RetrospectiveId {
+		return model.InputError("Cards can only be grouped within a retrospective")
+	}
+
+	c.GroupId = &g.Id
+	if err := s.db.MoveCard(ctx, c, g.Column, index); err != nil {
+		return err
+	}
+
+	s.emit(ctx, webhook.CardMoved, c.RetrospectiveId, c)
+	return nil
 }
 
-func (s *rocketboardService) GetCardStatuses(id string) ([]*model.Status, error) {
-	return s.db.GetStatusesByCardId(id)
+func (s *rocketboardService) GetCardGroups(ctx context.Context, rId string) ([]*model.CardGroup, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetCardGroups")
+	defer span.End()
+
+	return s.db.GetCardGroupsByRetrospectiveId(ctx, rId)
 }
 
-func (s *rocketboardService) GetStatusById(id string) (*model.Status, error) {
-	return s.db.GetStatusById(id)
+// GetCardGroupCards returns the cards in a group, in order.
+func (s *rocketboardService) GetCardGroupCards(ctx context.Context, g *model.CardGroup) ([]*model.Card, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetCardGroupCards")
+	defer span.End()
+
+	cards, err := s.db.GetCardsByRetrospectiveId(ctx, g.RetrospectiveId)
+	if err != nil {
+		return nil, err
+	}
+	members := []*model.Card{}
+	for _, c := range cards {
+		if c.GroupId != nil && *c.GroupId == g.Id {
+			members = append(members, c)
+		}
+	}
+	return members, nil
 }
 
-func (s *rocketboardService) SetStatus(id string, t model.StatusType) (string, error) {
-	c, err := s.db.GetCardById(id)
+// GetCardGroupVotes adds up the votes of the cards in a group, including
+// the cards merged into them, by emoji. The most popular emoji come first.
+func (s *rocketboardService) GetCardGroupVotes(ctx context.Context, g *model.CardGroup) ([]*model.VoteTotal, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetCardGroupVotes")
+	defer span.End()
+
+	members, err := s.GetCardGroupCards(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, c := range members {
+		for _, card := range c.Group() {
+			votes, err := s.GetVotesByCardId(ctx, card.Id)
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range votes {
+				counts[v.Emoji] += v.Count
+			}
+		}
+	}
+
+	totals := []*model.VoteTotal{}
+	for emoji, count := range counts {
+		totals = append(totals, &model.VoteTotal{Emoji: emoji, Count: count})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Count != totals[j].Count {
+			return totals[i].Count > totals[j].Count
+		}
+		return totals[i].Emoji < totals[j].Emoji
+	})
+	return totals, nil
+}
+
+// UpdateMessage changes the message of a card, unless it changed since the
+// caller saw it at version.
+func (s *rocketboardService) UpdateMessage(ctx context.Context, id string, message string, version int) error {
+	ctx, span := tracing.Start(ctx, "rocketboardService.UpdateMessage")
+	defer span.End()
+
+	c, err := s.db.GetCardById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.Version != version {
+		return &model.ConflictError{Card: c}
+	}
+
+	c.Message = s.sanitizeString(message)
+
+	return s.db.UpdateCard(ctx, c)
+}
+
+func (s *rocketboardService) GetCardById(ctx context.Context, id string) (*model.Card, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetCardById")
+	defer span.End()
+
+	return s.db.GetCardById(ctx, id)
+}
+
+func (s *rocketboardService) GetCardsForRetrospective(ctx context.Context, rId string) ([]*model.Card, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetCardsForRetrospective")
+	defer span.End()
+
+	return s.db.GetCardsByRetrospectiveId(ctx, rId)
+}
+
+func (s *rocketboardService) GetCardStatuses(ctx context.Context, id string) ([]*model.Status, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetCardStatuses")
+	defer span.End()
+
+	return s.db.GetStatusesByCardId(ctx, id)
+}
+
+func (s *rocketboardService) GetStatusById(ctx context.Context, id string) (*model.Status, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetStatusById")
+	defer span.End()
+
+	return s.db.GetStatusById(ctx, id)
+}
+
+func (s *rocketboardService) SetStatus(ctx context.Context, id string, t model.StatusType) (string, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.SetStatus")
+	defer span.End()
+
+	c, err := s.db.GetCardById(ctx, id)
 	if err != nil {
 		return "", err
 	}
@@ -236,13 +692,337 @@ func (s *rocketboardService) SetStatus(id string, t model.StatusType) (string, e
 		Type:    t,
 	}
 
-	if err := s.db.NewStatus(status); err != nil {
+	if err := s.db.NewStatus(ctx, status); err != nil {
 		return "", err
 	}
 
+	s.emit(ctx, webhook.CardStatusChanged, c.RetrospectiveId, struct {
+		*model.Card
+		Status *model.Status `json:"status"`
+	}{c, status})
 	return statusId, nil
 }
 
-func (s *rocketboardService) Healthcheck() error {
-	return s.db.Healthcheck()
+func (s *rocketboardService) GetTeam(ctx context.Context, name string) (*model.Team, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetTeam")
+	defer span.End()
+
+	return s.db.GetTeam(ctx, name)
+}
+
+// ConfigureTeam sets up the chat webhook and the retention of a team. A nil
+// retention selects the default one.
+func (s *rocketboardService) ConfigureTeam(ctx context.Context, name string, chatWebhookUrl string, retentionDays *int) (*model.Team, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.ConfigureTeam")
+	defer span.End()
+
+	if name == "" {
+		return nil, model.InputError("Teams need a name")
+	}
+	if retentionDays != nil && *retentionDays < 0 {
+		return nil, model.InputError("Retention can't be negative")
+	}
+	if chatWebhookUrl != "" {
+		u, err := url.Parse(chatWebhookUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, model.InputError("Chat webhook URL must be an absolute http(s) URL")
+		}
+	}
+
+	t, err := s.db.GetTeam(ctx, name)
+	if model.Code(err) == model.CodeNotFound {
+		t = &model.Team{Name: s.sanitizeString(name), Created: time.Now()}
+	} else if err != nil {
+		return nil, err
+	}
+	t.Updated = time.Now()
+	t.ChatWebhookUrl = chatWebhookUrl
+	t.RetentionDays = retentionDays
+	if err := s.db.SaveTeam(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetArchives lists the archived retrospectives of a team.
+func (s *rocketboardService) GetArchives(ctx context.Context, team string) ([]*model.Archive, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetArchives")
+	defer span.End()
+
+	return s.db.GetArchivesByTeam(ctx, team)
+}
+
+// GetArchive returns an archived retrospective along with its snapshot.
+func (s *rocketboardService) GetArchive(ctx context.Context, id string) (*model.Archive, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetArchive")
+	defer span.End()
+
+	return s.db.GetArchiveById(ctx, id)
+}
+
+// PostSummary posts the top limit cards by votes and the action items of a
+// retrospective to the chat webhook of its team, and returns the message.
+func (s *rocketboardService) PostSummary(ctx context.Context, rId string, limit int) (string, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.PostSummary")
+	defer span.End()
+
+	r, err := s.db.GetRetrospectiveById(ctx, rId)
+	if err != nil {
+		return "", err
+	}
+	if r.Team == "" {
+		return "", model.InputError("Retrospective does not belong to a team")
+	}
+	t, err := s.db.GetTeam(ctx, r.Team)
+	if err != nil && model.Code(err) != model.CodeNotFound {
+		return "", err
+	}
+	if err != nil || t.ChatWebhookUrl == "" {
+		return "", model.InputError("No chat webhook configured for team " + r.Team)
+	}
+
+	cards, err := s.db.GetCardsByRetrospectiveId(ctx, rId)
+	if err != nil {
+		return "", err
+	}
+	summaryCards := []chatops.Card{}
+	for _, c := range cards {
+		sc := chatops.Card{Card: c, Emojis: map[string]int{}}
+		for _, card := range c.Group() {
+			votes, err := s.GetVotesByCardId(ctx, card.Id)
+			if err != nil {
+				return "", err
+			}
+			for _, v := range votes {
+				sc.Emojis[v.Emoji] += v.Count
+			}
+		}
+		statuses, err := s.db.GetStatusesByCardId(ctx, c.Id)
+		if err != nil {
+			return "", err
+		}
+		for _, status := range statuses {
+			if sc.Status == nil || status.Created.After(sc.Status.Created) {
+				sc.Status = status
+			}
+		}
+		summaryCards = append(summaryCards, sc)
+	}
+
+	m := chatops.Summarize(r, s.retrospectiveUrl(r), summaryCards, limit)
+	if err := chatops.Send(ctx, t.ChatWebhookUrl, m); err != nil {
+		return "", err
+	}
+	return m.Text, nil
+}
+
+func (s *rocketboardService) GetExportProviders(ctx context.Context) []string {
+	_, span := tracing.Start(ctx, "rocketboardService.GetExportProviders")
+	defer span.End()
+
+	names := []string{}
+	for name := range s.exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExportCard creates an issue for a card in the named issue tracker, and
+// links the card to it.
+func (s *rocketboardService) ExportCard(ctx context.Context, id string, provider string) (*model.Card, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.ExportCard")
+	defer span.End()
+
+	p, ok := s.exporters[provider]
+	if !ok {
+		return nil, model.InputError("Unknown issue tracker " + provider)
+	}
+	c, err := s.db.GetCardById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if exporting(c) {
+		return nil, model.InputError("Card is being exported")
+	}
+	if c.IssueUrl != nil {
+		return nil, model.InputError("Card was already exported to " + *c.IssueUrl)
+	}
+	r, err := s.db.GetRetrospectiveById(ctx, c.RetrospectiveId)
+	if err != nil {
+		return nil, err
+	}
+
+	votes := []*model.Vote{}
+	for _, card := range c.Group() {
+		vs, err := s.GetVotesByCardId(ctx, card.Id)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, vs...)
+	}
+
+	// The card is claimed before the issue is created, so that concurrent
+	// exports of it fail with a conflict instead of creating the issue twice.
+	claimed := time.Now()
+	c.ExportClaimed = &claimed
+	if err := s.db.UpdateCard(ctx, c); err != nil {
+		return nil, err
+	}
+	issueUrl, err := p.CreateIssue(ctx, exporter.NewIssue(c, votes, r, s.retrospectiveUrl(r)))
+	if err != nil {
+		// Release the card even if ctx is done, so it can be exported again
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		c.ExportClaimed = nil
+		if err := s.db.UpdateCard(releaseCtx, c); err != nil {
+			logging.Retro(ctx, s.logger, c.RetrospectiveId).WithError(err).Error("Failed to release card after a failed export")
+		}
+		return nil, err
+	}
+	c.IssueUrl = &issueUrl
+	c.ExportClaimed = nil
+	if err := s.db.UpdateCard(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// exportClaimTimeout is how long a card stays claimed by an export, well
+// beyond the timeout of the issue trackers. Claims of an instance that died
+// while exporting expire, so the card can be exported again.
+const exportClaimTimeout = 5 * time.Minute
+
+// exporting reports whether an export of c is under way.
+func exporting(c *model.Card) bool {
+	return c.ExportClaimed != nil && time.Since(*c.ExportClaimed) < exportClaimTimeout
+}
+
+// ExportActionItems exports every card of a retrospective that is currently
+// in progress and wasn't exported yet. The board has no column of its own
+// for action items: a card becomes one by being marked in progress, while
+// cards that were only discussed or were archived need no follow-up.
+func (s *rocketboardService) ExportActionItems(ctx context.Context, rId string, provider string) ([]*model.Card, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.ExportActionItems")
+	defer span.End()
+
+	if _, ok := s.exporters[provider]; !ok {
+		return nil, model.InputError("Unknown issue tracker " + provider)
+	}
+	cards, err := s.db.GetCardsByRetrospectiveId(ctx, rId)
+	if err != nil {
+		return nil, err
+	}
+
+	exported := []*model.Card{}
+	for _, c := range cards {
+		if c.IssueUrl != nil || exporting(c) {
+			continue
+		}
+		statuses, err := s.db.GetStatusesByCardId(ctx, c.Id)
+		if err != nil {
+			return exported, err
+		}
+		var current *model.Status
+		for _, status := range statuses {
+			if current == nil || status.Created.After(current.Created) {
+				current = status
+			}
+		}
+		if current == nil || current.Type != model.InProgress {
+			continue
+		}
+
+		c, err := s.ExportCard(ctx, c.Id, provider)
+		if err != nil {
+			return exported, err
+		}
+		exported = append(exported, c)
+	}
+	return exported, nil
+}
+
+func (s *rocketboardService) CreateWebhook(ctx context.Context, rId *string, team string, rawUrl string, secret string, events []string) (*model.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.CreateWebhook")
+	defer span.End()
+
+	if (rId == nil) == (team == "") {
+		return nil, model.InputError("Webhooks need either a retrospective or a team")
+	}
+	if rId != nil {
+		if _, err := s.db.GetRetrospectiveById(ctx, *rId); err != nil {
+			return nil, err
+		}
+	}
+	u, err := url.Parse(rawUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, model.InputError("Webhook URL must be an absolute http(s) URL")
+	}
+	if secret == "" {
+		return nil, model.InputError("Webhooks need a secret")
+	}
+	for _, e := range events {
+		valid := false
+		for _, known := range webhook.Events {
+			valid = valid || e == known
+		}
+		if !valid {
+			return nil, model.InputError("Unknown webhook event " + e)
+		}
+	}
+
+	w := &model.Webhook{
+		Id:              utils.NewUlid(),
+		Created:         time.Now(),
+		RetrospectiveId: rId,
+		Team:            team,
+		Url:             rawUrl,
+		Secret:          secret,
+		Events:          strings.Join(events, ","),
+	}
+	if err := s.db.NewWebhook(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *rocketboardService) DeleteWebhook(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "rocketboardService.DeleteWebhook")
+	defer span.End()
+
+	if _, err := s.db.GetWebhookById(ctx, id); err != nil {
+		return err
+	}
+	return s.db.DeleteWebhook(ctx, id)
+}
+
+func (s *rocketboardService) GetWebhooks(ctx context.Context, rId *string, team string) ([]*model.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetWebhooks")
+	defer span.End()
+
+	if rId != nil {
+		return s.db.GetWebhooksByRetrospectiveId(ctx, *rId)
+	}
+	return s.db.GetWebhooksByTeam(ctx, team)
+}
+
+func (s *rocketboardService) GetWebhookDeliveries(ctx context.Context, id string, limit int) ([]*model.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetWebhookDeliveries")
+	defer span.End()
+
+	return s.db.GetWebhookDeliveriesByWebhookId(ctx, id, limit)
+}
+
+func (s *rocketboardService) GetWebhookAttempts(ctx context.Context, id string) ([]*model.WebhookAttempt, error) {
+	ctx, span := tracing.Start(ctx, "rocketboardService.GetWebhookAttempts")
+	defer span.End()
+
+	return s.db.GetWebhookAttemptsByDeliveryId(ctx, id)
+}
+
+func (s *rocketboardService) Healthcheck(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "rocketboardService.Healthcheck")
+	defer span.End()
+
+	return s.db.Healthcheck(ctx)
 }
@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestDetach(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	detached := Detach(ctx)
+	if detached.Err() != nil {
+		t.Fatal("Expected detached context not to be cancelled, got:", detached.Err())
+	}
+	if trace.SpanFromContext(detached) != span {
+		t.Fatal("Expected detached context to carry the span")
+	}
+}
+
+func TestEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	_, ok := provider.Tracer("test").Start(context.Background(), "ok")
+	_, failed := provider.Tracer("test").Start(context.Background(), "failed")
+
+	End(ok, nil)
+	End(failed, errors.New("failed"))
+	spans := recorder.Ended()
+	if len(spans) != 2 || spans[0].Status().Code != codes.Unset || spans[1].Status().Code != codes.Error {
+		t.Fatal("Expected only the failed span to be marked as failed, got:", spans)
+	}
+}
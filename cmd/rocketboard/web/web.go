@@ -0,0 +1,132 @@
+// Package web serves the frontend. The built frontend is embedded into the
+// binary from the build directory, where the Dockerfile puts the output of
+// `yarn build`; the index.html checked in there is a placeholder.
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+//go:embed build
+var build embed.FS
+
+// Assets under static/ have the hash of their content in their name, so
+// they can be cached for good.
+const (
+	cacheImmutable  = "public, max-age=31536000, immutable"
+	cacheRevalidate = "no-cache"
+)
+
+type handler struct {
+	fsys fs.FS
+	// Embedded files never change, which makes their hashes good ETags
+	embedded bool
+	etags    sync.Map
+}
+
+// Handler returns a handler serving the frontend from the directory dir, or
+// the embedded one if dir is empty. Paths without a file extension that
+// don't exist are client routes, which are answered with index.html.
+func Handler(dir string) (http.Handler, error) {
+	if dir == "" {
+		fsys, err := fs.Sub(build, "build")
+		if err != nil {
+			return nil, err
+		}
+		return &handler{fsys: fsys, embedded: true}, nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return &handler{fsys: os.DirFS(dir)}, nil
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	info, err := fs.Stat(h.fsys, name)
+	if name == "" || (err == nil && info.IsDir()) {
+		name = path.Join(name, "index.html")
+		info, err = fs.Stat(h.fsys, name)
+	}
+	if err != nil {
+		if path.Ext(name) != "" {
+			http.NotFound(w, r)
+			return
+		}
+		name = "index.html"
+		if info, err = fs.Stat(h.fsys, name); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	f, err := h.fsys.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(w, "failed to read "+name, http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(b)
+	}
+
+	if h.embedded && strings.HasPrefix(name, "static/") {
+		w.Header().Set("Cache-Control", cacheImmutable)
+	} else {
+		w.Header().Set("Cache-Control", cacheRevalidate)
+	}
+	if h.embedded {
+		etag, err := h.etag(name, content)
+		if err != nil {
+			http.Error(w, "failed to read "+name, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", etag)
+	}
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// etag returns the ETag of the embedded file name, hashing content the first
+// time it is asked for.
+func (h *handler) etag(name string, content io.ReadSeeker) (string, error) {
+	if etag, ok := h.etags.Load(name); ok {
+		return etag.(string), nil
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	h.etags.Store(name, etag)
+	return etag, nil
+}
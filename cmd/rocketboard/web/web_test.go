@@ -0,0 +1,95 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func get(h http.Handler, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	h := &handler{
+		fsys: fstest.MapFS{
+			"index.html":           {Data: []byte("<html>app</html>")},
+			"manifest.json":        {Data: []byte("{}")},
+			"static/js/main.1a.js": {Data: []byte("console.log()")},
+		},
+		embedded: true,
+	}
+
+	cases := []struct {
+		path  string
+		code  int
+		body  string
+		cache string
+	}{
+		{"/", http.StatusOK, "<html>app</html>", cacheRevalidate},
+		{"/retrospective/some-pet-name/", http.StatusOK, "<html>app</html>", cacheRevalidate},
+		{"/manifest.json", http.StatusOK, "{}", cacheRevalidate},
+		{"/static/js/main.1a.js", http.StatusOK, "console.log()", cacheImmutable},
+		{"/static/js/main.2b.js", http.StatusNotFound, "", ""},
+		{"/static/", http.StatusNotFound, "", ""},
+		{"/../index.html", http.StatusOK, "<html>app</html>", cacheRevalidate},
+	}
+	for _, c := range cases {
+		w := get(h, c.path, nil)
+		if w.Code != c.code {
+			t.Fatal("Expected", c.code, "for", c.path, "got:", w.Code)
+		}
+		if c.code != http.StatusOK {
+			continue
+		}
+		if w.Body.String() != c.body || w.Header().Get("Cache-Control") != c.cache {
+			t.Fatal("Unexpected response for", c.path, w.Body.String(), w.Header())
+		}
+	}
+
+	etag := get(h, "/", nil).Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected embedded files to have an ETag")
+	}
+	if w := get(h, "/retrospective/x/", http.Header{"If-None-Match": {etag}}); w.Code != http.StatusNotModified {
+		t.Fatal("Expected 304 for a matching ETag, got:", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatal("Expected 405, got:", w.Code)
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	h, err := Handler("")
+	if err != nil {
+		t.Fatal("Failed to serve embedded frontend", err)
+	}
+	if w := get(h, "/", nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Rocketboard") {
+		t.Fatal("Expected the embedded index.html, got:", w.Code, w.Body.String())
+	}
+}
+
+func TestDirectory(t *testing.T) {
+	if _, err := Handler("./missing"); err == nil {
+		t.Fatal("Expected a missing directory to fail")
+	}
+	h, err := Handler("./build")
+	if err != nil {
+		t.Fatal("Failed to serve directory", err)
+	}
+	w := get(h, "/retrospective/x/", nil)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != "" || w.Header().Get("Cache-Control") != cacheRevalidate {
+		t.Fatal("Expected files of a directory to be revalidated, got:", w.Code, w.Header())
+	}
+}
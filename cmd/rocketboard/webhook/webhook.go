@@ -0,0 +1,285 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/utils"
+)
+
+const (
+	CardCreated         = "card.created"
+	CardMoved           = "card.moved"
+	CardMerged          = "card.merged"
+	CardStatusChanged   = "card.status_changed"
+	RetrospectiveClosed = "retrospective.closed"
+)
+
+// Events lists every event webhooks can subscribe to.
+var Events = []string{
+	CardCreated,
+	CardMoved,
+	CardMerged,
+	CardStatusChanged,
+	RetrospectiveClosed,
+}
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body,
+// keyed with the webhook secret and prefixed with "sha256=".
+const SignatureHeader = "X-Rocketboard-Signature"
+
+type store interface {
+	GetWebhookById(context.Context, string) (*model.Webhook, error)
+	GetWebhooksByRetrospectiveId(context.Context, string) ([]*model.Webhook, error)
+	GetWebhooksByTeam(context.Context, string) ([]*model.Webhook, error)
+
+	NewWebhookDelivery(context.Context, *model.WebhookDelivery) error
+	UpdateWebhookDelivery(context.Context, *model.WebhookDelivery) error
+	GetDueWebhookDeliveries(context.Context, time.Time, int) ([]*model.WebhookDelivery, error)
+	ClaimWebhookDelivery(context.Context, *model.WebhookDelivery, string, time.Time) (bool, error)
+
+	NewWebhookAttempt(context.Context, *model.WebhookAttempt) error
+}
+
+// Payload is the JSON body POSTed to webhooks.
+type Payload struct {
+	Id            string               `json:"id"`
+	Event         string               `json:"event"`
+	Created       time.Time            `json:"created"`
+	Retrospective *model.Retrospective `json:"retrospective"`
+	Data          interface{}          `json:"data,omitempty"`
+}
+
+// Dispatcher records deliveries for webhook events and sends them from a
+// background worker, retrying failed deliveries with exponential backoff.
+type Dispatcher struct {
+	db     store
+	client *http.Client
+
+	// Deliveries are given up after MaxAttempts attempts. The delay before
+	// the n-th retry is BaseBackoff * 2^(n-1), capped at MaxBackoff.
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// How often the worker looks for due deliveries when not woken up by a
+	// new event.
+	PollInterval time.Duration
+	Logger       logrus.FieldLogger
+
+	wake chan struct{}
+}
+
+func NewDispatcher(db store) *Dispatcher {
+	return &Dispatcher{
+		db:           db,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts:  8,
+		BaseBackoff:  10 * time.Second,
+		MaxBackoff:   time.Hour,
+		PollInterval: 5 * time.Second,
+		Logger:       logrus.StandardLogger(),
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Sign returns the signature of body for the SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Emit records a pending delivery of the event for every webhook registered
+// for the retrospective or its team, and wakes up the worker.
+func (d *Dispatcher) Emit(ctx context.Context, event string, r *model.Retrospective, data interface{}) error {
+	hooks, err := d.db.GetWebhooksByRetrospectiveId(ctx, r.Id)
+	if err != nil {
+		return err
+	}
+	if r.Team != "" {
+		teamHooks, err := d.db.GetWebhooksByTeam(ctx, r.Team)
+		if err != nil {
+			return err
+		}
+		hooks = append(hooks, teamHooks...)
+	}
+
+	now := time.Now()
+	payload, err := json.Marshal(Payload{
+		Id:            utils.NewUlid(),
+		Event:         event,
+		Created:       now,
+		Retrospective: r,
+		Data:          data,
+	})
+	if err != nil {
+		return err
+	}
+
+	queued := false
+	for _, hook := range hooks {
+		if !hook.Wants(event) {
+			continue
+		}
+		err := d.db.NewWebhookDelivery(ctx, &model.WebhookDelivery{
+			Id:          utils.NewUlid(),
+			Created:     now,
+			WebhookId:   hook.Id,
+			Event:       event,
+			Payload:     string(payload),
+			State:       model.Pending,
+			NextAttempt: now,
+		})
+		if err != nil {
+			return err
+		}
+		queued = true
+	}
+
+	if queued {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Run sends due deliveries until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.DeliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// DeliverDue makes one attempt at every delivery that is currently due.
+func (d *Dispatcher) DeliverDue(ctx context.Context) {
+	for {
+		due, err := d.db.GetDueWebhookDeliveries(ctx, time.Now(), 50)
+		if err != nil {
+			d.Logger.WithError(err).Error("Failed to load webhook deliveries")
+			return
+		}
+		if len(due) == 0 {
+			return
+		}
+		for _, delivery := range due {
+			if ctx.Err() != nil {
+				return
+			}
+			// Hold the delivery for longer than an attempt can take, the
+			// outcome of the attempt sets the real next attempt.
+			claimed, err := d.db.ClaimWebhookDelivery(ctx, delivery, utils.NewUlid(), time.Now().Add(2*d.client.Timeout))
+			if err != nil {
+				d.Logger.WithError(err).WithField("delivery", delivery.Id).Error("Failed to claim webhook delivery")
+				return
+			}
+			if claimed {
+				d.attempt(ctx, delivery)
+			}
+		}
+	}
+}
+
+// How long recording the outcome of an attempt may take
+const recordTimeout = 5 * time.Second
+
+func (d *Dispatcher) attempt(ctx context.Context, delivery *model.WebhookDelivery) {
+	start := time.Now()
+	attempt := &model.WebhookAttempt{
+		Id:         utils.NewUlid(),
+		Created:    start,
+		DeliveryId: delivery.Id,
+	}
+
+	log := d.Logger.WithFields(logrus.Fields{"delivery": delivery.Id, "webhook": delivery.WebhookId, "event": delivery.Event})
+	err := d.send(ctx, delivery, attempt)
+	attempt.Duration = int(time.Since(start) / time.Millisecond)
+	delivery.Attempts += 1
+	if err == nil {
+		delivery.State = model.Delivered
+	} else {
+		attempt.Error = err.Error()
+		if delivery.Attempts >= d.MaxAttempts {
+			delivery.State = model.Failed
+			log.WithError(err).Warn("Giving up on webhook delivery")
+		} else {
+			delivery.NextAttempt = time.Now().Add(d.backoff(delivery.Attempts))
+		}
+	}
+
+	// The outcome is recorded even if ctx is done by now, e.g. when the
+	// attempt was cut short by a shutdown, so the delivery isn't left claimed.
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	if err := d.db.NewWebhookAttempt(ctx, attempt); err != nil {
+		log.WithError(err).Error("Failed to record webhook attempt")
+	}
+	if err := d.db.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		log.WithError(err).Error("Failed to update webhook delivery")
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *model.WebhookDelivery, attempt *model.WebhookAttempt) error {
+	hook, err := d.db.GetWebhookById(ctx, delivery.WebhookId)
+	if err != nil {
+		return fmt.Errorf("webhook not found: %v", err)
+	}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Rocketboard-Webhook")
+	req.Header.Set("X-Rocketboard-Event", delivery.Event)
+	req.Header.Set("X-Rocketboard-Delivery", delivery.Id)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 64*1024))
+
+	attempt.StatusCode = res.StatusCode
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+	return nil
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.BaseBackoff
+	for i := 1; i < attempts && delay < d.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.MaxBackoff {
+		delay = d.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,207 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rocketdynamics/rocketboard/cmd/rocketboard/model"
+)
+
+type fakeStore struct {
+	mu         sync.Mutex
+	hooks      []*model.Webhook
+	deliveries []*model.WebhookDelivery
+	attempts   []*model.WebhookAttempt
+}
+
+func (s *fakeStore) GetWebhookById(ctx context.Context, id string) (*model.Webhook, error) {
+	for _, h := range s.hooks {
+		if h.Id == id {
+			return h, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (s *fakeStore) GetWebhooksByRetrospectiveId(ctx context.Context, id string) ([]*model.Webhook, error) {
+	hs := []*model.Webhook{}
+	for _, h := range s.hooks {
+		if h.RetrospectiveId != nil && *h.RetrospectiveId == id {
+			hs = append(hs, h)
+		}
+	}
+	return hs, nil
+}
+
+func (s *fakeStore) GetWebhooksByTeam(ctx context.Context, team string) ([]*model.Webhook, error) {
+	hs := []*model.Webhook{}
+	for _, h := range s.hooks {
+		if h.RetrospectiveId == nil && h.Team == team {
+			hs = append(hs, h)
+		}
+	}
+	return hs, nil
+}
+
+func (s *fakeStore) NewWebhookDelivery(ctx context.Context, d *model.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, d)
+	return nil
+}
+
+func (s *fakeStore) UpdateWebhookDelivery(ctx context.Context, d *model.WebhookDelivery) error {
+	return nil
+}
+
+func (s *fakeStore) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ds := []*model.WebhookDelivery{}
+	for _, d := range s.deliveries {
+		if d.State == model.Pending && !d.NextAttempt.After(now) {
+			ds = append(ds, d)
+		}
+	}
+	return ds, nil
+}
+
+func (s *fakeStore) ClaimWebhookDelivery(ctx context.Context, d *model.WebhookDelivery, claim string, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d.Claim = claim
+	d.NextAttempt = until
+	return true, nil
+}
+
+func (s *fakeStore) NewWebhookAttempt(ctx context.Context, a *model.WebhookAttempt) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts = append(s.attempts, a)
+	return nil
+}
+
+func TestDeliveryIsSignedAndRetried(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	requests := 0
+	var body []byte
+	var signature string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ = ioutil.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+	}))
+	defer server.Close()
+
+	rId := "retro"
+	db := &fakeStore{hooks: []*model.Webhook{
+		{Id: "hook", RetrospectiveId: &rId, Url: server.URL, Secret: "secret", Events: CardCreated},
+		{Id: "other-event", RetrospectiveId: &rId, Url: server.URL, Secret: "secret", Events: CardMoved},
+		{Id: "team", Team: "team", Url: server.URL, Secret: "secret"},
+	}}
+	d := NewDispatcher(db)
+	d.BaseBackoff = time.Millisecond
+
+	err := d.Emit(ctx, CardCreated, &model.Retrospective{Id: rId}, &model.Card{Id: "card"})
+	if err != nil {
+		t.Fatal("Failed to emit", err)
+	}
+	if len(db.deliveries) != 1 {
+		t.Fatal("Expected 1 delivery, got:", len(db.deliveries))
+	}
+
+	d.DeliverDue(ctx)
+	if db.deliveries[0].State != model.Pending || db.deliveries[0].Attempts != 1 {
+		t.Fatal("Expected failed delivery to be retried, got:", db.deliveries[0])
+	}
+	time.Sleep(2 * time.Millisecond)
+	d.DeliverDue(ctx)
+	if db.deliveries[0].State != model.Delivered {
+		t.Fatal("Expected delivery to succeed on retry, got:", db.deliveries[0])
+	}
+
+	if len(db.attempts) != 2 || db.attempts[0].StatusCode != 500 || db.attempts[1].StatusCode != 200 {
+		t.Fatal("Expected both attempts to be recorded, got:", db.attempts)
+	}
+	if signature != Sign("secret", body) {
+		t.Fatal("Bad signature:", signature)
+	}
+	var p struct {
+		Event string
+		Data  model.Card
+	}
+	if err := json.Unmarshal(body, &p); err != nil || p.Event != CardCreated || p.Data.Id != "card" {
+		t.Fatal("Unexpected payload:", string(body))
+	}
+}
+
+func TestDeliveryGivesUp(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	db := &fakeStore{hooks: []*model.Webhook{{Id: "hook", Team: "team", Url: server.URL, Secret: "secret"}}}
+	d := NewDispatcher(db)
+	d.MaxAttempts = 3
+	d.BaseBackoff = -time.Hour
+
+	d.Emit(ctx, RetrospectiveClosed, &model.Retrospective{Id: "retro", Team: "team"}, nil)
+	d.DeliverDue(ctx)
+
+	if db.deliveries[0].State != model.Failed || db.deliveries[0].Attempts != 3 {
+		t.Fatal("Expected delivery to fail after 3 attempts, got:", db.deliveries[0])
+	}
+}
+
+func TestAttemptIsRecordedAfterCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Shut down while the webhook is being called
+		cancel()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	db := &fakeStore{hooks: []*model.Webhook{{Id: "hook", Team: "team", Url: server.URL, Secret: "secret"}}}
+	d := NewDispatcher(db)
+	d.Emit(ctx, RetrospectiveClosed, &model.Retrospective{Id: "retro", Team: "team"}, nil)
+	d.DeliverDue(ctx)
+
+	if len(db.attempts) != 1 {
+		t.Fatal("Expected the attempt to be recorded, got:", db.attempts)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil)
+	expected := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second}
+	for i, e := range expected {
+		if b := d.backoff(i + 1); b != e {
+			t.Error("Expected backoff", e, "after", i+1, "attempts, got:", b)
+		}
+	}
+	if b := d.backoff(100); b != time.Hour {
+		t.Error("Expected backoff to be capped, got:", b)
+	}
+}
@@ -9,7 +9,7 @@ import { ExportOutlined } from '@ant-design/icons';
 
 import Timer from "./Timer";
 
-import { UNMERGE_CARD } from "../queries";
+import { UNMERGE_CARD, UNMERGE_ALL } from "../queries";
 
 const EMOJI_MAP = {
     "clap": "👏",
@@ -174,7 +174,7 @@ class RetroCard extends React.PureComponent {
     };
 
     render() {
-        const { id, votes, mergedCards } = this.props.data;
+        const { id, votes, mergedCards, mergeTitle } = this.props.data;
         const { isDragging, onNewVote, onSetStatus } = this.props;
         const isOptimistic = this.props.data.creator === "";
         const sumVotes = R.compose(R.sum, R.pluck("count"));
@@ -191,8 +191,36 @@ class RetroCard extends React.PureComponent {
             })
         }
 
+        const unmergeAll = () => {
+            this.props.unmergeAll({
+                variables: {
+                    id,
+                }
+            })
+        }
+
+        const renderMerged = (cards) => cards?.map(nested => (
+            <span className="nested-card" key={nested.id}><hr />
+                <Popconfirm
+                    title="Unmerge?"
+                    onConfirm={() => unmergeCard(nested.id)}
+                    okText="Unmerge"
+                    cancelText="Cancel"
+                >
+                    <ExportOutlined style={{float: "right"}}/>
+                </Popconfirm>
+                <p>
+                    {nested.message}
+                </p>
+                {renderMerged(nested.mergedCards)}
+            </span>
+        ));
+
         let body = (
             <div>
+                {mergeTitle && mergedCards?.length > 0 && (
+                    <h4 className="merge-title">{mergeTitle}</h4>
+                )}
                 <p style={this.state.isEditing ? hiddenStyle : visibleStyle}>
                     {this.props.data.message}
                 </p>
@@ -208,21 +236,17 @@ class RetroCard extends React.PureComponent {
                     value={this.state.message}
                 />
                 <span>
-                    {mergedCards?.map(nested => (
-                        <span className="nested-card" key={nested.id}><hr />
-                            <Popconfirm
-                                title="Unmerge?"
-                                onConfirm={() => unmergeCard(nested.id)}
-                                okText="Unmerge"
-                                cancelText="Cancel"
-                            >
-                                <ExportOutlined style={{float: "right"}}/>
-                            </Popconfirm>
-                            <p>
-                                {nested.message}
-                            </p>
-                        </span>
-                    ))}
+                    {renderMerged(mergedCards)}
+                    {mergedCards?.length > 1 && (
+                        <Popconfirm
+                            title="Unmerge all cards?"
+                            onConfirm={unmergeAll}
+                            okText="Unmerge all"
+                            cancelText="Cancel"
+                        >
+                            <Button size="small" type="link">Unmerge all</Button>
+                        </Popconfirm>
+                    )}
                 </span>
             </div>
         );
@@ -353,7 +377,8 @@ class RetroCard extends React.PureComponent {
 }
 
 export default flowRight(
-    graphql(UNMERGE_CARD, { name: "unmergeCard" })
+    graphql(UNMERGE_CARD, { name: "unmergeCard" }),
+    graphql(UNMERGE_ALL, { name: "unmergeAll" })
 )(
     RetroCard
 );
@@ -41,10 +41,12 @@ class _RetroColumn extends React.Component {
     }
 
     handleMessageUpdated = ({ cardId, message }) => {
+        const card = R.find(R.propEq("id", cardId))(this.props.cards);
         this.props.updateMessage({
             variables: {
                 id: cardId,
                 message,
+                version: card.version,
             },
             optimisticResponse: {
                 __typename: "Mutation",
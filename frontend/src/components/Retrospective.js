@@ -176,11 +176,15 @@ function _Retrospective(props) {
         const combineId = combine?.draggableId;
 
         if (column !== undefined) {
+            const card = R.find(R.propEq("id", cardId))(
+                data.retrospectiveById.cards
+            );
             props.moveCard({
                 variables: {
                     id: cardId,
                     column,
                     index: destination.index,
+                    version: card.version,
                 },
                 optimisticResponse: {
                     __typename: "Mutation",
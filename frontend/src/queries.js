@@ -33,7 +33,12 @@ export const GET_RETROSPECTIVE = gql`
                 mergedCards {
                     id
                     message
+                    mergedCards {
+                        id
+                        message
+                    }
                 }
+                mergeTitle
                 statuses {
                     id
                     created
@@ -45,6 +50,7 @@ export const GET_RETROSPECTIVE = gql`
                     emoji
                 }
                 position
+                version
             }
         }
     }
@@ -57,8 +63,8 @@ export const ADD_CARD = gql`
 `;
 
 export const MOVE_CARD = gql`
-    mutation MoveCard($id: ID!, $column: String!, $index: Int!) {
-        moveCard(id: $id, column: $column, index: $index)
+    mutation MoveCard($id: ID!, $column: String!, $index: Int!, $version: Int!) {
+        moveCard(id: $id, column: $column, index: $index, version: $version)
     }
 `;
 
@@ -74,9 +80,15 @@ export const UNMERGE_CARD = gql`
     }
 `;
 
+export const UNMERGE_ALL = gql`
+    mutation UnmergeAll($id: ID!) {
+        unmergeAll(id: $id)
+    }
+`;
+
 export const UPDATE_MESSAGE = gql`
-    mutation UpdateMessage($id: ID!, $message: String!) {
-        updateMessage(id: $id, message: $message)
+    mutation UpdateMessage($id: ID!, $message: String!, $version: Int!) {
+        updateMessage(id: $id, message: $message, version: $version)
     }
 `;
 
@@ -117,7 +129,12 @@ export const CARD_SUBSCRIPTION = gql`
             mergedCards {
                 id
                 message
+                mergedCards {
+                    id
+                    message
+                }
             }
+            mergeTitle
             statuses {
                 id
                 created
@@ -129,6 +146,7 @@ export const CARD_SUBSCRIPTION = gql`
                 emoji
             }
             position
+            version
         }
     }
 `;